@@ -6,12 +6,23 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
+	// Embedded time zone data, as the runtime image has none; schedules and TZ need it
+	_ "time/tzdata"
 
 	"github.com/lazycatapps/image-sync/internal/handler"
 	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/pkg/timeutil"
 	"github.com/lazycatapps/image-sync/internal/repository"
 	"github.com/lazycatapps/image-sync/internal/router"
 	"github.com/lazycatapps/image-sync/internal/service"
@@ -34,22 +45,38 @@ var rootCmd = &cobra.Command{
 // It sets up the following configuration options:
 //   - --host: Server listening address (default: 0.0.0.0)
 //   - --port: Server listening port (default: 8080)
+//   - --shutdown-grace-period: Seconds to let running syncs finish on shutdown (default: 30)
 //   - --timeout: Sync operation timeout in seconds (default: 600)
+//   - --max-concurrent-syncs: Maximum number of syncs running at the same time (default: 3)
+//   - --resume-interrupted: Re-queue tasks interrupted by a restart (default: false)
+//   - --idempotency-ttl: How long an Idempotency-Key maps to the task it created (default: 24h, 0 = disabled)
 //   - --default-source-registry: Default source registry prefix
 //   - --default-dest-registry: Default destination registry prefix
 //   - --cors-allowed-origins: CORS allowed origins (default: *)
 //   - --config-dir: Directory for storing configuration files (default: /configs)
+//   - --task-store: Task storage backend, memory or sqlite (default: memory)
+//   - --task-db: SQLite database file for tasks (default: <config-dir>/tasks.db)
+//   - --task-retention-age: Prune finished tasks older than this, e.g. 7d (default: no age limit)
+//   - --task-retention-count: Maximum number of finished tasks kept, e.g. 1000 (default: 0, unlimited)
 //
 // Environment variables are supported with SYNC_ prefix and underscores replacing hyphens.
 // For example: SYNC_DEFAULT_SOURCE_REGISTRY for --default-source-registry.
 func init() {
 	rootCmd.Flags().String("host", "0.0.0.0", "Server host")
 	rootCmd.Flags().IntP("port", "p", 8080, "Server port")
+	rootCmd.Flags().Int("shutdown-grace-period", 30, "Seconds running syncs may take to finish on SIGTERM/SIGINT before they are interrupted")
 	rootCmd.Flags().IntP("timeout", "t", 600, "Sync timeout in seconds")
+	rootCmd.Flags().Int("max-concurrent-syncs", 3, "Maximum number of syncs running at the same time; further tasks wait in a FIFO queue")
+	rootCmd.Flags().Bool("resume-interrupted", false, "Re-queue tasks interrupted by a restart, if their request needs no stored credentials")
+	rootCmd.Flags().String("idempotency-ttl", "24h", "How long a repeated Idempotency-Key returns the task it created, e.g. 24h or 7d (0 = ignore the header)")
 	rootCmd.Flags().String("default-source-registry", "", "Default source registry")
 	rootCmd.Flags().String("default-dest-registry", "", "Default destination registry")
 	rootCmd.Flags().StringSlice("cors-allowed-origins", []string{"*"}, "CORS allowed origins")
 	rootCmd.Flags().String("config-dir", "./configs", "Directory for storing configuration files")
+	rootCmd.Flags().String("task-store", "memory", "Task storage backend: memory (lost on restart) or sqlite")
+	rootCmd.Flags().String("task-db", "", "SQLite database file for tasks (default: <config-dir>/tasks.db)")
+	rootCmd.Flags().String("task-retention-age", "", "Prune finished tasks that ended longer ago than this, e.g. 7d, 2w or 12h (default: no age limit)")
+	rootCmd.Flags().Int("task-retention-count", 0, "Maximum number of finished tasks kept; the oldest are pruned (default: 0, unlimited)")
 	rootCmd.Flags().Bool("allow-password-save", false, "Allow saving passwords in configuration files (default: false for security)")
 	rootCmd.Flags().Int("max-config-size", 4096, "Maximum configuration file size in bytes (default: 4096)")
 	rootCmd.Flags().Int("max-config-files", 1000, "Maximum number of configuration files per user (default: 1000)")
@@ -71,11 +98,11 @@ func init() {
 // It performs the following steps:
 //  1. Loads configuration from command-line flags and environment variables
 //  2. Initializes logger
-//  3. Creates repository for task storage (in-memory)
-//  4. Initializes services (sync, image inspection, session, config)
+//  3. Creates repository for task storage (in-memory or SQLite)
+//  4. Initializes services (sync, scheduler, watcher, tag discovery, registry hooks, image inspection, session, config) and recovers interrupted tasks
 //  5. Sets up HTTP handlers (including auth handler if OIDC enabled)
 //  6. Configures routing and middleware
-//  7. Starts the HTTP server
+//  7. Starts the HTTP server and shuts down gracefully on SIGTERM/SIGINT
 func runServer(cmd *cobra.Command, args []string) {
 	// Load configuration from viper
 	oidcClientID := viper.GetString("oidc-client-id")
@@ -85,21 +112,27 @@ func runServer(cmd *cobra.Command, args []string) {
 
 	cfg := &types.Config{
 		Server: types.ServerConfig{
-			Host: viper.GetString("host"),
-			Port: viper.GetInt("port"),
+			Host:                viper.GetString("host"),
+			Port:                viper.GetInt("port"),
+			ShutdownGracePeriod: viper.GetInt("shutdown-grace-period"),
 		},
 		Registry: types.RegistryConfig{
 			DefaultSourceRegistry: viper.GetString("default-source-registry"),
 			DefaultDestRegistry:   viper.GetString("default-dest-registry"),
 		},
 		Sync: types.SyncConfig{
-			Timeout: viper.GetInt("timeout"),
+			Timeout:           viper.GetInt("timeout"),
+			MaxConcurrent:     viper.GetInt("max-concurrent-syncs"),
+			ResumeInterrupted: viper.GetBool("resume-interrupted"),
 		},
 		CORS: types.CORSConfig{
 			AllowedOrigins: viper.GetStringSlice("cors-allowed-origins"),
 		},
 		Storage: types.StorageConfig{
-			ConfigDir: viper.GetString("config-dir"),
+			ConfigDir:          viper.GetString("config-dir"),
+			TaskStore:          viper.GetString("task-store"),
+			TaskDB:             viper.GetString("task-db"),
+			TaskRetentionCount: viper.GetInt("task-retention-count"),
 		},
 		OIDC: types.OIDCConfig{
 			ClientID:     oidcClientID,
@@ -113,6 +146,24 @@ func runServer(cmd *cobra.Command, args []string) {
 	// Initialize logger
 	log := logger.New()
 
+	if value := viper.GetString("idempotency-ttl"); value != "" && value != "0" {
+		ttl, err := timeutil.ParseDuration(value)
+		if err != nil {
+			log.Error("Invalid --idempotency-ttl: %v", err)
+			return
+		}
+		cfg.Sync.IdempotencyTTL = ttl
+	}
+
+	if value := viper.GetString("task-retention-age"); value != "" {
+		age, err := timeutil.ParseDuration(value)
+		if err != nil {
+			log.Error("Invalid --task-retention-age: %v", err)
+			return
+		}
+		cfg.Storage.TaskRetentionAge = age
+	}
+
 	// Log OIDC configuration status
 	if cfg.OIDC.Enabled {
 		log.Info("OIDC authentication enabled")
@@ -128,20 +179,57 @@ func runServer(cmd *cobra.Command, args []string) {
 		log.Debug("  OIDC_REDIRECT_URL: %s", oidcRedirectURL)
 	}
 
-	// Initialize repository (in-memory task storage)
-	taskRepo := repository.NewInMemoryTaskRepository()
+	// Initialize repository (task storage)
+	taskRepo, jobRepo, err := newRepositories(&cfg.Storage, log)
+	if err != nil {
+		log.Error("Failed to initialize task storage: %v", err)
+		return
+	}
 
 	// Initialize services
-	syncService := service.NewSyncService(taskRepo, log, cfg.Sync.Timeout)
+	syncService := service.NewSyncService(taskRepo, jobRepo, log, cfg.Sync.Timeout, cfg.Sync.MaxConcurrent, cfg.Sync.IdempotencyTTL)
+	if recovered, err := syncService.RecoverTasks(cfg.Sync.ResumeInterrupted); err != nil {
+		log.Error("Failed to recover interrupted tasks: %v", err)
+	} else if recovered > 0 {
+		log.Info("Recovered %d task(s) interrupted by the previous shutdown (resume: %v)", recovered, cfg.Sync.ResumeInterrupted)
+	}
 	imageService := service.NewImageService(log)
 	allowPasswordSave := viper.GetBool("allow-password-save")
 	maxConfigSize := viper.GetInt("max-config-size")
 	maxConfigFiles := viper.GetInt("max-config-files")
 	configService := service.NewConfigService(cfg.Storage.ConfigDir, allowPasswordSave, maxConfigSize, maxConfigFiles, log)
 	sessionService := service.NewSessionService(7 * 24 * time.Hour) // 7 days session TTL
+	scheduleRepo, err := repository.NewFileScheduleRepository(filepath.Join(cfg.Storage.ConfigDir, "schedules.json"))
+	if err != nil {
+		log.Error("Failed to load schedules: %v", err)
+		return
+	}
+	scheduler := service.NewScheduler(syncService, configService, scheduleRepo, log)
+	watchRepo, err := repository.NewFileWatchRepository(filepath.Join(cfg.Storage.ConfigDir, "watches.json"))
+	if err != nil {
+		log.Error("Failed to load watches: %v", err)
+		return
+	}
+	watcher := service.NewWatcher(syncService, configService, watchRepo, log)
+	subscriptionRepo, err := repository.NewFileSubscriptionRepository(filepath.Join(cfg.Storage.ConfigDir, "subscriptions.json"))
+	if err != nil {
+		log.Error("Failed to load subscriptions: %v", err)
+		return
+	}
+	discoverer := service.NewDiscoverer(syncService, configService, subscriptionRepo, log)
+	hookRepo, err := repository.NewFileHookRepository(filepath.Join(cfg.Storage.ConfigDir, "hooks.json"))
+	if err != nil {
+		log.Error("Failed to load hooks: %v", err)
+		return
+	}
+	hookReceiver := service.NewHookReceiver(syncService, configService, hookRepo, log)
 
 	// Initialize HTTP handlers
-	syncHandler := handler.NewSyncHandler(syncService, cfg, log)
+	syncHandler := handler.NewSyncHandler(syncService, configService, cfg, log)
+	scheduleHandler := handler.NewScheduleHandler(scheduler, configService, log)
+	watchHandler := handler.NewWatchHandler(watcher, configService, log)
+	subscriptionHandler := handler.NewSubscriptionHandler(discoverer, configService, log)
+	hookHandler := handler.NewHookHandler(hookReceiver, configService, log)
 	imageHandler := handler.NewImageHandler(imageService, log)
 	configHandler := handler.NewConfigHandler(configService, log)
 
@@ -153,14 +241,102 @@ func runServer(cmd *cobra.Command, args []string) {
 	}
 
 	// Set up router and middleware
-	router := router.New(syncHandler, imageHandler, configHandler, authHandler, sessionService)
+	router := router.New(syncHandler, scheduleHandler, watchHandler, subscriptionHandler, hookHandler, imageHandler, configHandler, authHandler, sessionService)
 	engine := router.Setup(cfg)
 
-	// Start HTTP server
+	// Request contexts derive from baseCtx, which is cancelled when the server shuts down
+	// so that long-lived requests such as SSE log streams end cleanly.
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
-	log.Info("Server starting on %s (timeout: %ds)", addr, cfg.Sync.Timeout)
-	if err := engine.Run(addr); err != nil {
-		log.Error("Failed to start server: %v", err)
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     engine,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+	srv.RegisterOnShutdown(cancelRequests)
+
+	// Start HTTP server
+	log.Info("Server starting on %s (timeout: %ds, max concurrent syncs: %d)", addr, cfg.Sync.Timeout, cfg.Sync.MaxConcurrent)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Prune finished tasks in the background until shutdown
+	janitor := service.NewTaskJanitor(syncService, cfg.Storage.TaskRetentionAge, cfg.Storage.TaskRetentionCount, log)
+	if janitor.Enabled() {
+		log.Info("Task retention: age %s, count %d (0 = unlimited)", cfg.Storage.TaskRetentionAge, cfg.Storage.TaskRetentionCount)
+		go janitor.Run(signalCtx)
+	}
+
+	// Start scheduled syncs, watches and tag discovery until shutdown
+	go scheduler.Run(signalCtx)
+	go watcher.Run(signalCtx)
+	go discoverer.Run(signalCtx)
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Failed to start server: %v", err)
+		}
+		return
+	case <-signalCtx.Done():
+		stop()
+	}
+
+	shutdownServer(srv, syncService, taskRepo, time.Duration(cfg.Server.ShutdownGracePeriod)*time.Second, log)
+}
+
+// shutdownServer drains the sync service and then stops the HTTP server.
+// New syncs are rejected right away while the server keeps serving other requests,
+// so that clients can follow the remaining syncs until they finish or gracePeriod expires.
+func shutdownServer(srv *http.Server, syncService service.SyncService, taskRepo repository.TaskRepository, gracePeriod time.Duration, log logger.Logger) {
+	log.Info("Shutting down, waiting up to %s for running syncs", gracePeriod)
+
+	graceCtx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+	if err := syncService.Shutdown(graceCtx); err != nil {
+		log.Info("Grace period expired, remaining syncs were interrupted")
+	}
+
+	httpCtx, cancelHTTP := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelHTTP()
+	if err := srv.Shutdown(httpCtx); err != nil {
+		log.Error("Failed to shut down HTTP server: %v", err)
+	}
+
+	if closer, ok := taskRepo.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Error("Failed to close task storage: %v", err)
+		}
+	}
+	log.Info("Server stopped")
+}
+
+// newRepositories creates the task and job repositories selected by --task-store.
+func newRepositories(cfg *types.StorageConfig, log logger.Logger) (repository.TaskRepository, repository.JobRepository, error) {
+	switch cfg.TaskStore {
+	case "", "memory":
+		log.Info("Task storage: in-memory (task history is lost on restart)")
+		return repository.NewInMemoryTaskRepository(), repository.NewInMemoryJobRepository(), nil
+	case "sqlite":
+		dbPath := cfg.TaskDB
+		if dbPath == "" {
+			dbPath = filepath.Join(cfg.ConfigDir, "tasks.db")
+		}
+		repo, err := repository.NewSQLiteTaskRepository(dbPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Info("Task storage: SQLite (%s)", dbPath)
+		return repo, repo.Jobs(), nil
+	default:
+		return nil, nil, fmt.Errorf("unknown task store %q (expected memory or sqlite)", cfg.TaskStore)
 	}
 }
 
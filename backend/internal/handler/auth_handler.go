@@ -212,22 +212,13 @@ func (h *AuthHandler) UserInfo(c *gin.Context) {
 		return
 	}
 
-	// Check if user is admin
-	isAdmin := false
-	for _, group := range session.Groups {
-		if group == "ADMIN" {
-			isAdmin = true
-			break
-		}
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"authenticated": true,
 		"oidc_enabled":  true,
 		"user_id":       session.UserID,
 		"email":         session.Email,
 		"groups":        session.Groups,
-		"is_admin":      isAdmin,
+		"is_admin":      session.IsAdmin(),
 	})
 }
 
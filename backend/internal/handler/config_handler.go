@@ -17,22 +17,43 @@ import (
 // getUserIdentifier extracts the user identifier from the session stored in the context.
 // The identifier is used as the subdirectory name for user-specific config files.
 // Returns empty string if OIDC is not enabled or session is not found.
-//
-// Current implementation: Uses session.Email for better readability in file system.
-// If you need to change the identifier (e.g., back to UserID), just modify the return line below.
+// See service.UserIdentifier for how the identifier is derived from the user.
 func getUserIdentifier(c *gin.Context) string {
+	session := getSessionInfo(c)
+	if session == nil {
+		return ""
+	}
+
+	return service.UserIdentifier(session.UserID, session.Email)
+}
+
+// getSessionInfo returns the session stored in the context by the auth middleware.
+// Returns nil if OIDC is not enabled or session is not found.
+func getSessionInfo(c *gin.Context) *service.SessionInfo {
 	sessionInfo, exists := c.Get("session")
 	if !exists {
-		return ""
+		return nil
 	}
 
 	session, ok := sessionInfo.(*service.SessionInfo)
 	if !ok {
-		return ""
+		return nil
 	}
+	return session
+}
 
-	// Change this line if you want to use a different identifier
-	return session.Email + "_" + session.UserID // Could also be: session.UserID, session.Username, etc.
+// getUserDisplayName returns a human-readable name (email, falling back to user ID)
+// of the current user for audit fields such as "cancelled by".
+// Returns empty string if OIDC is not enabled or session is not found.
+func getUserDisplayName(c *gin.Context) string {
+	session := getSessionInfo(c)
+	if session == nil {
+		return ""
+	}
+	if session.Email != "" {
+		return session.Email
+	}
+	return session.UserID
 }
 
 // ConfigHandler handles HTTP requests for user configuration management.
@@ -0,0 +1,226 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	apperrors "github.com/lazycatapps/image-sync/internal/pkg/errors"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// definition is implemented by the pointer types of the definitions, such as *models.Schedule.
+type definition[T any] interface {
+	*T
+	GetID() string
+	SetEnabled(enabled bool)
+	GetOwner() string
+	SetOwner(userID, email string)
+}
+
+// definitionService is the part of the services of definitions, such as
+// service.Scheduler, that their management endpoints use.
+type definitionService[D any] interface {
+	Create(def D) error
+	Get(id string) (D, error)
+	List(owner string) ([]D, error)
+	Update(id string, apply func(def D) error) (D, error)
+	SetEnabled(id string, enabled bool) (D, error)
+	Delete(id string) error
+}
+
+// definitionKind describes a kind of definition, such as schedules, to definitionEndpoints.
+// R is the type of its request body.
+type definitionKind[D, R any] struct {
+	name         string // Singular name for messages, e.g. "schedule"
+	plural       string
+	apply        func(req *R, def D) // Copies a request body into a definition
+	list         func(defs []D) any  // Builds the response of listing definitions
+	view         func(def D) D       // Prepares a definition other than a created one for a response (optional)
+	notFound     error               // Returned by the service for unknown IDs
+	wrapNotFound func(err error) *apperrors.AppError
+	invalid      error // Wrapped by the errors of the service for malformed definitions
+}
+
+// definitionEndpoints implements the management endpoints that schedules, watches and
+// the like share, for definitions of type T. Users other than admins only see and
+// change their own definitions; those of other users are reported as not found.
+type definitionEndpoints[T any, D definition[T], R any] struct {
+	service  definitionService[D]
+	kind     definitionKind[D, R]
+	validate func(def D) error // Validates the input fields of a definition
+	logger   logger.Logger
+}
+
+// handleError processes errors and sends appropriate HTTP responses.
+func (e *definitionEndpoints[T, D, R]) handleError(c *gin.Context, err error) {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+	} else {
+		e.logger.Error("Unexpected error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// list writes the definitions of the owner in the query.
+func (e *definitionEndpoints[T, D, R]) list(c *gin.Context) {
+	// Users other than admins only see their own definitions
+	owner, err := ownerFilter(c, c.Query("owner"))
+	if err != nil {
+		e.handleError(c, err)
+		return
+	}
+
+	defs, err := e.service.List(owner)
+	if err != nil {
+		e.logger.Error("Failed to list %s: %v", e.kind.plural, err)
+		e.handleError(c, apperrors.WrapInternal(err, "Failed to list "+e.kind.plural))
+		return
+	}
+
+	c.JSON(http.StatusOK, e.kind.list(defs))
+}
+
+// create creates a definition from the request body, owned by the current user and
+// enabled unless the request disables it, and writes it.
+func (e *definitionEndpoints[T, D, R]) create(c *gin.Context) {
+	var req R
+	if err := c.ShouldBindJSON(&req); err != nil {
+		e.logger.Error("Failed to bind JSON request: %v", err)
+		e.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
+		return
+	}
+
+	def := D(new(T))
+	def.SetEnabled(true)
+	if session := getSessionInfo(c); session != nil {
+		def.SetOwner(session.UserID, session.Email)
+	}
+	e.kind.apply(&req, def)
+	if err := e.validate(def); err != nil {
+		e.handleError(c, err)
+		return
+	}
+
+	if err := e.service.Create(def); err != nil {
+		e.handleDefinitionError(c, def.GetID(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, def)
+}
+
+// get writes the definition in the path.
+func (e *definitionEndpoints[T, D, R]) get(c *gin.Context) {
+	def, err := e.load(c, c.Param("id"))
+	if err != nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, e.view(def))
+}
+
+// update replaces the definition in the path with the request body and writes the result.
+func (e *definitionEndpoints[T, D, R]) update(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := e.load(c, id); err != nil {
+		return
+	}
+
+	var req R
+	if err := c.ShouldBindJSON(&req); err != nil {
+		e.logger.Error("Failed to bind JSON request: %v", err)
+		e.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
+		return
+	}
+
+	def, err := e.service.Update(id, func(def D) error {
+		e.kind.apply(&req, def)
+		return e.validate(def)
+	})
+	if err != nil {
+		e.handleDefinitionError(c, id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, e.view(def))
+}
+
+// setEnabled enables or disables the definition in the path and writes the result.
+func (e *definitionEndpoints[T, D, R]) setEnabled(c *gin.Context, enabled bool) {
+	id := c.Param("id")
+	if _, err := e.load(c, id); err != nil {
+		return
+	}
+
+	def, err := e.service.SetEnabled(id, enabled)
+	if err != nil {
+		e.handleDefinitionError(c, id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, e.view(def))
+}
+
+// delete deletes the definition in the path.
+func (e *definitionEndpoints[T, D, R]) delete(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := e.load(c, id); err != nil {
+		return
+	}
+
+	if err := e.service.Delete(id); err != nil {
+		e.handleDefinitionError(c, id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": strings.ToUpper(e.kind.name[:1]) + e.kind.name[1:] + " deleted",
+		"id":      id,
+	})
+}
+
+// view returns a definition as it is written in responses.
+func (e *definitionEndpoints[T, D, R]) view(def D) D {
+	if e.kind.view == nil {
+		return def
+	}
+	return e.kind.view(def)
+}
+
+// load loads a definition the current user may access and writes the error response if
+// it cannot be found. Definitions of other users are reported as not found.
+func (e *definitionEndpoints[T, D, R]) load(c *gin.Context, id string) (D, error) {
+	def, err := e.service.Get(id)
+	if err != nil {
+		e.handleDefinitionError(c, id, err)
+		return nil, err
+	}
+	if !canAccess(getSessionInfo(c), def.GetOwner()) {
+		e.handleError(c, e.kind.wrapNotFound(e.kind.notFound))
+		return nil, e.kind.notFound
+	}
+	return def, nil
+}
+
+// handleDefinitionError maps errors from loading or changing a definition to HTTP responses.
+func (e *definitionEndpoints[T, D, R]) handleDefinitionError(c *gin.Context, id string, err error) {
+	var appErr *apperrors.AppError
+	switch {
+	case errors.As(err, &appErr):
+		e.handleError(c, err)
+	case errors.Is(err, e.kind.notFound):
+		e.handleError(c, e.kind.wrapNotFound(err))
+	case errors.Is(err, e.kind.invalid):
+		e.handleError(c, apperrors.WrapInvalidInput(err, err.Error()))
+	default:
+		e.logger.Error("Failed to process %s %s: %v", e.kind.name, id, err)
+		e.handleError(c, apperrors.WrapInternal(err, "Failed to process "+e.kind.name))
+	}
+}
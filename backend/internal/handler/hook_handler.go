@@ -0,0 +1,252 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	apperrors "github.com/lazycatapps/image-sync/internal/pkg/errors"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/pkg/validator"
+	"github.com/lazycatapps/image-sync/internal/repository"
+	"github.com/lazycatapps/image-sync/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxHookPayloadSize bounds the size of a registry notification.
+const maxHookPayloadSize = 1 << 20
+
+// HookHandler handles HTTP requests related to registry hooks and the notifications they receive.
+type HookHandler struct {
+	definitionEndpoints[models.Hook, *models.Hook, models.HookRequest]
+	receiver      *service.HookReceiver
+	configService *service.ConfigService // Checks that referenced configs exist
+}
+
+// NewHookHandler creates a new HookHandler instance.
+func NewHookHandler(receiver *service.HookReceiver, configService *service.ConfigService, logger logger.Logger) *HookHandler {
+	h := &HookHandler{receiver: receiver, configService: configService}
+	h.definitionEndpoints = definitionEndpoints[models.Hook, *models.Hook, models.HookRequest]{
+		service: receiver,
+		kind: definitionKind[*models.Hook, models.HookRequest]{
+			name:   "hook",
+			plural: "hooks",
+			apply:  (*models.HookRequest).Apply,
+			list: func(hooks []*models.Hook) any {
+				for i, hook := range hooks {
+					hooks[i] = hook.WithoutSecret()
+				}
+				return &models.HookListResponse{Hooks: hooks, Total: len(hooks)}
+			},
+			// The secret is only returned when the hook is created
+			view:         (*models.Hook).WithoutSecret,
+			notFound:     repository.ErrHookNotFound,
+			wrapNotFound: apperrors.WrapHookNotFound,
+			invalid:      service.ErrInvalidHook,
+		},
+		validate: h.validateHook,
+		logger:   logger,
+	}
+	return h
+}
+
+// ListHooks lists registry hooks, oldest first, with the pushes they received.
+// Secrets are not included.
+//
+// Query parameters:
+//   - owner (optional): Filter by owner user ID or email; only effective for admins
+//
+// Response (200 OK):
+//
+//	{"total": 1, "hooks": [{"id": "hook-uuid", "enabled": true, "rules": [...], "lastDeliveryAt": "...",
+//	 "events": [{"receivedAt": "...", "image": "harbor.example.com/library/nginx:1.27",
+//	 "destImage": "registry.example.com/mirror/nginx:1.27", "taskId": "task-uuid"}], ...}]}
+//
+// Error responses: 401 (session without user ID), 500 (server error)
+func (h *HookHandler) ListHooks(c *gin.Context) {
+	h.list(c)
+}
+
+// CreateHook creates a registry hook. The registry is configured to send its push
+// notifications to POST /api/v1/hooks/registry/:hookId; every pushed tag matching a
+// rule of the hook is then synced to the destination of the first matching rule, as a
+// sync task owned by the creator of the hook.
+//
+// Request body (JSON):
+//   - rules (required): 1 to 32 rules, tried in order
+//   - rules[].repository, rules[].tag (optional): Regular expressions matching the whole
+//     pushed repository (e.g. "library/.*") and tag (default: any)
+//   - rules[].destination (required): Destination reference; {repository}, {name} and {tag}
+//     are replaced by the pushed repository, its last component and the pushed tag
+//   - sourceRegistry (optional): Registry host to copy pushed images from, if it differs
+//     from the host in the notifications (default: the host in the notification)
+//   - secret (optional): Shared secret of 16 to 256 printable characters (default: generated)
+//   - name (optional): Display name
+//   - enabled (optional): Whether notifications are processed (default: true)
+//   - architecture, srcTlsVerify, destTlsVerify, retryTimes, force, labels, note (optional):
+//     options of the created tasks, as for SyncImage
+//   - configName (optional): Saved config whose credentials are used for the syncs of
+//     every push; the hook keeps only its name
+//
+// Response (200 OK): the hook, including its ID and secret; the secret is not returned again
+//
+// Error responses: 400 (invalid input or rules, config not found), 500 (server error)
+func (h *HookHandler) CreateHook(c *gin.Context) {
+	h.create(c)
+}
+
+// GetHook returns a registry hook, without its secret, with the pushes it received.
+//
+// Path parameter:
+//   - id: Hook UUID
+//
+// Response (200 OK): Hook object
+// Error responses: 404 (hook not found or owned by another user), 500 (server error)
+func (h *HookHandler) GetHook(c *gin.Context) {
+	h.get(c)
+}
+
+// UpdateHook replaces the definition of a registry hook. The received pushes and the
+// owner are kept.
+//
+// Path parameter:
+//   - id: Hook UUID
+//
+// Request body (JSON): same as CreateHook; an omitted enabled or secret keeps the current one
+//
+// Response (200 OK): the updated hook, without its secret
+//
+// Error responses: 400 (invalid input or rules, config not found),
+// 404 (hook not found or owned by another user), 500 (server error)
+func (h *HookHandler) UpdateHook(c *gin.Context) {
+	h.update(c)
+}
+
+// EnableHook enables a registry hook.
+//
+// Path parameter:
+//   - id: Hook UUID
+//
+// Response (200 OK): the updated hook, without its secret
+// Error responses: 404 (hook not found or owned by another user), 500 (server error)
+func (h *HookHandler) EnableHook(c *gin.Context) {
+	h.setEnabled(c, true)
+}
+
+// DisableHook disables a registry hook. Its notifications are acknowledged but ignored,
+// so that registries do not retry them.
+//
+// Path parameter:
+//   - id: Hook UUID
+//
+// Response (200 OK): the updated hook, without its secret
+// Error responses: 404 (hook not found or owned by another user), 500 (server error)
+func (h *HookHandler) DisableHook(c *gin.Context) {
+	h.setEnabled(c, false)
+}
+
+// DeleteHook deletes a registry hook. Tasks it created are kept.
+//
+// Path parameter:
+//   - id: Hook UUID
+//
+// Response (200 OK):
+//
+//	{"message": "Hook deleted", "id": "hook-uuid"}
+//
+// Error responses: 404 (hook not found or owned by another user), 500 (server error)
+func (h *HookHandler) DeleteHook(c *gin.Context) {
+	h.delete(c)
+}
+
+// ReceiveRegistryHook receives a push notification from a registry: a Docker
+// Distribution notification envelope or a Harbor webhook payload. It needs no session;
+// the notification must carry the secret of the hook, either in the Authorization
+// header (as is or as a bearer token, e.g. the Harbor "Auth Header" or a header of the
+// Distribution endpoint) or as X-Hub-Signature-256, the hex HMAC-SHA256 of the body
+// keyed with the secret, prefixed with "sha256=".
+//
+// Path parameter:
+//   - hookId: Hook UUID
+//
+// Request body: the notification, up to 1 MiB
+//
+// Response (200 OK): the tag pushes in the notification and what was done about them;
+// pulls, deletions and pushes of layers or by digest are ignored
+//
+//	{"created": 1, "events": [{"receivedAt": "...", "image": "harbor.example.com/library/nginx:1.27",
+//	 "destImage": "registry.example.com/mirror/nginx:1.27", "taskId": "task-uuid"}]}
+//
+// A disabled hook responds 200 with no events.
+//
+// Error responses: 400 (unrecognized notification), 401 (missing or invalid secret or signature),
+// 404 (hook not found), 500 (server error)
+func (h *HookHandler) ReceiveRegistryHook(c *gin.Context) {
+	id := c.Param("hookId")
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxHookPayloadSize))
+	if err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
+		return
+	}
+
+	resp, err := h.receiver.Deliver(id, body, c.GetHeader("Authorization"), c.GetHeader("X-Hub-Signature-256"))
+	switch {
+	case errors.Is(err, service.ErrHookDisabled):
+		// Registries retry failed notifications, so acknowledge them
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Hook is disabled, notification ignored",
+			"events":  []models.HookEvent{},
+			"created": 0,
+		})
+	case errors.Is(err, service.ErrHookUnauthorized):
+		h.handleError(c, apperrors.WrapUnauthorized(err, "Invalid hook secret or signature"))
+	case errors.Is(err, service.ErrInvalidNotification):
+		h.handleError(c, apperrors.WrapInvalidInput(err, err.Error()))
+	case err != nil:
+		h.handleDefinitionError(c, id, err)
+	default:
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// validateHook validates the input fields of a hook and checks that the config it
+// references exists for its owner. The rule patterns are validated by the receiver.
+func (h *HookHandler) validateHook(hook *models.Hook) error {
+	if err := validator.ValidateHookName(hook.Name); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid hook name")
+	}
+	if hook.Secret != "" {
+		if err := validator.ValidateHookSecret(hook.Secret); err != nil {
+			return apperrors.WrapInvalidInput(err, "Invalid secret")
+		}
+	}
+
+	// Destinations and the options of the created tasks are validated on a sample push
+	source := "docker.io/library/nginx:latest"
+	var req *models.SyncRequest
+	for i, rule := range hook.Rules {
+		dest := service.ExpandHookDestination(rule.Destination, "library/nginx", "latest")
+		if err := validator.ValidateImageName(dest); err != nil {
+			return apperrors.WrapInvalidInput(err, fmt.Sprintf("Invalid destination of rule %d", i+1))
+		}
+		if req == nil {
+			req = hook.SyncDefinition(source, dest).Request()
+		}
+	}
+	if req == nil {
+		return nil
+	}
+	if err := validateSyncRequest(req); err != nil {
+		return err
+	}
+	if req.ConfigName != "" {
+		return h.configService.ApplyCredentials(service.UserIdentifier(hook.Owner, hook.OwnerEmail), req.ConfigName, req)
+	}
+	return nil
+}
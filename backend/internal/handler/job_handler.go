@@ -0,0 +1,364 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	apperrors "github.com/lazycatapps/image-sync/internal/pkg/errors"
+	"github.com/lazycatapps/image-sync/internal/pkg/validator"
+	"github.com/lazycatapps/image-sync/internal/repository"
+	"github.com/lazycatapps/image-sync/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxJobListSize bounds the size of a plain text job list (about 2 KiB per line for the maximum number of items).
+const maxJobListSize = 1 << 20
+
+// CreateJob creates a batch job: one sync task per source/destination pair, sharing
+// credentials and options. All tasks are placed in the task queue; if one cannot be
+// queued, the job is cancelled so that none of its tasks stays pending.
+//
+// Request body (JSON):
+//   - items: List of {"sourceImage", "destImage", "architecture"} pairs (architecture optional)
+//   - list: Plain text pairs, one "source dest [architecture]" per line; # starts a comment
+//   - name (optional): Display name of the job
+//   - every other SyncImage field except dryRun, applied to all items
+//
+// Alternatively the body can be the plain text list (Content-Type: text/plain), with
+// name, architecture, srcTlsVerify, destTlsVerify, retryTimes, configName, force and note
+// as query parameters. Credentials are then taken from the saved config.
+//
+// Response (200 OK):
+//
+//	{"message": "Job created", "id": "job-uuid", "taskIds": ["task-uuid", ...]}
+//
+// Error responses: 400 (invalid input, no or too many items, duplicate destination),
+// 409 (the sync of an item already pending or running), 503 (server shutting down), 500 (server error)
+func (h *SyncHandler) CreateJob(c *gin.Context) {
+	var req models.JobRequest
+	if c.ContentType() == "text/plain" {
+		if err := c.ShouldBindQuery(&req); err != nil {
+			h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid query parameters"))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxJobListSize))
+		if err != nil {
+			h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
+			return
+		}
+		req.List = string(body)
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Failed to bind JSON request: %v", err)
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
+		return
+	}
+
+	h.createJob(c, &req, gin.H{})
+}
+
+// createJob validates a job request, creates the job, queues its tasks and writes the
+// response. Fields in extra are added to the response.
+func (h *SyncHandler) createJob(c *gin.Context, req *models.JobRequest, extra gin.H) {
+	if err := validator.ValidateJobName(req.Name); err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid job name"))
+		return
+	}
+
+	reqs, err := req.SyncRequests()
+	if err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, fmt.Sprintf("Invalid list: %v", err)))
+		return
+	}
+	if err := validator.ValidateJobItems(len(reqs)); err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid items"))
+		return
+	}
+
+	for i, syncReq := range reqs {
+		if err := h.resolveCredentials(c, syncReq); err != nil {
+			h.handleError(c, err)
+			return
+		}
+		if err := validateSyncRequest(syncReq); err != nil {
+			var appErr *apperrors.AppError
+			if errors.As(err, &appErr) {
+				appErr.Message = fmt.Sprintf("Item %d: %s", i+1, appErr.Message)
+			}
+			h.handleError(c, err)
+			return
+		}
+		setRequestOwner(c, syncReq)
+	}
+
+	job := &models.SyncJob{
+		Name:   req.Name,
+		Labels: models.CopyLabels(req.Labels),
+		Note:   req.Note,
+	}
+	if session := getSessionInfo(c); session != nil {
+		job.Owner = session.UserID
+		job.OwnerEmail = session.Email
+	}
+	if err := h.syncService.CreateJob(job, reqs); err != nil {
+		if errors.Is(err, service.ErrInvalidJob) {
+			h.handleError(c, apperrors.WrapInvalidInput(err, err.Error()))
+			return
+		}
+		h.handleCreateError(c, err)
+		return
+	}
+
+	for i, taskID := range job.TaskIDs {
+		if _, err := h.syncService.EnqueueTask(taskID, reqs[i]); err != nil {
+			// Tasks that are not queued would stay pending forever, so the job is cancelled as a whole
+			if _, cancelErr := h.syncService.CancelJob(job.ID, ""); cancelErr != nil {
+				h.logger.Error("Failed to cancel job %s: %v", job.ID, cancelErr)
+			}
+			if errors.Is(err, service.ErrShuttingDown) {
+				h.handleError(c, apperrors.WrapUnavailable(err, "Server is shutting down, please retry later"))
+				return
+			}
+			h.logger.Error("Failed to enqueue task %s of job %s: %v", taskID, job.ID, err)
+			h.handleError(c, apperrors.WrapInternal(err, "Failed to enqueue sync task"))
+			return
+		}
+	}
+
+	h.logger.Info("Sync job created: %s (%d tasks)", job.ID, len(job.TaskIDs))
+	extra["message"] = "Job created"
+	extra["id"] = job.ID
+	extra["taskIds"] = job.TaskIDs
+	c.JSON(http.StatusOK, extra)
+}
+
+// ListJobs lists batch jobs, newest first, with their aggregated status and counts.
+//
+// Query parameters:
+//   - page (optional): Page number, default 1
+//   - pageSize (optional): Items per page, default 20, max 100
+//   - owner (optional): Filter by owner user ID or email; only effective for admins
+//
+// Response (200 OK):
+//
+//	{"total": 3, "page": 1, "pageSize": 20, "jobs": [{"id": "job-uuid", "status": "running",
+//	 "counts": {"total": 10, "pending": 2, "running": 3, "succeeded": 4, "failed": 1, "cancelled": 0}, ...}]}
+//
+// Error responses: 400 (invalid parameters), 401 (session without user ID), 500 (server error)
+func (h *SyncHandler) ListJobs(c *gin.Context) {
+	var req models.JobListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid query parameters"))
+		return
+	}
+
+	// Users other than admins only see their own jobs
+	owner, err := ownerFilter(c, req.Owner)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	req.Owner = owner
+
+	resp, err := h.syncService.ListJobs(&req)
+	if err != nil {
+		h.logger.Error("Failed to list jobs: %v", err)
+		h.handleError(c, apperrors.WrapInternal(err, "Failed to list jobs"))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetJob returns a batch job with its aggregated status, counts and the latest attempt
+// of every item (retries replace the failed task of their item).
+//
+// Path parameter:
+//   - id: Job UUID
+//
+// Response (200 OK): Job object with status, counts and task summaries
+// Error responses: 404 (job not found or owned by another user), 500 (server error)
+func (h *SyncHandler) GetJob(c *gin.Context) {
+	job, err := h.getJob(c, c.Param("id"))
+	if err != nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelJob cancels all pending and running tasks of a batch job.
+//
+// Path parameter:
+//   - id: Job UUID
+//
+// Response (200 OK):
+//
+//	{"message": "Cancellation requested", "id": "job-uuid", "cancelled": 4}
+//
+// Error responses: 404 (job not found or owned by another user), 500 (server error)
+func (h *SyncHandler) CancelJob(c *gin.Context) {
+	id := c.Param("id")
+
+	if _, err := h.getJob(c, id); err != nil {
+		return
+	}
+
+	cancelled, err := h.syncService.CancelJob(id, getUserDisplayName(c))
+	if err != nil {
+		h.handleJobError(c, id, err)
+		return
+	}
+
+	h.logger.Info("Sync job cancellation requested: %s (%d tasks)", id, cancelled)
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Cancellation requested",
+		"id":        id,
+		"cancelled": cancelled,
+	})
+}
+
+// RetryJob re-runs the failed, cancelled, interrupted and partial items of a batch job, each as
+// a new task of the job linked to the task it retries. Credentials are handled as for
+// RetrySync and apply to all retried items.
+//
+// Path parameter:
+//   - id: Job UUID
+//
+// Request body (JSON, optional): same as RetrySync
+//
+// Response (200 OK):
+//
+//	{"message": "Job retried", "id": "job-uuid",
+//	 "retried": [{"id": "new-task-uuid", "parentTaskId": "task-uuid"}],
+//	 "failed": [{"parentTaskId": "task-uuid", "error": "registry credentials required for ..."}]}
+//
+// Error responses: 400 (invalid input), 404 (job not found or owned by another user),
+// 409 (no failed, cancelled, interrupted or partial items), 503 (server shutting down), 500 (server error)
+func (h *SyncHandler) RetryJob(c *gin.Context) {
+	id := c.Param("id")
+
+	var retryReq models.RetryRequest
+	if err := c.ShouldBindJSON(&retryReq); err != nil && !errors.Is(err, io.EOF) {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
+		return
+	}
+
+	job, err := h.getJob(c, id)
+	if err != nil {
+		return
+	}
+	tasks, err := h.syncService.LatestJobTasks(job.SyncJob)
+	if err != nil {
+		h.handleJobError(c, id, err)
+		return
+	}
+
+	retried := []gin.H{}
+	failed := []gin.H{}
+	for _, task := range tasks {
+		if !task.Status.IsRetryable() {
+			continue
+		}
+		taskID, req, err := h.retryTask(c, task, &retryReq)
+		if errors.Is(err, service.ErrShuttingDown) {
+			h.handleError(c, apperrors.WrapUnavailable(err, "Server is shutting down, please retry later"))
+			return
+		}
+		if err != nil {
+			failed = append(failed, gin.H{"parentTaskId": task.ID, "error": err.Error()})
+			continue
+		}
+		if _, err := h.syncService.EnqueueTask(taskID, req); err != nil {
+			// A retry that is not queued would stay pending forever
+			if cancelErr := h.syncService.CancelTask(taskID, ""); cancelErr != nil {
+				h.logger.Error("Failed to cancel task %s of job %s: %v", taskID, id, cancelErr)
+			}
+			if errors.Is(err, service.ErrShuttingDown) {
+				h.handleError(c, apperrors.WrapUnavailable(err, "Server is shutting down, please retry later"))
+				return
+			}
+			h.logger.Error("Failed to enqueue task %s of job %s: %v", taskID, id, err)
+			failed = append(failed, gin.H{"parentTaskId": task.ID, "error": "failed to enqueue sync task"})
+			continue
+		}
+		retried = append(retried, gin.H{"id": taskID, "parentTaskId": task.ID})
+	}
+
+	if len(retried) == 0 && len(failed) == 0 {
+		h.handleError(c, apperrors.WrapConflict(service.ErrTaskNotRetryable, "Job has no failed, cancelled, interrupted or partial items"))
+		return
+	}
+
+	h.logger.Info("Sync job %s retried (%d tasks, %d failed)", id, len(retried), len(failed))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Job retried",
+		"id":      id,
+		"retried": retried,
+		"failed":  failed,
+	})
+}
+
+// retryTask creates the retry of a task with the credentials of retryReq, as RetrySync
+// does, and returns the new task ID with the request to enqueue.
+func (h *SyncHandler) retryTask(c *gin.Context, parent *models.SyncTask, retryReq *models.RetryRequest) (string, *models.SyncRequest, error) {
+	req, _ := parent.Request()
+	req.SourceUsername, req.SourcePassword = retryReq.SourceUsername, retryReq.SourcePassword
+	req.DestUsername, req.DestPassword = retryReq.DestUsername, retryReq.DestPassword
+	for i := range req.Destinations {
+		for _, dest := range retryReq.Destinations {
+			if dest.Image == req.Destinations[i].Image {
+				req.Destinations[i].Username, req.Destinations[i].Password = dest.Username, dest.Password
+			}
+		}
+	}
+	if retryReq.ConfigName != "" {
+		req.ConfigName = retryReq.ConfigName
+	}
+
+	if err := h.resolveCredentials(c, req); err != nil {
+		return "", nil, err
+	}
+	if err := validateSyncRequest(req); err != nil {
+		return "", nil, err
+	}
+
+	setRequestOwner(c, req)
+	taskID, err := h.syncService.RetryTask(parent.ID, req)
+	if err != nil {
+		return "", nil, err
+	}
+	h.logger.Info("Sync task %s created as retry of %s", taskID, parent.ID)
+	return taskID, req, nil
+}
+
+// getJob loads a job the current user may access and writes the error response if it
+// cannot be found. Jobs of other users are reported as not found.
+func (h *SyncHandler) getJob(c *gin.Context, id string) (*models.JobSummary, error) {
+	job, err := h.syncService.GetJob(id)
+	if err != nil {
+		h.handleJobError(c, id, err)
+		return nil, err
+	}
+	if !canAccess(getSessionInfo(c), job.Owner) {
+		h.handleError(c, apperrors.WrapJobNotFound(repository.ErrJobNotFound))
+		return nil, repository.ErrJobNotFound
+	}
+	return job, nil
+}
+
+// handleJobError maps errors from loading or changing a job to HTTP responses.
+func (h *SyncHandler) handleJobError(c *gin.Context, id string, err error) {
+	if errors.Is(err, repository.ErrJobNotFound) {
+		h.handleError(c, apperrors.WrapJobNotFound(err))
+		return
+	}
+	h.logger.Error("Failed to process job %s: %v", id, err)
+	h.handleError(c, apperrors.WrapInternal(err, "Failed to process job"))
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	apperrors "github.com/lazycatapps/image-sync/internal/pkg/errors"
+	"github.com/lazycatapps/image-sync/internal/pkg/validator"
+	"github.com/lazycatapps/image-sync/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SyncRepository copies the tags of a source repository selected by a tag filter to the
+// same tags of a destination repository, as a batch job with one task per tag.
+//
+// Request body (JSON):
+//   - sourceRepository (required): Source repository without tag, e.g. "docker.io/library/nginx"
+//   - destRepository (required): Destination repository without tag
+//   - tags (optional): Tag filter, all tags if omitted
+//   - include, exclude: Regular expressions; tags must match one include and no exclude pattern
+//   - semver: Version constraint, e.g. ">=1.24 <2"; tags that are not versions are skipped
+//   - latest: Only the N highest versions
+//   - prerelease: Let semver and latest include prereleases
+//   - name (optional): Display name of the job
+//   - every other SyncImage field except dryRun, applied to all tags
+//
+// Response (200 OK):
+//
+//	{"message": "Job created", "id": "job-uuid", "taskIds": ["task-uuid", ...], "tags": ["1.27.1", ...]}
+//
+// Error responses: 400 (invalid input or tag filter, source repository not found, no or too many
+// matching tags), 503 (server shutting down), 500 (listing tags failed or server error)
+func (h *SyncHandler) SyncRepository(c *gin.Context) {
+	req, preview, ok := h.previewRepoSync(c)
+	if !ok {
+		return
+	}
+
+	if len(preview.Tags) == 0 {
+		h.handleError(c, apperrors.NewInvalidInput("No tags match the filter"))
+		return
+	}
+	if len(preview.Tags) > validator.MaxJobItems {
+		h.handleError(c, apperrors.NewInvalidInput(fmt.Sprintf(
+			"%d tags match the filter, more than the maximum of %d; narrow the filter", len(preview.Tags), validator.MaxJobItems)))
+		return
+	}
+
+	h.createJob(c, req.JobRequest(preview.Tags), gin.H{"tags": preview.Tags})
+}
+
+// PreviewRepoSync lists the tags a repository sync would copy, without creating a job.
+//
+// Request body (JSON): same as SyncRepository
+//
+// Response (200 OK):
+//
+//	{"sourceRepository": "docker.io/library/nginx", "destRepository": "registry.example.com/nginx",
+//	 "totalTags": 1020, "tags": ["1.27.1", "1.27.0", ...]}
+//
+// Error responses: 400 (invalid input or tag filter, source repository not found),
+// 500 (listing tags failed)
+func (h *SyncHandler) PreviewRepoSync(c *gin.Context) {
+	_, preview, ok := h.previewRepoSync(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// previewRepoSync binds and validates a repository sync request, resolves its
+// credentials and lists the matching tags. It writes the error response and returns
+// false if any step fails.
+func (h *SyncHandler) previewRepoSync(c *gin.Context) (*models.RepoSyncRequest, *models.RepoSyncPreview, bool) {
+	var req models.RepoSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Failed to bind JSON request: %v", err)
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
+		return nil, nil, false
+	}
+
+	if err := validator.ValidateRepositoryName(req.SourceRepository); err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid source repository"))
+		return nil, nil, false
+	}
+	if err := validator.ValidateRepositoryName(req.DestRepository); err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid destination repository"))
+		return nil, nil, false
+	}
+
+	// Tags are listed with the source credentials, which may come from a saved config
+	creds := req.SourceRequest()
+	if err := h.resolveCredentials(c, creds); err != nil {
+		h.handleError(c, err)
+		return nil, nil, false
+	}
+	if err := validator.ValidateCredentials(creds.SourceUsername, creds.SourcePassword); err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid source credentials"))
+		return nil, nil, false
+	}
+	req.SourceUsername, req.SourcePassword = creds.SourceUsername, creds.SourcePassword
+	req.DestUsername, req.DestPassword = creds.DestUsername, creds.DestPassword
+
+	preview, err := h.syncService.PreviewRepoSync(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTagFilter):
+			h.handleError(c, apperrors.WrapInvalidInput(err, err.Error()))
+		case errors.Is(err, service.ErrImageNotFound):
+			h.handleError(c, apperrors.WrapInvalidInput(err, "Source repository not found"))
+		default:
+			h.logger.Error("Failed to list tags of %s: %v", req.SourceRepository, err)
+			h.handleError(c, apperrors.WrapCommandFailed(err, fmt.Sprintf("Failed to list tags: %v", err)))
+		}
+		return nil, nil, false
+	}
+	return &req, preview, true
+}
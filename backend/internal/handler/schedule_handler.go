@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"github.com/lazycatapps/image-sync/internal/models"
+	apperrors "github.com/lazycatapps/image-sync/internal/pkg/errors"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/pkg/validator"
+	"github.com/lazycatapps/image-sync/internal/repository"
+	"github.com/lazycatapps/image-sync/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleHandler handles HTTP requests related to scheduled syncs.
+type ScheduleHandler struct {
+	definitionEndpoints[models.Schedule, *models.Schedule, models.ScheduleRequest]
+	configService *service.ConfigService // Checks that referenced configs exist
+}
+
+// NewScheduleHandler creates a new ScheduleHandler instance.
+func NewScheduleHandler(scheduler *service.Scheduler, configService *service.ConfigService, logger logger.Logger) *ScheduleHandler {
+	h := &ScheduleHandler{configService: configService}
+	h.definitionEndpoints = definitionEndpoints[models.Schedule, *models.Schedule, models.ScheduleRequest]{
+		service: scheduler,
+		kind: definitionKind[*models.Schedule, models.ScheduleRequest]{
+			name:   "schedule",
+			plural: "schedules",
+			apply:  (*models.ScheduleRequest).Apply,
+			list: func(schedules []*models.Schedule) any {
+				return &models.ScheduleListResponse{Schedules: schedules, Total: len(schedules)}
+			},
+			notFound:     repository.ErrScheduleNotFound,
+			wrapNotFound: apperrors.WrapScheduleNotFound,
+			invalid:      service.ErrInvalidSchedule,
+		},
+		validate: h.validateSchedule,
+		logger:   logger,
+	}
+	return h
+}
+
+// ListSchedules lists schedules, oldest first, with their last and next run times.
+//
+// Query parameters:
+//   - owner (optional): Filter by owner user ID or email; only effective for admins
+//
+// Response (200 OK):
+//
+//	{"total": 1, "schedules": [{"id": "schedule-uuid", "cron": "0 2 * * *", "enabled": true,
+//	 "nextRunAt": "...", "lastRunAt": "...", "lastRunStatus": "started", "lastTaskId": "task-uuid", ...}]}
+//
+// Error responses: 401 (session without user ID), 500 (server error)
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	h.list(c)
+}
+
+// CreateSchedule creates a schedule that runs a sync according to a cron expression.
+// Every run creates a regular sync task with scheduleId set, owned by the creator.
+//
+// Request body (JSON):
+//   - cron (required): Cron expression with minute, hour, day of month, month and day of week,
+//     e.g. "0 2 * * *", or one of @hourly, @daily, @weekly, @monthly, @yearly
+//   - timezone (optional): IANA time zone of the expression, e.g. "Asia/Shanghai" (default: server time zone)
+//   - name (optional): Display name
+//   - enabled (optional): Whether the schedule runs (default: true)
+//   - overlap (optional): skip (default) skips a run while the previous one is pending or running;
+//     queue runs it once the previous one has finished
+//   - sourceImage, destImage (required), architecture, srcTlsVerify, destTlsVerify, retryTimes,
+//     force, labels, note (optional): the sync, as for SyncImage
+//   - configName (optional): Saved config to take credentials from at every run; passwords are never
+//     stored with a schedule
+//
+// Response (200 OK): the schedule, including its ID and next run time
+//
+// Error responses: 400 (invalid input, cron expression or time zone, config not found), 500 (server error)
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	h.create(c)
+}
+
+// GetSchedule returns a schedule with its last and next run times.
+//
+// Path parameter:
+//   - id: Schedule UUID
+//
+// Response (200 OK): Schedule object
+// Error responses: 404 (schedule not found or owned by another user), 500 (server error)
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	h.get(c)
+}
+
+// UpdateSchedule replaces the definition of a schedule. The run history and the owner are
+// kept, and the next run time is recomputed.
+//
+// Path parameter:
+//   - id: Schedule UUID
+//
+// Request body (JSON): same as CreateSchedule; an omitted enabled keeps the current state
+//
+// Response (200 OK): the updated schedule
+//
+// Error responses: 400 (invalid input, cron expression or time zone, config not found),
+// 404 (schedule not found or owned by another user), 500 (server error)
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	h.update(c)
+}
+
+// EnableSchedule enables a schedule, which runs next at its next regular time.
+//
+// Path parameter:
+//   - id: Schedule UUID
+//
+// Response (200 OK): the updated schedule
+// Error responses: 404 (schedule not found or owned by another user), 500 (server error)
+func (h *ScheduleHandler) EnableSchedule(c *gin.Context) {
+	h.setEnabled(c, true)
+}
+
+// DisableSchedule disables a schedule. A run that has already started is not cancelled.
+//
+// Path parameter:
+//   - id: Schedule UUID
+//
+// Response (200 OK): the updated schedule
+// Error responses: 404 (schedule not found or owned by another user), 500 (server error)
+func (h *ScheduleHandler) DisableSchedule(c *gin.Context) {
+	h.setEnabled(c, false)
+}
+
+// DeleteSchedule deletes a schedule. Tasks created by its runs are kept.
+//
+// Path parameter:
+//   - id: Schedule UUID
+//
+// Response (200 OK):
+//
+//	{"message": "Schedule deleted", "id": "schedule-uuid"}
+//
+// Error responses: 404 (schedule not found or owned by another user), 500 (server error)
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	h.delete(c)
+}
+
+// validateSchedule validates the input fields of a schedule and checks that the config
+// it references exists for its owner, whose configs are used at every run.
+// The cron expression and time zone are validated by the scheduler.
+func (h *ScheduleHandler) validateSchedule(schedule *models.Schedule) error {
+	if err := validator.ValidateScheduleName(schedule.Name); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid schedule name")
+	}
+
+	req := schedule.Request()
+	if err := validateSyncRequest(req); err != nil {
+		return err
+	}
+	if req.ConfigName != "" {
+		return h.configService.ApplyCredentials(service.UserIdentifier(schedule.Owner, schedule.OwnerEmail), req.ConfigName, req)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"github.com/lazycatapps/image-sync/internal/models"
+	apperrors "github.com/lazycatapps/image-sync/internal/pkg/errors"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/pkg/validator"
+	"github.com/lazycatapps/image-sync/internal/repository"
+	"github.com/lazycatapps/image-sync/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubscriptionHandler handles HTTP requests related to tag discovery subscriptions.
+type SubscriptionHandler struct {
+	definitionEndpoints[models.Subscription, *models.Subscription, models.SubscriptionRequest]
+	configService *service.ConfigService // Checks that referenced configs exist
+}
+
+// NewSubscriptionHandler creates a new SubscriptionHandler instance.
+func NewSubscriptionHandler(discoverer *service.Discoverer, configService *service.ConfigService, logger logger.Logger) *SubscriptionHandler {
+	h := &SubscriptionHandler{configService: configService}
+	h.definitionEndpoints = definitionEndpoints[models.Subscription, *models.Subscription, models.SubscriptionRequest]{
+		service: discoverer,
+		kind: definitionKind[*models.Subscription, models.SubscriptionRequest]{
+			name:   "subscription",
+			plural: "subscriptions",
+			apply:  (*models.SubscriptionRequest).Apply,
+			list: func(subscriptions []*models.Subscription) any {
+				return &models.SubscriptionListResponse{Subscriptions: subscriptions, Total: len(subscriptions)}
+			},
+			notFound:     repository.ErrSubscriptionNotFound,
+			wrapNotFound: apperrors.WrapSubscriptionNotFound,
+			invalid:      service.ErrInvalidSubscription,
+		},
+		validate: h.validateSubscription,
+		logger:   logger,
+	}
+	return h
+}
+
+// ListSubscriptions lists tag subscriptions, oldest first, with their last check and discoveries.
+//
+// Query parameters:
+//   - owner (optional): Filter by owner user ID or email; only effective for admins
+//
+// Response (200 OK):
+//
+//	{"total": 1, "subscriptions": [{"id": "subscription-uuid", "interval": "1h", "enabled": true,
+//	 "sourceRepository": "docker.io/library/nginx", "lastCheckAt": "...", "nextCheckAt": "...",
+//	 "discoveries": [{"discoveredAt": "...", "tags": ["1.27.1"], "jobId": "job-uuid"}], ...}]}
+//
+// Error responses: 401 (session without user ID), 500 (server error)
+func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
+	h.list(c)
+}
+
+// CreateSubscription creates a subscription that periodically lists the tags of a source
+// repository and mirrors the matching tags the destination repository does not have yet.
+// The subscription is checked right away. Every check that finds missing tags creates a
+// batch job copying them (at most 100 per check), owned by the creator; the check is
+// skipped while the job of the previous discovery is still in progress.
+//
+// Request body (JSON):
+//   - sourceRepository, destRepository (required): Repositories without tag
+//   - tags (optional): Tag filter, as for SyncRepository (default: all tags)
+//   - interval (optional): Time between checks, at least 5m (default: "1h"); failing checks
+//     are backed off up to 6h, and for at least 15m after a registry rate limited a check
+//   - name (optional): Display name, also used for the created jobs
+//   - enabled (optional): Whether the subscription is checked (default: true)
+//   - architecture, srcTlsVerify, destTlsVerify, retryTimes, force, labels, note (optional):
+//     options of the created tasks, as for SyncImage
+//   - configName (optional): Saved config providing the registry credentials; it is read
+//     again at every check, so that changed passwords take effect
+//
+// Response (200 OK): the subscription, including its ID
+//
+// Error responses: 400 (invalid input, interval or tag filter, config not found), 500 (server error)
+func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
+	h.create(c)
+}
+
+// GetSubscription returns a subscription with its last check and discoveries.
+//
+// Path parameter:
+//   - id: Subscription UUID
+//
+// Response (200 OK): Subscription object
+// Error responses: 404 (subscription not found or owned by another user), 500 (server error)
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	h.get(c)
+}
+
+// UpdateSubscription replaces the definition of a subscription. The discoveries and the
+// owner are kept; if the repositories or the tag filter changed, it is checked right away.
+//
+// Path parameter:
+//   - id: Subscription UUID
+//
+// Request body (JSON): same as CreateSubscription; an omitted enabled keeps the current state
+//
+// Response (200 OK): the updated subscription
+//
+// Error responses: 400 (invalid input, interval or tag filter, config not found),
+// 404 (subscription not found or owned by another user), 500 (server error)
+func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
+	h.update(c)
+}
+
+// EnableSubscription enables a subscription, which is checked one interval after its last check.
+//
+// Path parameter:
+//   - id: Subscription UUID
+//
+// Response (200 OK): the updated subscription
+// Error responses: 404 (subscription not found or owned by another user), 500 (server error)
+func (h *SubscriptionHandler) EnableSubscription(c *gin.Context) {
+	h.setEnabled(c, true)
+}
+
+// DisableSubscription disables a subscription. Jobs it has already created are not cancelled.
+//
+// Path parameter:
+//   - id: Subscription UUID
+//
+// Response (200 OK): the updated subscription
+// Error responses: 404 (subscription not found or owned by another user), 500 (server error)
+func (h *SubscriptionHandler) DisableSubscription(c *gin.Context) {
+	h.setEnabled(c, false)
+}
+
+// DeleteSubscription deletes a subscription. Jobs it created are kept.
+//
+// Path parameter:
+//   - id: Subscription UUID
+//
+// Response (200 OK):
+//
+//	{"message": "Subscription deleted", "id": "subscription-uuid"}
+//
+// Error responses: 404 (subscription not found or owned by another user), 500 (server error)
+func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	h.delete(c)
+}
+
+// validateSubscription validates the input fields of a subscription and checks that the
+// config it references exists for its owner. The interval and tag filter are validated
+// by the discoverer.
+func (h *SubscriptionHandler) validateSubscription(subscription *models.Subscription) error {
+	if err := validator.ValidateSubscriptionName(subscription.Name); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid subscription name")
+	}
+	if err := validator.ValidateRepositoryName(subscription.SourceRepository); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid source repository")
+	}
+	if err := validator.ValidateRepositoryName(subscription.DestRepository); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid destination repository")
+	}
+
+	// The options of the created tasks are validated on the request of a sample tag
+	reqs, err := subscription.RepoSyncRequest().JobRequest([]string{"latest"}).SyncRequests()
+	if err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid subscription")
+	}
+	req := reqs[0]
+	if err := validateSyncRequest(req); err != nil {
+		return err
+	}
+	if req.ConfigName != "" {
+		return h.configService.ApplyCredentials(service.UserIdentifier(subscription.Owner, subscription.OwnerEmail), req.ConfigName, req)
+	}
+	return nil
+}
@@ -5,13 +5,20 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lazycatapps/image-sync/internal/models"
 	apperrors "github.com/lazycatapps/image-sync/internal/pkg/errors"
 	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/pkg/timeutil"
 	"github.com/lazycatapps/image-sync/internal/pkg/validator"
 	"github.com/lazycatapps/image-sync/internal/repository"
 	"github.com/lazycatapps/image-sync/internal/service"
@@ -22,17 +29,19 @@ import (
 
 // SyncHandler handles HTTP requests related to image synchronization tasks.
 type SyncHandler struct {
-	syncService service.SyncService
-	config      *types.Config
-	logger      logger.Logger
+	syncService   service.SyncService
+	configService *service.ConfigService // Resolves credentials referenced by config name
+	config        *types.Config
+	logger        logger.Logger
 }
 
 // NewSyncHandler creates a new SyncHandler instance.
-func NewSyncHandler(syncService service.SyncService, cfg *types.Config, logger logger.Logger) *SyncHandler {
+func NewSyncHandler(syncService service.SyncService, configService *service.ConfigService, cfg *types.Config, logger logger.Logger) *SyncHandler {
 	return &SyncHandler{
-		syncService: syncService,
-		config:      cfg,
-		logger:      logger,
+		syncService:   syncService,
+		configService: configService,
+		config:        cfg,
+		logger:        logger,
 	}
 }
 
@@ -49,21 +58,43 @@ func (h *SyncHandler) handleError(c *gin.Context, err error) {
 }
 
 // SyncImage creates a new image synchronization task.
-// It validates the request, creates a task record, and starts sync execution asynchronously.
+// It validates the request, creates a task record, and places it in the task queue.
+// The task starts immediately if fewer than --max-concurrent-syncs syncs are running.
 //
 // Request body (JSON):
 //   - sourceImage (required): Source image address
 //   - destImage (required): Destination image address
 //   - architecture (optional): Target architecture (e.g., "linux/amd64", "all")
+//   - architectures (optional): Platforms to copy, e.g. ["linux/amd64", "linux/arm64"], instead of
+//     architecture; the destination gets a manifest list of only these platforms, which keeps
+//     their source digests
 //   - sourceUsername, sourcePassword (optional): Source registry credentials
 //   - destUsername, destPassword (optional): Destination registry credentials
+//   - configName (optional): Saved config to take credentials from when they are not supplied
 //   - srcTLSVerify, destTLSVerify (optional): TLS verification flags
+//   - force (optional): Copy even if the destination already has the same digest
+//   - destinations (optional): Additional destinations, each {"image", "username", "password",
+//     "tlsVerify"}; the source is pulled once and copied to destImage and all of them, and the
+//     result of every destination is reported in the task's destinations
+//   - allowPartial (optional): Finish as "partial" instead of "failed" if some destinations
+//     failed and the others were synced
+//   - dryRun (optional): Only return the sync plan, as PlanSync does; no task is created
+//   - labels (optional): Free-form key/value labels, e.g. {"ticket": "OPS-123"}
+//   - note (optional): Free-form note
+//
+// Header Idempotency-Key (optional): a repeated key within --idempotency-ttl returns the
+// task the key created instead of a new one. Independently of the header, a request for
+// the same source, destination and architecture as a pending or running task of the
+// same user is attached to that task rather than starting a second copy.
 //
 // Response (200 OK):
 //
 //	{"message": "Sync started", "id": "task-uuid"}
+//	{"message": "Sync queued", "id": "task-uuid", "queuePosition": 3}
+//	{"message": "Sync already requested", "id": "task-uuid", "status": "running", "duplicateOf": "in-flight"}
 //
-// Error responses: 400 (invalid input), 500 (server error)
+// Error responses: 400 (invalid input), 409 (idempotency key used for a different request),
+// 503 (server shutting down), 500 (server error)
 func (h *SyncHandler) SyncImage(c *gin.Context) {
 	var req models.SyncRequest
 
@@ -73,152 +104,798 @@ func (h *SyncHandler) SyncImage(c *gin.Context) {
 		return
 	}
 
-	// Validate input fields for security
-	if err := validator.ValidateImageName(req.SourceImage); err != nil {
-		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid source image"))
+	if err := h.resolveCredentials(c, &req); err != nil {
+		h.handleError(c, err)
 		return
 	}
 
-	if err := validator.ValidateImageName(req.DestImage); err != nil {
-		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid destination image"))
+	if err := validateSyncRequest(&req); err != nil {
+		h.handleError(c, err)
 		return
 	}
 
-	if err := validator.ValidateArchitecture(req.Architecture); err != nil {
-		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid architecture"))
+	if req.DryRun {
+		h.planSync(c, &req)
 		return
 	}
 
-	if err := validator.ValidateCredentials(req.SourceUsername, req.SourcePassword); err != nil {
-		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid source credentials"))
+	req.IdempotencyKey = c.GetHeader("Idempotency-Key")
+	if err := validator.ValidateIdempotencyKey(req.IdempotencyKey); err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid Idempotency-Key header"))
 		return
 	}
 
-	if err := validator.ValidateCredentials(req.DestUsername, req.DestPassword); err != nil {
-		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid destination credentials"))
+	setRequestOwner(c, &req)
+	taskID, err := h.syncService.CreateSyncTask(&req)
+	var duplicate *service.DuplicateTaskError
+	if errors.As(err, &duplicate) {
+		// The repeated request is attached to the existing task; nothing is queued
+		if duplicate.Reason == service.DuplicateIdempotencyKey {
+			c.Header("Idempotent-Replayed", "true")
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "Sync already requested",
+			"id":          duplicate.TaskID,
+			"status":      duplicate.Status,
+			"duplicateOf": duplicate.Reason,
+		})
+		return
+	}
+	if err != nil {
+		h.handleCreateError(c, err)
 		return
 	}
 
-	if err := validator.ValidateRetryTimes(req.RetryTimes); err != nil {
-		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid retry times"))
+	h.logger.Info("Sync task created: %s (source: %s, dest: %s)", taskID, req.SourceImage, req.DestImage)
+	h.enqueueTask(c, taskID, &req, gin.H{})
+}
+
+// PlanSync reports what a sync would do without creating a task or writing to the
+// destination: the resolved source digest, the platforms and blobs that would be copied,
+// the blobs the destination tag already references and whether it would be overwritten.
+//
+// Request body (JSON): same as SyncImage, without destinations and architectures
+//
+// Response (200 OK):
+//
+//	{"sourceDigest": "sha256:...", "destExists": true, "destDigest": "sha256:...", "upToDate": false,
+//	 "overwrite": true, "platforms": [{"platform": "linux/amd64", "digest": "sha256:...", "blobs": 3, "bytes": 1024}],
+//	 "blobs": [{"digest": "sha256:...", "size": 512, "existsAtDest": true}],
+//	 "totalBytes": 1024, "existingBytes": 512, "transferBytes": 512, ...}
+//
+// Error responses: 400 (invalid input or source image not found), 500 (inspection failed)
+func (h *SyncHandler) PlanSync(c *gin.Context) {
+	var req models.SyncRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Failed to bind JSON request: %v", err)
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
 		return
 	}
 
-	taskID, err := h.syncService.CreateSyncTask(&req)
-	if err != nil {
-		h.logger.Error("Failed to create sync task: %v", err)
-		h.handleError(c, apperrors.WrapInternal(err, "Failed to create sync task"))
+	if err := h.resolveCredentials(c, &req); err != nil {
+		h.handleError(c, err)
 		return
 	}
 
-	// Execute sync asynchronously
-	go func() {
-		if err := h.syncService.ExecuteSync(taskID, &req); err != nil {
-			h.logger.Error("[%s] Sync execution failed: %v", taskID, err)
+	if err := validateSyncRequest(&req); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.planSync(c, &req)
+}
+
+// planSync computes the plan for a validated request and writes the response.
+func (h *SyncHandler) planSync(c *gin.Context, req *models.SyncRequest) {
+	if len(req.Destinations) > 0 {
+		h.handleError(c, apperrors.NewInvalidInput("Sync plans cover destImage only; remove destinations"))
+		return
+	}
+	if len(req.Architectures) > 0 {
+		h.handleError(c, apperrors.NewInvalidInput("Sync plans do not support architectures; use architecture"))
+		return
+	}
+
+	plan, err := h.syncService.PlanSync(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrImageNotFound) {
+			h.handleError(c, apperrors.WrapInvalidInput(err, "Source image not found"))
+			return
 		}
-	}()
+		h.logger.Error("Failed to plan sync %s -> %s: %v", req.SourceImage, req.DestImage, err)
+		h.handleError(c, apperrors.WrapCommandFailed(err, fmt.Sprintf("Failed to plan sync: %v", err)))
+		return
+	}
 
-	h.logger.Info("Sync task created: %s (source: %s, dest: %s)", taskID, req.SourceImage, req.DestImage)
+	c.JSON(http.StatusOK, plan)
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Sync started",
-		"id":      taskID,
-	})
+// RetrySync re-runs a failed, cancelled, interrupted or partial task as a new task linked
+// to the original one. Credentials are never stored with a task: if the original task
+// used them, they must be supplied again or referenced through a saved config. A retry
+// copies to all destinations again; those already synced are skipped unless forced.
+//
+// Path parameter:
+//   - id: Task UUID
+//
+// Request body (JSON, optional):
+//   - sourceUsername, sourcePassword (optional): Source registry credentials
+//   - destUsername, destPassword (optional): Destination registry credentials
+//   - destinations (optional): Credentials of additional destinations, each {"image", "username", "password"}
+//   - configName (optional): Saved config to take credentials from (default: the config the original task used)
+//
+// Response (200 OK):
+//
+//	{"message": "Sync started", "id": "new-task-uuid", "parentTaskId": "task-uuid"}
+//	{"message": "Sync queued", "id": "new-task-uuid", "parentTaskId": "task-uuid", "queuePosition": 3}
+//
+// Error responses: 400 (invalid input or credentials required),
+// 404 (task not found or owned by another user), 409 (task not failed, cancelled, interrupted or partial,
+// or the same sync already pending or running), 503 (server shutting down), 500 (server error)
+func (h *SyncHandler) RetrySync(c *gin.Context) {
+	id := c.Param("id")
+
+	var retryReq models.RetryRequest
+	if err := c.ShouldBindJSON(&retryReq); err != nil && !errors.Is(err, io.EOF) {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
+		return
+	}
+
+	parent, err := h.getTask(c, id)
+	if err != nil {
+		return
+	}
+
+	taskID, req, err := h.retryTask(c, parent, &retryReq)
+	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) {
+			h.handleError(c, err)
+			return
+		}
+		h.handleCreateError(c, err)
+		return
+	}
+
+	h.enqueueTask(c, taskID, req, gin.H{"parentTaskId": id})
 }
 
-// GetSyncStatus retrieves the status and details of a sync task by ID.
+// CloneSync creates a new task from an existing task of any status. The request body
+// is a partial sync request that overrides the original one, e.g. a different
+// destination tag or architecture. Credentials are handled as for RetrySync.
 //
 // Path parameter:
 //   - id: Task UUID
 //
-// Response (200 OK): Task object with all details (status, logs, timestamps, etc.)
-// Error responses: 404 (task not found), 500 (server error)
-func (h *SyncHandler) GetSyncStatus(c *gin.Context) {
+// Request body (JSON, optional): any SyncImage field; omitted fields keep the original value
+//
+// Response (200 OK):
+//
+//	{"message": "Sync started", "id": "new-task-uuid", "parentTaskId": "task-uuid"}
+//	{"message": "Sync queued", "id": "new-task-uuid", "parentTaskId": "task-uuid", "queuePosition": 3}
+//
+// Error responses: 400 (invalid input or credentials required),
+// 404 (task not found or owned by another user), 409 (the same sync already pending or running),
+// 503 (server shutting down), 500 (server error)
+func (h *SyncHandler) CloneSync(c *gin.Context) {
 	id := c.Param("id")
 
+	parent, err := h.getTask(c, id)
+	if err != nil {
+		return
+	}
+
+	// Decoding onto the reconstructed request only replaces the fields present in the body
+	req, _ := parent.Request()
+	if err := c.ShouldBindJSON(req); err != nil && !errors.Is(err, io.EOF) {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
+		return
+	}
+
+	if err := h.resolveCredentials(c, req); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if err := validateSyncRequest(req); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	setRequestOwner(c, req)
+	taskID, err := h.syncService.CloneTask(id, req)
+	if err != nil {
+		h.handleCreateError(c, err)
+		return
+	}
+
+	h.logger.Info("Sync task %s created as clone of %s (source: %s, dest: %s)", taskID, id, req.SourceImage, req.DestImage)
+	h.enqueueTask(c, taskID, req, gin.H{"parentTaskId": id})
+}
+
+// getTask loads a task the current user may access and writes the error response if it
+// cannot be found. Tasks of other users are reported as not found, so that their
+// existence is not revealed.
+func (h *SyncHandler) getTask(c *gin.Context, id string) (*models.SyncTask, error) {
 	task, err := h.syncService.GetTask(id)
 	if err != nil {
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			h.handleError(c, apperrors.WrapTaskNotFound(err))
-			return
+			return nil, err
 		}
 		h.logger.Error("Failed to get task %s: %v", id, err)
 		h.handleError(c, apperrors.WrapInternal(err, "Failed to get task"))
+		return nil, err
+	}
+	if !canAccess(getSessionInfo(c), task.Owner) {
+		h.handleError(c, apperrors.WrapTaskNotFound(repository.ErrTaskNotFound))
+		return nil, repository.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// canAccess reports whether the user of session may see and manage a task owned by owner.
+// Everyone can access all tasks when OIDC is disabled (no session); otherwise users
+// can only access their own tasks, except members of the ADMIN group.
+// Tasks created before owners were recorded are only accessible to admins.
+func canAccess(session *service.SessionInfo, owner string) bool {
+	if session == nil || session.IsAdmin() {
+		return true
+	}
+	return owner != "" && owner == session.UserID
+}
+
+// errNoUserID is the error of a session without user ID, see ownerFilter.
+var errNoUserID = errors.New("session has no user ID")
+
+// ownerFilter returns the owner that lists and bulk operations of the current user are
+// restricted to: the requested owner for admins and when OIDC is disabled (no session),
+// and the user's own ID otherwise. As an empty owner means no filter, the request is
+// rejected if the session of a user other than an admin has no user ID.
+func ownerFilter(c *gin.Context, owner string) (string, error) {
+	session := getSessionInfo(c)
+	if session == nil || session.IsAdmin() {
+		return owner, nil
+	}
+	if session.UserID == "" {
+		return "", apperrors.WrapUnauthorized(errNoUserID, "Session has no user ID")
+	}
+	return session.UserID, nil
+}
+
+// resolveCredentials fills in missing credentials from the saved config named in the request.
+func (h *SyncHandler) resolveCredentials(c *gin.Context, req *models.SyncRequest) error {
+	if req.ConfigName == "" {
+		return nil
+	}
+	return h.configService.ApplyCredentials(getUserIdentifier(c), req.ConfigName, req)
+}
+
+// setRequestOwner records the current user as the owner of the task created for req.
+func setRequestOwner(c *gin.Context, req *models.SyncRequest) {
+	if session := getSessionInfo(c); session != nil {
+		req.Owner = session.UserID
+		req.OwnerEmail = session.Email
+	}
+}
+
+// validateSyncRequest validates the input fields of a sync request for security.
+func validateSyncRequest(req *models.SyncRequest) error {
+	if err := validator.ValidateImageName(req.SourceImage); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid source image")
+	}
+
+	if err := validator.ValidateImageName(req.DestImage); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid destination image")
+	}
+
+	if err := validator.ValidateArchitecture(req.Architecture); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid architecture")
+	}
+
+	if len(req.Architectures) > 0 && req.Architecture != "" {
+		return apperrors.NewInvalidInput("Set either architecture or architectures, not both")
+	}
+	for i, arch := range req.Architectures {
+		if arch == "" || arch == "all" {
+			return apperrors.NewInvalidInput(fmt.Sprintf("Invalid architectures: entry %d must be a platform such as linux/amd64", i+1))
+		}
+		if err := validator.ValidateArchitecture(arch); err != nil {
+			return apperrors.WrapInvalidInput(err, fmt.Sprintf("Invalid architectures: entry %d", i+1))
+		}
+		if slices.Contains(req.Architectures[:i], arch) {
+			return apperrors.NewInvalidInput(fmt.Sprintf("Duplicate architecture %s", arch))
+		}
+	}
+
+	if err := validator.ValidateCredentials(req.SourceUsername, req.SourcePassword); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid source credentials")
+	}
+
+	if err := validator.ValidateCredentials(req.DestUsername, req.DestPassword); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid destination credentials")
+	}
+
+	if err := validator.ValidateDestinations(len(req.Destinations)); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid destinations")
+	}
+
+	seen := map[string]bool{req.DestImage: true}
+	for i, dest := range req.Destinations {
+		if err := validator.ValidateImageName(dest.Image); err != nil {
+			return apperrors.WrapInvalidInput(err, fmt.Sprintf("Invalid destination %d", i+1))
+		}
+		if seen[dest.Image] {
+			return apperrors.NewInvalidInput(fmt.Sprintf("Duplicate destination %s", dest.Image))
+		}
+		seen[dest.Image] = true
+		if err := validator.ValidateCredentials(dest.Username, dest.Password); err != nil {
+			return apperrors.WrapInvalidInput(err, fmt.Sprintf("Invalid credentials of destination %d", i+1))
+		}
+	}
+
+	if err := validator.ValidateRetryTimes(req.RetryTimes); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid retry times")
+	}
+
+	if err := validator.ValidateLabels(req.Labels); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid labels")
+	}
+
+	if err := validator.ValidateNote(req.Note); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid note")
+	}
+
+	return nil
+}
+
+// handleCreateError maps errors from creating a task to HTTP responses.
+func (h *SyncHandler) handleCreateError(c *gin.Context, err error) {
+	var duplicate *service.DuplicateTaskError
+	switch {
+	case errors.As(err, &duplicate):
+		h.handleError(c, apperrors.WrapConflict(err, fmt.Sprintf("The same sync is already %s as task %s", duplicate.Status, duplicate.TaskID)))
+	case errors.Is(err, repository.ErrTaskNotFound):
+		h.handleError(c, apperrors.WrapTaskNotFound(err))
+	case errors.Is(err, service.ErrTaskNotRetryable):
+		h.handleError(c, apperrors.WrapConflict(err, "Only failed, cancelled, interrupted or partial tasks can be retried"))
+	case errors.Is(err, service.ErrCredentialsRequired):
+		h.handleError(c, apperrors.WrapInvalidInput(err, fmt.Sprintf("%v; supply them again or reference a saved config", err)))
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		h.handleError(c, apperrors.WrapConflict(err, "Idempotency-Key was already used for a different sync request"))
+	case errors.Is(err, service.ErrShuttingDown):
+		h.handleError(c, apperrors.WrapUnavailable(err, "Server is shutting down, please retry later"))
+	default:
+		h.logger.Error("Failed to create sync task: %v", err)
+		h.handleError(c, apperrors.WrapInternal(err, "Failed to create sync task"))
+	}
+}
+
+// enqueueTask hands a new task to the queue, where it starts as soon as a worker is free,
+// and writes the response. Fields in extra are added to the response.
+func (h *SyncHandler) enqueueTask(c *gin.Context, taskID string, req *models.SyncRequest, extra gin.H) {
+	position, err := h.syncService.EnqueueTask(taskID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrShuttingDown) {
+			h.handleError(c, apperrors.WrapUnavailable(err, "Server is shutting down, please retry later"))
+			return
+		}
+		h.logger.Error("Failed to enqueue sync task: %v", err)
+		h.handleError(c, apperrors.WrapInternal(err, "Failed to enqueue sync task"))
+		return
+	}
+
+	extra["id"] = taskID
+	if position > 0 {
+		extra["message"] = "Sync queued"
+		extra["queuePosition"] = position
+	} else {
+		extra["message"] = "Sync started"
+	}
+	c.JSON(http.StatusOK, extra)
+}
+
+// GetSyncStatus retrieves the status and details of a sync task by ID.
+//
+// Path parameter:
+//   - id: Task UUID
+//
+// Response (200 OK): Task object with all details (status, queue position, copy progress, logs, timestamps, etc.)
+// Error responses: 404 (task not found or owned by another user), 500 (server error)
+func (h *SyncHandler) GetSyncStatus(c *gin.Context) {
+	task, err := h.getTask(c, c.Param("id"))
+	if err != nil {
 		return
 	}
 
 	c.JSON(http.StatusOK, task)
 }
 
-// StreamLogs streams task logs to the client using Server-Sent Events (SSE).
-// It sends historical logs first, then streams new logs in real-time until task completes.
+// SSE stream timing. Heartbeats keep idle connections from being closed by proxies;
+// the retry interval tells browsers how long to wait before reconnecting.
+const (
+	streamHeartbeatInterval = 15 * time.Second
+	streamRetryMillis       = 3000
+)
+
+// streamStatus is the payload of "status" and "done" events.
+type streamStatus struct {
+	Status        models.SyncStatus `json:"status"`
+	Message       string            `json:"message"`
+	QueuePosition int               `json:"queuePosition,omitempty"`
+}
+
+// StreamLogs streams task logs and state changes to the client using Server-Sent Events (SSE).
+// Each stream reads from its own cursor over the task's log buffer, so no lines are lost
+// for slow clients, and a reconnecting client resumes after the last line it received.
 //
 // Path parameter:
 //   - id: Task UUID
 //
+// Request headers / query parameters:
+//   - Last-Event-ID: Sequence number of the last log line received; sent automatically
+//     by browsers on reconnect. Lines up to and including it are not sent again.
+//   - lastEventId (query, optional): Same as Last-Event-ID, for clients that cannot set headers
+//
 // Response headers:
 //   - Content-Type: text/event-stream
 //   - Cache-Control: no-cache
 //   - Connection: keep-alive
 //
-// Response format: SSE (data: <log line>\n\n)
-// Error responses: 404 (task not found), 500 (server error)
+// Response format: SSE with typed events:
+//   - log: one log line; the event id is the line's sequence number (1-based)
+//     (event: log\nid: 42\ndata: <log line>\n\n)
+//   - status: sent at start and whenever status, message or queue position change
+//     (event: status\ndata: {"status": "running", "message": "Syncing image..."}\n\n)
+//   - progress: copy progress whenever it changes
+//     (event: progress\ndata: {"phase": "copying_blobs", "blobsTotal": 5, ...}\n\n)
+//   - done: final status once the task has finished and all log lines were sent; the stream then ends
+//
+// While the task is idle a comment line (": heartbeat") is sent every 15 seconds.
+// Error responses: 404 (task not found or owned by another user), 500 (server error)
 func (h *SyncHandler) StreamLogs(c *gin.Context) {
-	id := c.Param("id")
-
-	task, err := h.syncService.GetTask(id)
+	task, err := h.getTask(c, c.Param("id"))
 	if err != nil {
-		if errors.Is(err, repository.ErrTaskNotFound) {
-			h.handleError(c, apperrors.WrapTaskNotFound(err))
-			return
-		}
-		h.logger.Error("Failed to get task %s for log streaming: %v", id, err)
-		h.handleError(c, apperrors.WrapInternal(err, "Failed to get task"))
 		return
 	}
 
+	cursor := lastEventID(c)
+
 	// Set SSE headers
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
 	c.Writer.Header().Set("Connection", "keep-alive")
 	c.Writer.Header().Set("X-Accel-Buffering", "no")
 
-	// Send existing logs first
-	existingLogs := task.GetLogLines()
-	taskStatus := task.Status
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetryMillis)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	var lastStatus *streamStatus
+	var lastProgress *models.TaskProgress
+	clientGone := c.Request.Context().Done()
+	for {
+		// Take the status before reading logs: a task that is finished at this point
+		// has written all its log lines, so the read below returns the rest of them.
+		status := &streamStatus{Status: task.Status, Message: task.Message, QueuePosition: task.QueuePosition}
+
+		lines, changed := task.ReadLogs(cursor)
+		for _, line := range lines {
+			cursor++
+			writeEvent(c, "log", strconv.Itoa(cursor), line)
+		}
+		if lastStatus == nil || *status != *lastStatus {
+			writeJSONEvent(c, "status", status)
+			lastStatus = status
+		}
+		if progress := task.GetProgress(); progress != nil && (lastProgress == nil || *progress != *lastProgress) {
+			writeJSONEvent(c, "progress", progress)
+			lastProgress = progress
+		}
+
+		if status.Status.IsTerminal() {
+			writeJSONEvent(c, "done", status)
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		case <-clientGone:
+			// Client disconnected
+			return
+		}
+	}
+}
+
+// lastEventID returns the sequence number of the last log line a reconnecting client
+// received, from the Last-Event-ID header or the lastEventId query parameter.
+// Returns 0 (stream from the beginning) if neither is set or valid.
+func lastEventID(c *gin.Context) int {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("lastEventId")
+	}
+	seq, err := strconv.Atoi(value)
+	if err != nil || seq < 0 {
+		return 0
+	}
+	return seq
+}
 
-	for _, line := range existingLogs {
-		fmt.Fprintf(c.Writer, "data: %s\n\n", line)
-		c.Writer.Flush()
+// writeEvent sends one SSE event. id may be empty for events that do not advance the cursor.
+// Log lines never contain newlines since they are split by line when read.
+func writeEvent(c *gin.Context, event, id, data string) {
+	fmt.Fprintf(c.Writer, "event: %s\n", event)
+	if id != "" {
+		fmt.Fprintf(c.Writer, "id: %s\n", id)
 	}
+	fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+	c.Writer.Flush()
+}
 
-	// If task is already completed, no need to stream further
-	if taskStatus == models.StatusCompleted || taskStatus == models.StatusFailed {
+// writeJSONEvent sends v as the JSON data of an SSE event.
+func writeJSONEvent(c *gin.Context, event string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
 		return
 	}
+	writeEvent(c, event, "", string(data))
+}
+
+// StreamEvents streams task lifecycle events of all tasks using Server-Sent Events (SSE),
+// for dashboards that follow many tasks at once. Only events published after the
+// connection was opened are sent; clients load the current state with ListTasks first.
+// When OIDC is enabled, users only receive events of their own tasks, except members
+// of the ADMIN group, who receive all events.
+//
+// Query parameters:
+//   - status (optional): Comma-separated task statuses to include (e.g. "running,failed")
+//   - owner (optional): Only include tasks created by this user ID or email
+//
+// Response format: SSE, one event per task change, named after the event type
+// (created, started, progress, completed, failed, cancelled, interrupted, skipped):
+//
+//	event: started
+//	data: {"type": "started", "taskId": "task-uuid", "status": "running", "sourceImage": "...", "time": "..."}
+//
+// While idle a comment line (": heartbeat") is sent every 15 seconds. A client that falls
+// too far behind is disconnected and should reconnect and reload the task list.
+func (h *SyncHandler) StreamEvents(c *gin.Context) {
+	statuses := make(map[models.SyncStatus]bool)
+	for _, status := range strings.Split(c.Query("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			statuses[models.SyncStatus(status)] = true
+		}
+	}
+	owner := c.Query("owner")
+	session := getSessionInfo(c)
+
+	sub := h.syncService.Events().Subscribe()
+	defer sub.Close()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetryMillis)
+	c.Writer.Flush()
 
-	// Subscribe to new logs
-	logChan := task.AddLogListener()
-	defer task.RemoveLogListener(logChan)
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
 
-	// Stream new logs until task completes or client disconnects
 	clientGone := c.Request.Context().Done()
 	for {
 		select {
-		case line, ok := <-logChan:
+		case event, ok := <-sub.Events():
 			if !ok {
-				// Channel closed, task completed
+				// Dropped for falling behind; the client reconnects
 				return
 			}
-			fmt.Fprintf(c.Writer, "data: %s\n\n", line)
+			if !canAccess(session, event.Owner) {
+				continue
+			}
+			if len(statuses) > 0 && !statuses[event.Status] {
+				continue
+			}
+			if owner != "" && !event.OwnedBy(owner) {
+				continue
+			}
+			writeJSONEvent(c, string(event.Type), event)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
 			c.Writer.Flush()
 		case <-clientGone:
-			// Client disconnected
 			return
 		}
 	}
 }
 
+// CancelSync cancels a pending or running sync task.
+// A running skopeo process is terminated (SIGTERM, then SIGKILL), its temporary
+// auth file is removed and all log streams of the task are closed.
+//
+// Path parameter:
+//   - id: Task UUID
+//
+// Response (200 OK):
+//
+//	{"message": "Cancellation requested", "id": "task-uuid"}
+//
+// Error responses: 404 (task not found or owned by another user), 409 (task already finished), 500 (server error)
+func (h *SyncHandler) CancelSync(c *gin.Context) {
+	id := c.Param("id")
+
+	if _, err := h.getTask(c, id); err != nil {
+		return
+	}
+
+	if err := h.syncService.CancelTask(id, getUserDisplayName(c)); err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			h.handleError(c, apperrors.WrapTaskNotFound(err))
+			return
+		}
+		if errors.Is(err, service.ErrTaskFinished) {
+			h.handleError(c, apperrors.WrapConflict(err, "Task has already finished"))
+			return
+		}
+		h.logger.Error("Failed to cancel task %s: %v", id, err)
+		h.handleError(c, apperrors.WrapInternal(err, "Failed to cancel task"))
+		return
+	}
+
+	h.logger.Info("Sync task cancellation requested: %s", id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cancellation requested",
+		"id":      id,
+	})
+}
+
+// UpdateSync edits the labels and note of a task of any status.
+//
+// Path parameter:
+//   - id: Task UUID
+//
+// Request body (JSON):
+//   - labels (optional): Labels to add or change; a null value removes the label
+//   - note (optional): New note; an empty string removes it
+//
+// Response (200 OK): the updated task, as returned by GetSyncStatus
+//
+// Error responses: 400 (invalid labels or note), 404 (task not found or owned by another user),
+// 500 (server error)
+func (h *SyncHandler) UpdateSync(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.TaskUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid request body"))
+		return
+	}
+
+	if _, err := h.getTask(c, id); err != nil {
+		return
+	}
+
+	task, err := h.syncService.UpdateTask(id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTaskNotFound):
+			h.handleError(c, apperrors.WrapTaskNotFound(err))
+		case errors.Is(err, service.ErrInvalidMetadata):
+			h.handleError(c, apperrors.WrapInvalidInput(err, err.Error()))
+		default:
+			h.logger.Error("Failed to update task %s: %v", id, err)
+			h.handleError(c, apperrors.WrapInternal(err, "Failed to update task"))
+		}
+		return
+	}
+
+	h.logger.Info("Sync task updated: %s", id)
+	c.JSON(http.StatusOK, task)
+}
+
+// DeleteSync deletes a finished task and its logs.
+//
+// Path parameter:
+//   - id: Task UUID
+//
+// Response (200 OK):
+//
+//	{"message": "Task deleted", "id": "task-uuid"}
+//
+// Error responses: 404 (task not found or owned by another user),
+// 409 (task still pending or running), 500 (server error)
+func (h *SyncHandler) DeleteSync(c *gin.Context) {
+	id := c.Param("id")
+
+	if _, err := h.getTask(c, id); err != nil {
+		return
+	}
+
+	if err := h.syncService.DeleteTask(id); err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			h.handleError(c, apperrors.WrapTaskNotFound(err))
+			return
+		}
+		if errors.Is(err, service.ErrTaskActive) {
+			h.handleError(c, apperrors.WrapConflict(err, "Task is still pending or running; cancel it first"))
+			return
+		}
+		h.logger.Error("Failed to delete task %s: %v", id, err)
+		h.handleError(c, apperrors.WrapInternal(err, "Failed to delete task"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Task deleted",
+		"id":      id,
+	})
+}
+
+// DeleteTasks deletes all finished tasks matching the filters. Pending and running
+// tasks are never deleted. At least one of status and olderThan is required.
+//
+// Query parameters:
+//   - status (optional): Only delete tasks with this final status (completed/failed/cancelled/interrupted/skipped)
+//   - olderThan (optional): Only delete tasks that ended longer ago than this, e.g. 7d, 2w or 12h
+//   - owner (optional): Only delete tasks of this owner user ID or email; only effective for
+//     admins, since other users can only delete their own tasks when OIDC is enabled
+//
+// Response (200 OK):
+//
+//	{"message": "Tasks deleted", "deleted": 42}
+//
+// Error responses: 400 (invalid or missing filters), 401 (session without user ID), 500 (server error)
+func (h *SyncHandler) DeleteTasks(c *gin.Context) {
+	status := models.SyncStatus(c.Query("status"))
+	olderThan := c.Query("olderThan")
+	owner := c.Query("owner")
+
+	if status == "" && olderThan == "" {
+		h.handleError(c, apperrors.NewInvalidInput("status or olderThan is required"))
+		return
+	}
+	if status != "" && !status.IsTerminal() {
+		h.handleError(c, apperrors.NewInvalidInput(fmt.Sprintf("cannot delete %s tasks, only finished ones", status)))
+		return
+	}
+	var endedBefore time.Time
+	if olderThan != "" {
+		age, err := timeutil.ParseDuration(olderThan)
+		if err != nil {
+			h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid olderThan"))
+			return
+		}
+		endedBefore = time.Now().Add(-age)
+	}
+	owner, err := ownerFilter(c, owner)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	deleted, err := h.syncService.DeleteTasks(status, owner, endedBefore)
+	if err != nil {
+		h.logger.Error("Failed to delete tasks: %v", err)
+		h.handleError(c, apperrors.WrapInternal(err, "Failed to delete tasks"))
+		return
+	}
+
+	h.logger.Info("Deleted %d task(s) (status: %s, olderThan: %s, owner: %s)", deleted, status, olderThan, owner)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tasks deleted",
+		"deleted": deleted,
+	})
+}
+
 // GetEnvDefaults returns default registry configuration from environment variables.
 //
 // Response (200 OK):
@@ -236,15 +913,30 @@ func (h *SyncHandler) GetEnvDefaults(c *gin.Context) {
 // Query parameters:
 //   - page (optional): Page number, default 1
 //   - pageSize (optional): Items per page, default 20, max 100
-//   - status (optional): Filter by status (pending/running/completed/failed)
-//   - sortBy (optional): Sort field (startTime/endTime), default startTime
+//   - cursor (optional): nextCursor of the previous response; continues after its last task
+//     and stays stable while new tasks are created (page is ignored)
+//   - status (optional): Filter by status (pending/running/completed/failed/cancelled/interrupted/skipped)
+//   - owner (optional): Filter by owner user ID or email; only effective for admins, since
+//     other users always see just their own tasks when OIDC is enabled
+//   - source, dest (optional): Image substring, or glob if it contains *, ? or [...] (e.g. "*/nginx:*")
+//   - registry (optional): Source or destination registry host (e.g. "registry.example.com")
+//   - architecture (optional): Exact architecture (e.g. "linux/amd64", "all")
+//   - startedAfter, startedBefore, endedAfter, endedBefore (optional): RFC 3339 time range
+//   - minDuration (optional): Only finished tasks that took at least this long (e.g. "5m", "1h")
+//   - label (optional, repeatable): Label selector, "key" (label present) or "key=value";
+//     tasks must match all selectors
+//   - jobId (optional): Only tasks of this batch job
+//   - scheduleId (optional): Only tasks created by runs of this schedule
+//   - watchId (optional): Only tasks created by this watch
+//   - hookId (optional): Only tasks created by pushes received by this registry hook
+//   - sortBy (optional): Sort field (startTime/endTime/duration/sourceImage/destImage), default startTime
 //   - sortOrder (optional): Sort direction (asc/desc), default desc
 //
 // Response (200 OK):
 //
-//	{"total": 100, "page": 1, "pageSize": 20, "tasks": [...]}
+//	{"total": 100, "page": 1, "pageSize": 20, "tasks": [...], "nextCursor": "eyJz..."}
 //
-// Error responses: 400 (invalid parameters), 500 (server error)
+// Error responses: 400 (invalid parameters or cursor), 401 (session without user ID), 500 (server error)
 func (h *SyncHandler) ListTasks(c *gin.Context) {
 	var req models.TaskListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -252,8 +944,20 @@ func (h *SyncHandler) ListTasks(c *gin.Context) {
 		return
 	}
 
+	// Users other than admins only see their own tasks
+	owner, err := ownerFilter(c, req.Owner)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	req.Owner = owner
+
 	resp, err := h.syncService.ListTasks(&req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			h.handleError(c, apperrors.WrapInvalidInput(err, err.Error()))
+			return
+		}
 		h.logger.Error("Failed to list tasks: %v", err)
 		h.handleError(c, apperrors.WrapInternal(err, "Failed to list tasks"))
 		return
@@ -262,6 +966,50 @@ func (h *SyncHandler) ListTasks(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// GetStats returns statistics of the tasks started within a time window.
+//
+// Query parameters:
+//   - since, until (optional): RFC 3339 window bounds, default the last 30 days
+//   - interval (optional): Series bucket size, hour or day; default hour for windows up to 7 days
+//   - owner (optional): Filter by owner user ID or email; only effective for admins, since
+//     other users always get statistics of their own tasks when OIDC is enabled
+//
+// Response (200 OK):
+//
+//	{"total": 120, "succeeded": 110, "failed": 8, "successRate": 0.93, "avgDuration": 42.5,
+//	 "p95Duration": 180.2, "byStatus": {...}, "bySourceRegistry": [...], "byDestRegistry": [...],
+//	 "byUser": [...], "series": [...]}
+//
+// Error responses: 400 (invalid window or interval), 401 (session without user ID), 500 (server error)
+func (h *SyncHandler) GetStats(c *gin.Context) {
+	var req models.TaskStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.handleError(c, apperrors.WrapInvalidInput(err, "Invalid query parameters"))
+		return
+	}
+
+	// Users other than admins only see statistics of their own tasks
+	owner, err := ownerFilter(c, req.Owner)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	req.Owner = owner
+
+	stats, err := h.syncService.TaskStats(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			h.handleError(c, apperrors.WrapInvalidInput(err, err.Error()))
+			return
+		}
+		h.logger.Error("Failed to compute task statistics: %v", err)
+		h.handleError(c, apperrors.WrapInternal(err, "Failed to compute task statistics"))
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
 // Health performs a health check and returns service status.
 //
 // Response (200 OK):
@@ -271,4 +1019,4 @@ func (h *SyncHandler) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ok",
 	})
-}
\ No newline at end of file
+}
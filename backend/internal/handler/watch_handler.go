@@ -0,0 +1,161 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"github.com/lazycatapps/image-sync/internal/models"
+	apperrors "github.com/lazycatapps/image-sync/internal/pkg/errors"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/pkg/validator"
+	"github.com/lazycatapps/image-sync/internal/repository"
+	"github.com/lazycatapps/image-sync/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WatchHandler handles HTTP requests related to watches of source tags.
+type WatchHandler struct {
+	definitionEndpoints[models.Watch, *models.Watch, models.WatchRequest]
+	configService *service.ConfigService // Checks that referenced configs exist
+}
+
+// NewWatchHandler creates a new WatchHandler instance.
+func NewWatchHandler(watcher *service.Watcher, configService *service.ConfigService, logger logger.Logger) *WatchHandler {
+	h := &WatchHandler{configService: configService}
+	h.definitionEndpoints = definitionEndpoints[models.Watch, *models.Watch, models.WatchRequest]{
+		service: watcher,
+		kind: definitionKind[*models.Watch, models.WatchRequest]{
+			name:   "watch",
+			plural: "watches",
+			apply:  (*models.WatchRequest).Apply,
+			list: func(watches []*models.Watch) any {
+				return &models.WatchListResponse{Watches: watches, Total: len(watches)}
+			},
+			notFound:     repository.ErrWatchNotFound,
+			wrapNotFound: apperrors.WrapWatchNotFound,
+			invalid:      service.ErrInvalidWatch,
+		},
+		validate: h.validateWatch,
+		logger:   logger,
+	}
+	return h
+}
+
+// ListWatches lists watches, oldest first, with their last check and digest history.
+//
+// Query parameters:
+//   - owner (optional): Filter by owner user ID or email; only effective for admins
+//
+// Response (200 OK):
+//
+//	{"total": 1, "watches": [{"id": "watch-uuid", "interval": "1h", "enabled": true,
+//	 "lastCheckAt": "...", "nextCheckAt": "...", "lastDigest": "sha256:...", "failures": 0,
+//	 "history": [{"digest": "sha256:...", "detectedAt": "...", "taskId": "task-uuid"}], ...}]}
+//
+// Error responses: 401 (session without user ID), 500 (server error)
+func (h *WatchHandler) ListWatches(c *gin.Context) {
+	h.list(c)
+}
+
+// CreateWatch creates a watch that polls the manifest digest of a source tag and syncs
+// it to the destination whenever the digest changes. The watch is checked right away,
+// and every sync creates a regular sync task with watchId set, owned by the creator.
+//
+// Request body (JSON):
+//   - interval (optional): Time between checks, at least 5m (default: "1h"); failing checks
+//     are backed off up to 6h, and for at least 15m after the registry rate limited a check
+//   - name (optional): Display name
+//   - enabled (optional): Whether the watch is checked (default: true)
+//   - sourceImage, destImage (required), architecture, srcTlsVerify, destTlsVerify, retryTimes,
+//     force, labels, note (optional): the sync, as for SyncImage; with an architecture, only
+//     changes of that platform's image trigger a sync
+//   - configName (optional): Saved config whose credentials are looked up anew for every
+//     check and sync
+//
+// Response (200 OK): the watch, including its ID
+//
+// Error responses: 400 (invalid input or interval, config not found), 500 (server error)
+func (h *WatchHandler) CreateWatch(c *gin.Context) {
+	h.create(c)
+}
+
+// GetWatch returns a watch with its last check and digest history.
+//
+// Path parameter:
+//   - id: Watch UUID
+//
+// Response (200 OK): Watch object
+// Error responses: 404 (watch not found or owned by another user), 500 (server error)
+func (h *WatchHandler) GetWatch(c *gin.Context) {
+	h.get(c)
+}
+
+// UpdateWatch replaces the definition of a watch. The history and the owner are kept;
+// if the source, destination or architecture changed, the watch is checked and synced right away.
+//
+// Path parameter:
+//   - id: Watch UUID
+//
+// Request body (JSON): same as CreateWatch; an omitted enabled keeps the current state
+//
+// Response (200 OK): the updated watch
+//
+// Error responses: 400 (invalid input or interval, config not found),
+// 404 (watch not found or owned by another user), 500 (server error)
+func (h *WatchHandler) UpdateWatch(c *gin.Context) {
+	h.update(c)
+}
+
+// EnableWatch enables a watch, which is checked one interval after its last check.
+//
+// Path parameter:
+//   - id: Watch UUID
+//
+// Response (200 OK): the updated watch
+// Error responses: 404 (watch not found or owned by another user), 500 (server error)
+func (h *WatchHandler) EnableWatch(c *gin.Context) {
+	h.setEnabled(c, true)
+}
+
+// DisableWatch disables a watch. A sync that has already started is not cancelled.
+//
+// Path parameter:
+//   - id: Watch UUID
+//
+// Response (200 OK): the updated watch
+// Error responses: 404 (watch not found or owned by another user), 500 (server error)
+func (h *WatchHandler) DisableWatch(c *gin.Context) {
+	h.setEnabled(c, false)
+}
+
+// DeleteWatch deletes a watch. Tasks it created are kept.
+//
+// Path parameter:
+//   - id: Watch UUID
+//
+// Response (200 OK):
+//
+//	{"message": "Watch deleted", "id": "watch-uuid"}
+//
+// Error responses: 404 (watch not found or owned by another user), 500 (server error)
+func (h *WatchHandler) DeleteWatch(c *gin.Context) {
+	h.delete(c)
+}
+
+// validateWatch validates the input fields of a watch and checks that the config it
+// references exists for its owner. The interval is validated by the watcher.
+func (h *WatchHandler) validateWatch(watch *models.Watch) error {
+	if err := validator.ValidateWatchName(watch.Name); err != nil {
+		return apperrors.WrapInvalidInput(err, "Invalid watch name")
+	}
+
+	req := watch.Request()
+	if err := validateSyncRequest(req); err != nil {
+		return err
+	}
+	if req.ConfigName != "" {
+		return h.configService.ApplyCredentials(service.UserIdentifier(watch.Owner, watch.OwnerEmail), req.ConfigName, req)
+	}
+	return nil
+}
@@ -75,6 +75,7 @@ func isPublicEndpoint(path string) bool {
 		"/api/v1/auth/login",
 		"/api/v1/auth/callback",
 		"/api/v1/auth/userinfo",
+		"/api/v1/hooks/registry/:hookId", // Authenticated by the secret of the hook
 	}
 
 	for _, p := range publicPaths {
@@ -18,8 +18,8 @@ import (
 //   - "*": Allow all origins (reflects the request origin to support credentials)
 //   - Specific origins: Only allow exact matches
 //
-// Allowed methods: GET, POST, PUT, DELETE, OPTIONS
-// Allowed headers: Content-Type, Authorization
+// Allowed methods: GET, POST, PUT, PATCH, DELETE, OPTIONS
+// Allowed headers: Content-Type, Authorization, Idempotency-Key
 //
 // Note: When using credentials (cookies, authorization headers), the wildcard "*"
 // is not allowed by CORS spec. This middleware automatically reflects the request
@@ -56,8 +56,8 @@ func CORS(allowedOrigins []string) gin.HandlerFunc {
 
 		// Only set CORS headers if origin is allowed
 		if allowed {
-			c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key")
 			if allowCredentials {
 				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
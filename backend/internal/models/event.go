@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// TaskEventType identifies a task lifecycle event.
+type TaskEventType string
+
+// Task lifecycle events. A task that finishes emits the event named after its
+// final status (completed, failed, cancelled, interrupted, skipped or partial).
+const (
+	EventCreated     TaskEventType = "created"     // Task created (pending)
+	EventStarted     TaskEventType = "started"     // Copy started
+	EventProgress    TaskEventType = "progress"    // Copy progress changed
+	EventCompleted   TaskEventType = "completed"   // Copy completed successfully
+	EventFailed      TaskEventType = "failed"      // Copy failed
+	EventCancelled   TaskEventType = "cancelled"   // Task cancelled by a user
+	EventInterrupted TaskEventType = "interrupted" // Task stopped by a server restart or shutdown
+	EventSkipped     TaskEventType = "skipped"     // Copy skipped, destination already up to date
+	EventPartial     TaskEventType = "partial"     // Copied to some destinations, the others failed
+)
+
+// TaskEvent is a task lifecycle event broadcast to event stream subscribers.
+// It carries a snapshot of the task taken when the event was emitted.
+type TaskEvent struct {
+	Type          TaskEventType `json:"type"`
+	TaskID        string        `json:"taskId"`
+	SourceImage   string        `json:"sourceImage"`
+	DestImage     string        `json:"destImage"`
+	Status        SyncStatus    `json:"status"`
+	Message       string        `json:"message"`
+	QueuePosition int           `json:"queuePosition,omitempty"`
+	Owner         string        `json:"owner,omitempty"`
+	OwnerEmail    string        `json:"ownerEmail,omitempty"`
+	Progress      *TaskProgress `json:"progress,omitempty"`
+	Time          time.Time     `json:"time"`
+}
+
+// NewTaskEvent creates an event of the given type with a snapshot of task.
+func NewTaskEvent(eventType TaskEventType, task *SyncTask) *TaskEvent {
+	return &TaskEvent{
+		Type:          eventType,
+		TaskID:        task.ID,
+		SourceImage:   task.SourceImage,
+		DestImage:     task.DestImage,
+		Status:        task.Status,
+		Message:       task.Message,
+		QueuePosition: task.QueuePosition,
+		Owner:         task.Owner,
+		OwnerEmail:    task.OwnerEmail,
+		Progress:      task.GetProgress(),
+		Time:          time.Now(),
+	}
+}
+
+// OwnedBy reports whether the event's task was created by the user with the given
+// ID or email.
+func (e *TaskEvent) OwnedBy(user string) bool {
+	return user != "" && (e.Owner == user || e.OwnerEmail == user)
+}
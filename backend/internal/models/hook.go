@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// HookRule maps the images pushed to a registry to their destination.
+type HookRule struct {
+	Repository  string `json:"repository"`  // Regular expression matching the whole pushed repository, e.g. "library/.*" (optional, default: any)
+	Tag         string `json:"tag"`         // Regular expression matching the whole pushed tag (optional, default: any)
+	Destination string `json:"destination"` // Destination reference; {repository}, {name} and {tag} are replaced by those of the pushed image (required)
+}
+
+// HookDefinition is the stored form of what a registry hook syncs.
+// Passwords are never stored; registries that need credentials are accessed with the
+// credentials of the saved config named by ConfigName.
+type HookDefinition struct {
+	SourceRegistry string            `json:"sourceRegistry"`           // Registry host to copy pushed images from (optional, default: the host in the notification)
+	Rules          []HookRule        `json:"rules" binding:"required"` // Rules tried in order; the first matching rule is used (required)
+	Architecture   string            `json:"architecture"`             // Target architecture (optional, default: "all")
+	SrcTLSVerify   *bool             `json:"srcTlsVerify"`             // Source TLS verification (optional, default: true)
+	DestTLSVerify  *bool             `json:"destTlsVerify"`            // Destination TLS verification (optional, default: true)
+	RetryTimes     *int              `json:"retryTimes"`               // Retry times for network failures (optional, default: 3)
+	ConfigName     string            `json:"configName"`               // Saved config of the owner to take credentials from (optional)
+	Force          bool              `json:"force"`                    // Copy even if the destination already has the same digest (optional)
+	Labels         map[string]string `json:"labels"`                   // Labels of the created tasks (optional)
+	Note           string            `json:"note"`                     // Note of the created tasks (optional)
+}
+
+// SyncDefinition returns the definition of the sync copying source to dest with the
+// options of the hook.
+func (d *HookDefinition) SyncDefinition(source, dest string) *SyncDefinition {
+	return &SyncDefinition{
+		SourceImage:   source,
+		DestImage:     dest,
+		Architecture:  d.Architecture,
+		SrcTLSVerify:  d.SrcTLSVerify,
+		DestTLSVerify: d.DestTLSVerify,
+		RetryTimes:    d.RetryTimes,
+		ConfigName:    d.ConfigName,
+		Force:         d.Force,
+		Labels:        CopyLabels(d.Labels),
+		Note:          d.Note,
+	}
+}
+
+// HookEvent records a tag push received by a registry hook and what was done about it.
+type HookEvent struct {
+	ReceivedAt time.Time `json:"receivedAt"`          // Time the notification was received
+	Image      string    `json:"image"`               // Pushed image, e.g. "harbor.example.com/library/nginx:1.27"
+	DestImage  string    `json:"destImage,omitempty"` // Destination of the matching rule (empty if no rule matched)
+	TaskID     string    `json:"taskId,omitempty"`    // Sync task created for the push, or the identical task in progress
+	Error      string    `json:"error,omitempty"`     // Why no sync task was created
+}
+
+// Hook receives push notifications from a registry, such as Docker Distribution or
+// Harbor, and syncs the pushed tags matching its rules. Every sync creates a regular
+// sync task with HookID set.
+type Hook struct {
+	ID      string `json:"id"`               // Unique hook identifier (UUID), part of the notification URL
+	Name    string `json:"name"`             // Display name
+	Enabled bool   `json:"enabled"`          // Disabled hooks acknowledge notifications but ignore them
+	Secret  string `json:"secret,omitempty"` // Shared secret authenticating notifications; only returned when set
+	HookDefinition
+	Owner          string      `json:"owner,omitempty"`          // User ID of the creator (empty if OIDC is disabled)
+	OwnerEmail     string      `json:"ownerEmail,omitempty"`     // Email of the creator (empty if OIDC is disabled)
+	CreatedAt      time.Time   `json:"createdAt"`                // Creation timestamp
+	UpdatedAt      time.Time   `json:"updatedAt"`                // Last modification timestamp
+	LastDeliveryAt *time.Time  `json:"lastDeliveryAt,omitempty"` // Time of the last authenticated notification (nil if none)
+	Events         []HookEvent `json:"events,omitempty"`         // Received tag pushes, most recent first
+}
+
+// GetID returns the ID of the hook.
+func (h *Hook) GetID() string {
+	return h.ID
+}
+
+// IsEnabled reports whether the hook processes notifications.
+func (h *Hook) IsEnabled() bool {
+	return h.Enabled
+}
+
+// SetEnabled enables or disables the hook.
+func (h *Hook) SetEnabled(enabled bool) {
+	h.Enabled = enabled
+}
+
+// GetOwner returns the user ID of the creator of the hook.
+func (h *Hook) GetOwner() string {
+	return h.Owner
+}
+
+// SetOwner records the user ID and email of the creator of the hook.
+func (h *Hook) SetOwner(userID, email string) {
+	h.Owner = userID
+	h.OwnerEmail = email
+}
+
+// GetUpdatedAt returns the last modification time of the hook.
+func (h *Hook) GetUpdatedAt() time.Time {
+	return h.UpdatedAt
+}
+
+// SetCreated sets the ID and the creation and modification times of a new hook.
+func (h *Hook) SetCreated(id string, at time.Time) {
+	h.ID = id
+	h.CreatedAt = at
+	h.UpdatedAt = at
+}
+
+// SetUpdated sets the last modification time of the hook.
+func (h *Hook) SetUpdated(at time.Time) {
+	h.UpdatedAt = at
+}
+
+// WithoutSecret returns a shallow copy of the hook without its secret, for responses.
+func (h *Hook) WithoutSecret() *Hook {
+	copied := *h
+	copied.Secret = ""
+	return &copied
+}
+
+// HookRequest represents the request for creating or replacing a registry hook.
+type HookRequest struct {
+	Name    string `json:"name"`    // Display name (optional)
+	Enabled *bool  `json:"enabled"` // Whether notifications are processed (optional, default: true, or unchanged on update)
+	Secret  string `json:"secret"`  // Shared secret (optional, default: generated on create, or unchanged on update)
+	HookDefinition
+}
+
+// Apply copies the request into a hook. Enabled and Secret are left unchanged if not supplied.
+func (r *HookRequest) Apply(h *Hook) {
+	h.Name = r.Name
+	if r.Enabled != nil {
+		h.Enabled = *r.Enabled
+	}
+	if r.Secret != "" {
+		h.Secret = r.Secret
+	}
+	h.HookDefinition = r.HookDefinition
+	h.Rules = append([]HookRule(nil), r.Rules...)
+	h.Labels = CopyLabels(r.Labels)
+}
+
+// HookListResponse represents the response of listing registry hooks.
+type HookListResponse struct {
+	Hooks []*Hook `json:"hooks"`
+	Total int     `json:"total"`
+}
+
+// HookDeliveryResponse represents the response to a registry notification.
+type HookDeliveryResponse struct {
+	Events  []HookEvent `json:"events"`  // Tag pushes in the notification; other events are ignored
+	Created int         `json:"created"` // Number of sync tasks created
+}
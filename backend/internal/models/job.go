@@ -0,0 +1,195 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SyncJob groups the sync tasks created together by one batch request.
+// Every item of the batch becomes a task with JobID set; the job itself only stores
+// the shared metadata, and its status is derived from the tasks (see JobSummary).
+type SyncJob struct {
+	ID         string            `json:"id"`                   // Unique job identifier (UUID)
+	Name       string            `json:"name,omitempty"`       // Optional display name
+	Owner      string            `json:"owner,omitempty"`      // User ID of the creator (empty if OIDC is disabled)
+	OwnerEmail string            `json:"ownerEmail,omitempty"` // Email of the creator (empty if OIDC is disabled)
+	Labels     map[string]string `json:"labels,omitempty"`     // Labels shared by all tasks of the job
+	Note       string            `json:"note,omitempty"`       // Note shared by all tasks of the job
+	TaskIDs    []string          `json:"taskIds"`              // First task of every item, in request order
+	CreatedAt  time.Time         `json:"createdAt"`
+}
+
+// JobItem is one source/destination pair of a batch request.
+type JobItem struct {
+	SourceImage  string `json:"sourceImage"`            // Source image address (required)
+	DestImage    string `json:"destImage"`              // Destination image address (required)
+	Architecture string `json:"architecture,omitempty"` // Overrides the job's architecture (optional)
+}
+
+// JobRequest represents the request for creating a batch job.
+// Items come from Items and from List, a plain text list with one "source dest [architecture]"
+// per line. All other fields are shared by every item. When the list is sent as a text/plain
+// body, the options without credentials can be passed as query parameters.
+type JobRequest struct {
+	Name           string            `json:"name" form:"name"`                   // Display name (optional)
+	Items          []JobItem         `json:"items"`                              // Source/destination pairs
+	List           string            `json:"list"`                               // Plain text pairs, one per line
+	SourceUsername string            `json:"sourceUsername"`                     // Source registry username (optional)
+	SourcePassword string            `json:"sourcePassword"`                     // Source registry password (optional)
+	DestUsername   string            `json:"destUsername"`                       // Destination registry username (optional)
+	DestPassword   string            `json:"destPassword"`                       // Destination registry password (optional)
+	Architecture   string            `json:"architecture" form:"architecture"`   // Default architecture of the items (optional, default: "all")
+	SrcTLSVerify   *bool             `json:"srcTlsVerify" form:"srcTlsVerify"`   // Source TLS verification (optional, default: false)
+	DestTLSVerify  *bool             `json:"destTlsVerify" form:"destTlsVerify"` // Destination TLS verification (optional, default: false)
+	RetryTimes     *int              `json:"retryTimes" form:"retryTimes"`       // Retry times for network failures (optional, default: 3)
+	ConfigName     string            `json:"configName" form:"configName"`       // Saved config to take credentials from (optional)
+	Force          bool              `json:"force" form:"force"`                 // Copy even if the destination already has the same digest (optional)
+	Labels         map[string]string `json:"labels"`                             // Labels of the job and its tasks (optional)
+	Note           string            `json:"note" form:"note"`                   // Note of the job and its tasks (optional)
+}
+
+// SyncRequests returns the sync request of every item, combined with the shared options,
+// in request order: first Items, then the lines of List.
+// Returns an error if List contains a malformed line.
+func (r *JobRequest) SyncRequests() ([]*SyncRequest, error) {
+	items := r.Items
+	if r.List != "" {
+		parsed, err := ParseJobItems(r.List)
+		if err != nil {
+			return nil, err
+		}
+		items = append(append([]JobItem(nil), items...), parsed...)
+	}
+
+	reqs := make([]*SyncRequest, len(items))
+	for i, item := range items {
+		architecture := item.Architecture
+		if architecture == "" {
+			architecture = r.Architecture
+		}
+		reqs[i] = &SyncRequest{
+			SourceImage:    item.SourceImage,
+			DestImage:      item.DestImage,
+			SourceUsername: r.SourceUsername,
+			SourcePassword: r.SourcePassword,
+			DestUsername:   r.DestUsername,
+			DestPassword:   r.DestPassword,
+			Architecture:   architecture,
+			SrcTLSVerify:   r.SrcTLSVerify,
+			DestTLSVerify:  r.DestTLSVerify,
+			RetryTimes:     r.RetryTimes,
+			ConfigName:     r.ConfigName,
+			Force:          r.Force,
+			Labels:         CopyLabels(r.Labels),
+			Note:           r.Note,
+		}
+	}
+	return reqs, nil
+}
+
+// ParseJobItems parses a plain text list of "source dest [architecture]" lines separated
+// by whitespace. Blank lines and lines starting with # are ignored.
+func ParseJobItems(text string) ([]JobItem, error) {
+	var items []JobItem
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 2:
+			items = append(items, JobItem{SourceImage: fields[0], DestImage: fields[1]})
+		case 3:
+			items = append(items, JobItem{SourceImage: fields[0], DestImage: fields[1], Architecture: fields[2]})
+		default:
+			return nil, fmt.Errorf("line %d: expected \"source dest [architecture]\", got %q", n, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// JobCounts aggregates the statuses of the tasks of a job.
+// Only the latest attempt of every item is counted, so a retried item counts once.
+type JobCounts struct {
+	Total     int `json:"total"`     // Number of items
+	Pending   int `json:"pending"`   // Waiting in the queue
+	Running   int `json:"running"`   // Currently copying
+	Succeeded int `json:"succeeded"` // Completed or skipped
+	Failed    int `json:"failed"`    // Failed or interrupted
+	Partial   int `json:"partial"`   // Copied to some of their destinations only
+	Cancelled int `json:"cancelled"` // Cancelled by a user
+}
+
+// Add counts a task with the given status.
+func (c *JobCounts) Add(status SyncStatus) {
+	c.Total++
+	switch status {
+	case StatusPending:
+		c.Pending++
+	case StatusRunning:
+		c.Running++
+	case StatusCompleted, StatusSkipped:
+		c.Succeeded++
+	case StatusFailed, StatusInterrupted:
+		c.Failed++
+	case StatusPartial:
+		c.Partial++
+	case StatusCancelled:
+		c.Cancelled++
+	}
+}
+
+// Status returns the aggregated job status: pending while all items wait, running while
+// any item is pending or running, and once all items finished completed if all succeeded,
+// failed if any failed, partial if any was only copied to some destinations, and
+// cancelled otherwise.
+func (c *JobCounts) Status() SyncStatus {
+	switch {
+	case c.Pending == c.Total:
+		return StatusPending
+	case c.Pending+c.Running > 0:
+		return StatusRunning
+	case c.Succeeded == c.Total:
+		return StatusCompleted
+	case c.Failed > 0:
+		return StatusFailed
+	case c.Partial > 0:
+		return StatusPartial
+	default:
+		return StatusCancelled
+	}
+}
+
+// JobSummary is a job with its aggregated status. Tasks holds the latest attempt of
+// every item in request order, and is only set when a single job is requested.
+type JobSummary struct {
+	*SyncJob
+	Status SyncStatus     `json:"status"`
+	Counts JobCounts      `json:"counts"`
+	Tasks  []*TaskSummary `json:"tasks,omitempty"`
+}
+
+// JobListRequest represents query parameters for listing jobs.
+type JobListRequest struct {
+	Page     int    `form:"page,default=1"`      // Page number (default: 1)
+	PageSize int    `form:"pageSize,default=20"` // Items per page (default: 20, max: 100)
+	Owner    string `form:"owner"`               // Filter by owner user ID or email (optional)
+}
+
+// JobListResponse represents the response for job list queries, newest jobs first.
+type JobListResponse struct {
+	Total    int           `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"pageSize"`
+	Jobs     []*JobSummary `json:"jobs"`
+}
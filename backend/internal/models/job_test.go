@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "testing"
+
+func TestParseJobItems(t *testing.T) {
+	text := `# release mirrors
+docker.io/library/nginx:1.25 registry.example.com/nginx:1.25
+
+  docker.io/library/redis:7	registry.example.com/redis:7   linux/amd64
+`
+	items, err := ParseJobItems(text)
+	if err != nil {
+		t.Fatalf("ParseJobItems failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+	if items[0].SourceImage != "docker.io/library/nginx:1.25" || items[0].DestImage != "registry.example.com/nginx:1.25" ||
+		items[0].Architecture != "" {
+		t.Errorf("Unexpected first item: %+v", items[0])
+	}
+	if items[1].Architecture != "linux/amd64" {
+		t.Errorf("Expected architecture linux/amd64, got %q", items[1].Architecture)
+	}
+
+	for _, invalid := range []string{"only-source", "a b c d"} {
+		if _, err := ParseJobItems(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestJobRequest_SyncRequests(t *testing.T) {
+	retryTimes := 5
+	req := &JobRequest{
+		Items:        []JobItem{{SourceImage: "src1", DestImage: "dest1", Architecture: "linux/arm64"}},
+		List:         "src2 dest2",
+		DestUsername: "user",
+		DestPassword: "secret",
+		Architecture: "linux/amd64",
+		RetryTimes:   &retryTimes,
+		Labels:       map[string]string{"release": "2025.03"},
+	}
+
+	reqs, err := req.SyncRequests()
+	if err != nil {
+		t.Fatalf("SyncRequests failed: %v", err)
+	}
+	if len(reqs) != 2 || reqs[0].SourceImage != "src1" || reqs[1].SourceImage != "src2" {
+		t.Fatalf("Expected items before list lines, got %d requests", len(reqs))
+	}
+	if reqs[0].Architecture != "linux/arm64" || reqs[1].Architecture != "linux/amd64" {
+		t.Errorf("Expected item architecture to override the default, got %s and %s", reqs[0].Architecture, reqs[1].Architecture)
+	}
+	if reqs[1].DestUsername != "user" || *reqs[1].RetryTimes != 5 || reqs[1].Labels["release"] != "2025.03" {
+		t.Errorf("Expected shared options on every request, got %+v", reqs[1])
+	}
+
+	// Labels are copied, so tasks never share a map
+	reqs[0].Labels["release"] = "changed"
+	if reqs[1].Labels["release"] != "2025.03" {
+		t.Error("Expected labels to be copied per request")
+	}
+}
+
+func TestJobCounts_Status(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []SyncStatus
+		want     SyncStatus
+	}{
+		{"all pending", []SyncStatus{StatusPending, StatusPending}, StatusPending},
+		{"some running", []SyncStatus{StatusPending, StatusRunning, StatusFailed}, StatusRunning},
+		{"partly finished", []SyncStatus{StatusPending, StatusCompleted}, StatusRunning},
+		{"all succeeded", []SyncStatus{StatusCompleted, StatusSkipped}, StatusCompleted},
+		{"some failed", []SyncStatus{StatusCompleted, StatusInterrupted, StatusCancelled}, StatusFailed},
+		{"cancelled", []SyncStatus{StatusCompleted, StatusCancelled}, StatusCancelled},
+		{"partial", []SyncStatus{StatusCompleted, StatusPartial, StatusCancelled}, StatusPartial},
+		{"partial and failed", []SyncStatus{StatusPartial, StatusFailed}, StatusFailed},
+	}
+
+	for _, tt := range tests {
+		var counts JobCounts
+		for _, status := range tt.statuses {
+			counts.Add(status)
+		}
+		if got := counts.Status(); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
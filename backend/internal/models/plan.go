@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+// SyncPlan describes what a sync request would do, without writing to the destination.
+type SyncPlan struct {
+	SourceImage   string          `json:"sourceImage"`
+	DestImage     string          `json:"destImage"`
+	Architecture  string          `json:"architecture"`         // Requested architecture ("all" or os/arch[/variant])
+	SourceDigest  string          `json:"sourceDigest"`         // Digest of the manifest that would be copied
+	DestExists    bool            `json:"destExists"`           // Whether the destination tag already exists
+	DestDigest    string          `json:"destDigest,omitempty"` // Digest currently at the destination for the same selection
+	UpToDate      bool            `json:"upToDate"`             // Destination already has the same digest; the copy would be skipped
+	Overwrite     bool            `json:"overwrite"`            // An existing destination tag would be replaced with different content
+	Platforms     []*PlatformPlan `json:"platforms"`            // Platforms that would be copied
+	Blobs         []*BlobPlan     `json:"blobs"`                // Distinct config and layer blobs of all copied platforms
+	TotalBytes    int64           `json:"totalBytes"`           // Total size of all blobs
+	ExistingBytes int64           `json:"existingBytes"`        // Size of blobs already present at the destination
+	TransferBytes int64           `json:"transferBytes"`        // Size of blobs that would be uploaded
+}
+
+// PlatformPlan describes one platform image of a sync plan.
+type PlatformPlan struct {
+	Platform string `json:"platform"` // os/arch[/variant]
+	Digest   string `json:"digest"`   // Image manifest digest
+	Blobs    int    `json:"blobs"`    // Number of config and layer blobs
+	Bytes    int64  `json:"bytes"`    // Total size of config and layer blobs
+}
+
+// BlobPlan describes one blob of a sync plan.
+// ExistsAtDest is based on the blobs referenced by the current destination tag;
+// blobs the destination repository only holds through other tags are reported as missing.
+type BlobPlan struct {
+	Digest       string `json:"digest"`
+	Size         int64  `json:"size"`
+	ExistsAtDest bool   `json:"existsAtDest"`
+}
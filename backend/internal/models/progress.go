@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Copy phases reported in TaskProgress.Phase, in the order skopeo goes through them.
+// For multi-arch copies the blob, config and manifest phases repeat for every image.
+const (
+	PhasePreparing         = "preparing"          // Reading the source and its signatures
+	PhaseCopyingBlobs      = "copying_blobs"      // Copying layer blobs
+	PhaseCopyingConfig     = "copying_config"     // Copying the image config
+	PhaseWritingManifest   = "writing_manifest"   // Writing the manifest to the destination
+	PhaseStoringSignatures = "storing_signatures" // Storing signatures; the copy is almost done
+)
+
+// TaskProgress is the structured copy progress of a task, parsed from skopeo output.
+type TaskProgress struct {
+	Phase        string `json:"phase"`            // Current copy phase (see Phase* constants)
+	BlobsTotal   int    `json:"blobsTotal"`       // Number of distinct blobs seen so far
+	BlobsDone    int    `json:"blobsDone"`        // Blobs copied
+	BlobsSkipped int    `json:"blobsSkipped"`     // Blobs the destination already had
+	Image        int    `json:"image,omitempty"`  // 1-based index of the image being copied from a manifest list
+	Images       int    `json:"images,omitempty"` // Number of images copied from a manifest list
+}
+
+// Blob states tracked while parsing skopeo output.
+const (
+	blobPending = iota
+	blobDone
+	blobSkipped
+)
+
+var (
+	imagesInListPattern = regexp.MustCompile(`^Copying (\d+) images? generated from \d+ images? in list`)
+	imageInListPattern  = regexp.MustCompile(`^Copying image \S+ \((\d+)/(\d+)\)`)
+	blobPattern         = regexp.MustCompile(`^Copying blob (\S+)(.*)$`)
+)
+
+// ParseProgress updates the task progress from one line of skopeo output.
+// Skopeo prints "Copying blob <digest>" when a blob starts and, depending on its version
+// and whether output is a terminal, "done" or "skipped: already exists" on the same line;
+// blobs still pending when the image config or manifest is written are counted as done.
+// Returns true if the progress changed.
+func (t *SyncTask) ParseProgress(line string) bool {
+	t.logMu.Lock()
+	defer t.logMu.Unlock()
+
+	var p TaskProgress
+	if t.Progress != nil {
+		p = *t.Progress
+	}
+	if t.blobs == nil {
+		t.blobs = make(map[string]int)
+	}
+
+	switch {
+	case strings.HasPrefix(line, "Getting image source signatures"):
+		p.Phase = PhasePreparing
+	case imagesInListPattern.MatchString(line):
+		m := imagesInListPattern.FindStringSubmatch(line)
+		p.Images, _ = strconv.Atoi(m[1])
+	case imageInListPattern.MatchString(line):
+		m := imageInListPattern.FindStringSubmatch(line)
+		p.Image, _ = strconv.Atoi(m[1])
+		p.Images, _ = strconv.Atoi(m[2])
+		p.Phase = PhasePreparing
+	case blobPattern.MatchString(line):
+		m := blobPattern.FindStringSubmatch(line)
+		digest, rest := m[1], strings.ToLower(m[2])
+		p.Phase = PhaseCopyingBlobs
+
+		state, seen := t.blobs[digest]
+		if !seen {
+			p.BlobsTotal++
+			state = blobPending
+		}
+		switch {
+		case strings.Contains(rest, "skipped") || strings.Contains(rest, "already exists"):
+			if state == blobPending {
+				state = blobSkipped
+				p.BlobsSkipped++
+			}
+		case strings.Contains(rest, "done"):
+			if state == blobPending {
+				state = blobDone
+				p.BlobsDone++
+			}
+		}
+		t.blobs[digest] = state
+	case strings.HasPrefix(line, "Copying config"):
+		p.Phase = PhaseCopyingConfig
+		p.BlobsDone += t.completePendingBlobs()
+	case strings.HasPrefix(line, "Writing manifest"):
+		p.Phase = PhaseWritingManifest
+		p.BlobsDone += t.completePendingBlobs()
+	case strings.HasPrefix(line, "Storing signatures"):
+		p.Phase = PhaseStoringSignatures
+	default:
+		return false
+	}
+
+	if t.Progress != nil && *t.Progress == p {
+		return false
+	}
+	// Replace rather than modify, so that readers holding the previous value are unaffected
+	t.Progress = &p
+	return true
+}
+
+// GetProgress returns a copy of the current progress, or nil if no progress was parsed yet.
+// Thread-safe for concurrent access.
+func (t *SyncTask) GetProgress() *TaskProgress {
+	t.logMu.Lock()
+	defer t.logMu.Unlock()
+
+	if t.Progress == nil {
+		return nil
+	}
+	p := *t.Progress
+	return &p
+}
+
+// completePendingBlobs marks all pending blobs as done and returns their number.
+// Must be called with t.logMu held.
+func (t *SyncTask) completePendingBlobs() int {
+	n := 0
+	for digest, state := range t.blobs {
+		if state == blobPending {
+			t.blobs[digest] = blobDone
+			n++
+		}
+	}
+	return n
+}
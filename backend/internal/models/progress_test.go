@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "testing"
+
+func TestSyncTask_ParseProgress(t *testing.T) {
+	task := NewSyncTask("test-id", "src", "dest", "all")
+
+	if task.ParseProgress("Executing: skopeo copy --all docker://src docker://dest") {
+		t.Error("Expected unrelated line not to change progress")
+	}
+	if task.GetProgress() != nil {
+		t.Error("Expected no progress before the copy starts")
+	}
+
+	lines := []string{
+		"Getting image source signatures",
+		"Copying 2 images generated from 2 images in list",
+		"Copying image sha256:aaa (1/2)",
+		"Copying blob sha256:layer1",
+		"Copying blob sha256:layer2",
+		"Copying blob sha256:layer3 skipped: already exists",
+	}
+	for _, line := range lines {
+		task.ParseProgress(line)
+	}
+
+	p := task.GetProgress()
+	if p.Phase != PhaseCopyingBlobs || p.Image != 1 || p.Images != 2 {
+		t.Errorf("Expected copying_blobs of image 1/2, got %+v", p)
+	}
+	if p.BlobsTotal != 3 || p.BlobsDone != 0 || p.BlobsSkipped != 1 {
+		t.Errorf("Expected 3 blobs with 1 skipped, got %+v", p)
+	}
+
+	// Pending blobs are complete once the config is copied
+	task.ParseProgress("Copying config sha256:config1")
+	p = task.GetProgress()
+	if p.Phase != PhaseCopyingConfig || p.BlobsDone != 2 {
+		t.Errorf("Expected copying_config with 2 blobs done, got %+v", p)
+	}
+
+	for _, line := range []string{
+		"Writing manifest to image destination",
+		"Copying image sha256:bbb (2/2)",
+		"Copying blob sha256:layer4 done",
+		"Copying blob sha256:layer4 done",
+		"Writing manifest to image destination",
+		"Writing manifest list to image destination",
+		"Storing signatures",
+	} {
+		task.ParseProgress(line)
+	}
+
+	p = task.GetProgress()
+	expected := TaskProgress{
+		Phase:        PhaseStoringSignatures,
+		BlobsTotal:   4,
+		BlobsDone:    3,
+		BlobsSkipped: 1,
+		Image:        2,
+		Images:       2,
+	}
+	if *p != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *p)
+	}
+}
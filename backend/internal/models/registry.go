@@ -0,0 +1,27 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "strings"
+
+// DefaultRegistry is the registry of image references without a registry host.
+const DefaultRegistry = "docker.io"
+
+// RegistryHost returns the registry host (with port, if any) of an image reference,
+// e.g. "registry.example.com:5000" for "registry.example.com:5000/app/nginx:1.0".
+// References without a registry host, such as "nginx:latest" or "library/nginx",
+// belong to DefaultRegistry.
+func RegistryHost(image string) string {
+	image = strings.TrimPrefix(image, "docker://")
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return DefaultRegistry
+	}
+	// Like Docker, treat the first component as a host only if it looks like one
+	host := image[:i]
+	if strings.ContainsAny(host, ".:") || host == "localhost" {
+		return host
+	}
+	return DefaultRegistry
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "testing"
+
+func TestRegistryHost(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx", "docker.io"},
+		{"nginx:latest", "docker.io"},
+		{"library/nginx:latest", "docker.io"},
+		{"docker.io/library/nginx", "docker.io"},
+		{"registry.example.com/app/nginx:1.0", "registry.example.com"},
+		{"registry.example.com:5000/nginx", "registry.example.com:5000"},
+		{"localhost/nginx", "localhost"},
+		{"docker://ghcr.io/org/app", "ghcr.io"},
+	}
+
+	for _, tt := range tests {
+		if got := RegistryHost(tt.image); got != tt.want {
+			t.Errorf("Expected RegistryHost(%q) = %q, got %q", tt.image, tt.want, got)
+		}
+	}
+}
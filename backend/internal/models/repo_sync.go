@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+// TagFilter selects the tags of a repository to sync. The filters apply in order:
+// include and exclude patterns, then the semver constraint, then the latest limit.
+type TagFilter struct {
+	Include    []string `json:"include"`    // Regular expressions; tags must match at least one (optional, default: all tags)
+	Exclude    []string `json:"exclude"`    // Regular expressions; tags matching any are skipped (optional)
+	Semver     string   `json:"semver"`     // Version constraint such as ">=1.24 <2"; tags that are not versions are skipped (optional)
+	Latest     int      `json:"latest"`     // Only the N highest versions; tags that are not versions are skipped (optional)
+	Prerelease bool     `json:"prerelease"` // Let semver and latest include prereleases such as 1.26.0-rc.1 (optional)
+}
+
+// UsesSemver reports whether the filter selects tags by version.
+func (f *TagFilter) UsesSemver() bool {
+	return f.Semver != "" || f.Latest > 0
+}
+
+// RepoSyncRequest represents the request for syncing the tags of a repository.
+// Each matched tag is copied to the same tag of the destination repository by one
+// task of a batch job.
+type RepoSyncRequest struct {
+	SourceRepository string            `json:"sourceRepository" binding:"required"` // Source repository without tag (required)
+	DestRepository   string            `json:"destRepository" binding:"required"`   // Destination repository without tag (required)
+	Tags             TagFilter         `json:"tags"`                                // Tag selection (optional, default: all tags)
+	Name             string            `json:"name"`                                // Display name of the job (optional)
+	SourceUsername   string            `json:"sourceUsername"`                      // Source registry username (optional)
+	SourcePassword   string            `json:"sourcePassword"`                      // Source registry password (optional)
+	DestUsername     string            `json:"destUsername"`                        // Destination registry username (optional)
+	DestPassword     string            `json:"destPassword"`                        // Destination registry password (optional)
+	Architecture     string            `json:"architecture"`                        // Target architecture (optional, default: "all")
+	SrcTLSVerify     *bool             `json:"srcTlsVerify"`                        // Source TLS verification (optional, default: false)
+	DestTLSVerify    *bool             `json:"destTlsVerify"`                       // Destination TLS verification (optional, default: false)
+	RetryTimes       *int              `json:"retryTimes"`                          // Retry times for network failures (optional, default: 3)
+	ConfigName       string            `json:"configName"`                          // Saved config to take credentials from (optional)
+	Force            bool              `json:"force"`                               // Copy even if the destination already has the same digest (optional)
+	Labels           map[string]string `json:"labels"`                              // Labels of the job and its tasks (optional)
+	Note             string            `json:"note"`                                // Note of the job and its tasks (optional)
+}
+
+// SourceRequest returns a sync request carrying the source repository and credentials,
+// as needed to list tags and to resolve credentials from a saved config.
+func (r *RepoSyncRequest) SourceRequest() *SyncRequest {
+	return &SyncRequest{
+		SourceImage:    r.SourceRepository,
+		DestImage:      r.DestRepository,
+		SourceUsername: r.SourceUsername,
+		SourcePassword: r.SourcePassword,
+		DestUsername:   r.DestUsername,
+		DestPassword:   r.DestPassword,
+		SrcTLSVerify:   r.SrcTLSVerify,
+		DestTLSVerify:  r.DestTLSVerify,
+		ConfigName:     r.ConfigName,
+	}
+}
+
+// JobRequest returns the batch job request copying the given tags.
+func (r *RepoSyncRequest) JobRequest(tags []string) *JobRequest {
+	items := make([]JobItem, len(tags))
+	for i, tag := range tags {
+		items[i] = JobItem{
+			SourceImage: r.SourceRepository + ":" + tag,
+			DestImage:   r.DestRepository + ":" + tag,
+		}
+	}
+	return &JobRequest{
+		Name:           r.Name,
+		Items:          items,
+		SourceUsername: r.SourceUsername,
+		SourcePassword: r.SourcePassword,
+		DestUsername:   r.DestUsername,
+		DestPassword:   r.DestPassword,
+		Architecture:   r.Architecture,
+		SrcTLSVerify:   r.SrcTLSVerify,
+		DestTLSVerify:  r.DestTLSVerify,
+		RetryTimes:     r.RetryTimes,
+		ConfigName:     r.ConfigName,
+		Force:          r.Force,
+		Labels:         CopyLabels(r.Labels),
+		Note:           r.Note,
+	}
+}
+
+// RepoSyncPreview lists the tags a repository sync would copy.
+type RepoSyncPreview struct {
+	SourceRepository string   `json:"sourceRepository"`
+	DestRepository   string   `json:"destRepository"`
+	TotalTags        int      `json:"totalTags"` // Number of tags in the source repository
+	Tags             []string `json:"tags"`      // Matched tags, highest version first when selecting by version, otherwise sorted by name
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// OverlapPolicy decides what a schedule does when its previous run is still pending or running.
+type OverlapPolicy string
+
+const (
+	OverlapSkip  OverlapPolicy = "skip"  // Skip the run (default)
+	OverlapQueue OverlapPolicy = "queue" // Run once the previous run has finished; further due runs are merged into it
+)
+
+// Outcomes of a schedule run, as reported by Schedule.LastRunStatus.
+const (
+	ScheduleRunStarted = "started" // A sync task was created
+	ScheduleRunSkipped = "skipped" // The previous run or an identical sync was still in progress
+	ScheduleRunFailed  = "failed"  // No task could be created, see Schedule.LastError
+)
+
+// SyncDefinition is the stored form of a sync request, as used by schedules.
+// Passwords are never stored; registries that need credentials are accessed with
+// the credentials of the saved config named by ConfigName.
+type SyncDefinition struct {
+	SourceImage   string            `json:"sourceImage" binding:"required"` // Source image address (required)
+	DestImage     string            `json:"destImage" binding:"required"`   // Destination image address (required)
+	Architecture  string            `json:"architecture"`                   // Target architecture (optional, default: "all")
+	SrcTLSVerify  *bool             `json:"srcTlsVerify"`                   // Source TLS verification (optional, default: true)
+	DestTLSVerify *bool             `json:"destTlsVerify"`                  // Destination TLS verification (optional, default: true)
+	RetryTimes    *int              `json:"retryTimes"`                     // Retry times for network failures (optional, default: 3)
+	ConfigName    string            `json:"configName"`                     // Saved config of the owner to take credentials from (optional)
+	Force         bool              `json:"force"`                          // Copy even if the destination already has the same digest (optional)
+	Labels        map[string]string `json:"labels"`                         // Labels of the created tasks (optional)
+	Note          string            `json:"note"`                           // Note of the created tasks (optional)
+}
+
+// Request returns a sync request for the definition, without credentials.
+func (d *SyncDefinition) Request() *SyncRequest {
+	return &SyncRequest{
+		SourceImage:   d.SourceImage,
+		DestImage:     d.DestImage,
+		Architecture:  d.Architecture,
+		SrcTLSVerify:  d.SrcTLSVerify,
+		DestTLSVerify: d.DestTLSVerify,
+		RetryTimes:    d.RetryTimes,
+		ConfigName:    d.ConfigName,
+		Force:         d.Force,
+		Labels:        CopyLabels(d.Labels),
+		Note:          d.Note,
+	}
+}
+
+// Schedule runs a sync periodically according to a cron expression.
+// Every run creates a regular sync task with ScheduleID set.
+type Schedule struct {
+	ID       string        `json:"id"`       // Unique schedule identifier (UUID)
+	Name     string        `json:"name"`     // Display name
+	Cron     string        `json:"cron"`     // Cron expression, e.g. "0 2 * * *"
+	Timezone string        `json:"timezone"` // IANA time zone of the cron expression (empty: server time zone)
+	Enabled  bool          `json:"enabled"`  // Disabled schedules keep their definition but do not run
+	Overlap  OverlapPolicy `json:"overlap"`  // What to do if the previous run is still in progress
+	SyncDefinition
+	Owner         string     `json:"owner,omitempty"`         // User ID of the creator (empty if OIDC is disabled)
+	OwnerEmail    string     `json:"ownerEmail,omitempty"`    // Email of the creator (empty if OIDC is disabled)
+	CreatedAt     time.Time  `json:"createdAt"`               // Creation timestamp
+	UpdatedAt     time.Time  `json:"updatedAt"`               // Last modification timestamp
+	NextRunAt     *time.Time `json:"nextRunAt,omitempty"`     // Next run time (nil if disabled)
+	LastRunAt     *time.Time `json:"lastRunAt,omitempty"`     // Time of the last run (nil if it never ran)
+	LastRunStatus string     `json:"lastRunStatus,omitempty"` // Outcome of the last run: started, skipped or failed
+	LastTaskID    string     `json:"lastTaskId,omitempty"`    // Task created by the most recent started run
+	LastError     string     `json:"lastError,omitempty"`     // Why the last run was skipped or failed
+	RunQueued     bool       `json:"runQueued,omitempty"`     // A run is waiting for the previous one to finish (overlap queue)
+}
+
+// GetID returns the ID of the schedule.
+func (s *Schedule) GetID() string {
+	return s.ID
+}
+
+// IsEnabled reports whether the schedule runs.
+func (s *Schedule) IsEnabled() bool {
+	return s.Enabled
+}
+
+// SetEnabled enables or disables the schedule.
+func (s *Schedule) SetEnabled(enabled bool) {
+	s.Enabled = enabled
+}
+
+// GetOwner returns the user ID of the creator of the schedule.
+func (s *Schedule) GetOwner() string {
+	return s.Owner
+}
+
+// SetOwner records the user ID and email of the creator of the schedule.
+func (s *Schedule) SetOwner(userID, email string) {
+	s.Owner = userID
+	s.OwnerEmail = email
+}
+
+// GetUpdatedAt returns the last modification time of the schedule.
+func (s *Schedule) GetUpdatedAt() time.Time {
+	return s.UpdatedAt
+}
+
+// SetCreated sets the ID and the creation and modification times of a new schedule.
+func (s *Schedule) SetCreated(id string, at time.Time) {
+	s.ID = id
+	s.CreatedAt = at
+	s.UpdatedAt = at
+}
+
+// SetUpdated sets the last modification time of the schedule.
+func (s *Schedule) SetUpdated(at time.Time) {
+	s.UpdatedAt = at
+}
+
+// ScheduleRequest represents the request for creating or replacing a schedule.
+type ScheduleRequest struct {
+	Name     string        `json:"name"`                    // Display name (optional)
+	Cron     string        `json:"cron" binding:"required"` // Cron expression (required)
+	Timezone string        `json:"timezone"`                // IANA time zone (optional, default: server time zone)
+	Enabled  *bool         `json:"enabled"`                 // Whether the schedule runs (optional, default: true, or unchanged on update)
+	Overlap  OverlapPolicy `json:"overlap"`                 // skip or queue (optional, default: skip)
+	SyncDefinition
+}
+
+// Apply copies the request into a schedule. Enabled is left unchanged if not supplied.
+func (r *ScheduleRequest) Apply(s *Schedule) {
+	s.Name = r.Name
+	s.Cron = r.Cron
+	s.Timezone = r.Timezone
+	if r.Enabled != nil {
+		s.Enabled = *r.Enabled
+	}
+	s.Overlap = r.Overlap
+	if s.Overlap == "" {
+		s.Overlap = OverlapSkip
+	}
+	s.SyncDefinition = r.SyncDefinition
+	s.Labels = CopyLabels(r.Labels)
+}
+
+// ScheduleListResponse represents the response of listing schedules.
+type ScheduleListResponse struct {
+	Schedules []*Schedule `json:"schedules"`
+	Total     int         `json:"total"`
+}
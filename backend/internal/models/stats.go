@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// Stats bucket intervals supported by TaskStatsRequest.Interval.
+const (
+	StatsIntervalHour = "hour"
+	StatsIntervalDay  = "day"
+)
+
+// TaskStatsRequest represents the query parameters for task statistics.
+// The window covers tasks started at or after Since and before Until.
+type TaskStatsRequest struct {
+	Since    time.Time `form:"since"`    // Window start (optional, default 30 days before Until)
+	Until    time.Time `form:"until"`    // Window end (optional, default now)
+	Interval string    `form:"interval"` // Series bucket size: hour or day (optional, default by window length)
+	Owner    string    `form:"owner"`    // Filter by owner user ID or email (optional)
+}
+
+// TaskStats summarizes the tasks started within a time window.
+// Succeeded counts completed and skipped tasks; the success rate relates them to
+// succeeded, failed, interrupted and partial tasks, leaving out tasks cancelled by users and
+// tasks that have not finished yet. Durations are those of completed tasks, in seconds.
+type TaskStats struct {
+	Since            time.Time          `json:"since"`
+	Until            time.Time          `json:"until"`
+	Interval         string             `json:"interval"`         // Bucket size of Series
+	Total            int                `json:"total"`            // Tasks started within the window
+	Succeeded        int                `json:"succeeded"`        // Completed or skipped tasks
+	Failed           int                `json:"failed"`           // Failed, interrupted or partial tasks
+	SuccessRate      float64            `json:"successRate"`      // Succeeded / (succeeded + failed), 0 without finished tasks
+	AvgDuration      float64            `json:"avgDuration"`      // Average duration of completed tasks
+	P95Duration      float64            `json:"p95Duration"`      // 95th percentile duration of completed tasks
+	ByStatus         map[SyncStatus]int `json:"byStatus"`         // Task count per status
+	BySourceRegistry []*StatsGroup      `json:"bySourceRegistry"` // Busiest source registries first
+	ByDestRegistry   []*StatsGroup      `json:"byDestRegistry"`   // Busiest destination registries first
+	ByUser           []*StatsGroup      `json:"byUser"`           // Keyed by owner email or ID; empty for tasks without owner
+	Series           []*StatsBucket     `json:"series"`           // One bucket per interval, oldest first
+}
+
+// StatsGroup holds the statistics of the tasks sharing one breakdown key.
+type StatsGroup struct {
+	Key         string  `json:"key"`
+	Total       int     `json:"total"`
+	Succeeded   int     `json:"succeeded"`
+	Failed      int     `json:"failed"`
+	SuccessRate float64 `json:"successRate"`
+	AvgDuration float64 `json:"avgDuration"`
+}
+
+// StatsBucket holds the statistics of the tasks started within one series interval.
+type StatsBucket struct {
+	Start       time.Time `json:"start"`
+	Total       int       `json:"total"`
+	Succeeded   int       `json:"succeeded"`
+	Failed      int       `json:"failed"`
+	AvgDuration float64   `json:"avgDuration"`
+}
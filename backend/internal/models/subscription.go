@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// TagDiscovery records the tags one check of a subscription found missing at the
+// destination, and the batch job that was created to mirror them.
+type TagDiscovery struct {
+	DiscoveredAt time.Time `json:"discoveredAt"`    // Time of the check
+	Tags         []string  `json:"tags"`            // Discovered tags
+	JobID        string    `json:"jobId,omitempty"` // Batch job mirroring the tags
+}
+
+// SubscriptionDefinition is the stored form of a repository sync, as used by subscriptions.
+// Passwords are never stored; registries that need credentials are accessed with the
+// credentials of the saved config named by ConfigName.
+type SubscriptionDefinition struct {
+	SourceRepository string            `json:"sourceRepository" binding:"required"` // Source repository without tag (required)
+	DestRepository   string            `json:"destRepository" binding:"required"`   // Destination repository without tag (required)
+	Tags             TagFilter         `json:"tags"`                                // Tags to mirror (optional, default: all tags)
+	Architecture     string            `json:"architecture"`                        // Target architecture (optional, default: "all")
+	SrcTLSVerify     *bool             `json:"srcTlsVerify"`                        // Source TLS verification (optional, default: true)
+	DestTLSVerify    *bool             `json:"destTlsVerify"`                       // Destination TLS verification (optional, default: true)
+	RetryTimes       *int              `json:"retryTimes"`                          // Retry times for network failures (optional, default: 3)
+	ConfigName       string            `json:"configName"`                          // Saved config of the owner to take credentials from (optional)
+	Force            bool              `json:"force"`                               // Copy even if the destination already has the same digest (optional)
+	Labels           map[string]string `json:"labels"`                              // Labels of the created jobs and tasks (optional)
+	Note             string            `json:"note"`                                // Note of the created jobs and tasks (optional)
+}
+
+// RepoSyncRequest returns a repository sync request for the definition, without credentials.
+func (d *SubscriptionDefinition) RepoSyncRequest() *RepoSyncRequest {
+	return &RepoSyncRequest{
+		SourceRepository: d.SourceRepository,
+		DestRepository:   d.DestRepository,
+		Tags:             d.Tags,
+		Architecture:     d.Architecture,
+		SrcTLSVerify:     d.SrcTLSVerify,
+		DestTLSVerify:    d.DestTLSVerify,
+		RetryTimes:       d.RetryTimes,
+		ConfigName:       d.ConfigName,
+		Force:            d.Force,
+		Labels:           CopyLabels(d.Labels),
+		Note:             d.Note,
+	}
+}
+
+// Subscription periodically lists the tags of a source repository and mirrors the
+// tags matching its filter that the destination repository does not have yet, so that
+// new upstream releases are picked up automatically. Every check that finds such tags
+// creates a batch job copying them.
+type Subscription struct {
+	ID      string `json:"id"`      // Unique subscription identifier (UUID)
+	Name    string `json:"name"`    // Display name
+	Enabled bool   `json:"enabled"` // Disabled subscriptions keep their definition and discoveries but are not checked
+	SubscriptionDefinition
+	Owner       string         `json:"owner,omitempty"`      // User ID of the creator (empty if OIDC is disabled)
+	OwnerEmail  string         `json:"ownerEmail,omitempty"` // Email of the creator (empty if OIDC is disabled)
+	CreatedAt   time.Time      `json:"createdAt"`            // Creation timestamp
+	UpdatedAt   time.Time      `json:"updatedAt"`            // Last modification timestamp
+	Checks                     // Interval and state of the tag checks; LastError also tells why a check was skipped
+	LastJobID   string         `json:"lastJobId,omitempty"`   // Batch job created by the most recent discovery
+	Discoveries []TagDiscovery `json:"discoveries,omitempty"` // Discovered tags, most recent first
+}
+
+// GetID returns the ID of the subscription.
+func (s *Subscription) GetID() string {
+	return s.ID
+}
+
+// IsEnabled reports whether the subscription is checked.
+func (s *Subscription) IsEnabled() bool {
+	return s.Enabled
+}
+
+// SetEnabled enables or disables the subscription.
+func (s *Subscription) SetEnabled(enabled bool) {
+	s.Enabled = enabled
+}
+
+// GetOwner returns the user ID of the creator of the subscription.
+func (s *Subscription) GetOwner() string {
+	return s.Owner
+}
+
+// SetOwner records the user ID and email of the creator of the subscription.
+func (s *Subscription) SetOwner(userID, email string) {
+	s.Owner = userID
+	s.OwnerEmail = email
+}
+
+// GetUpdatedAt returns the last modification time of the subscription.
+func (s *Subscription) GetUpdatedAt() time.Time {
+	return s.UpdatedAt
+}
+
+// SetCreated sets the ID and the creation and modification times of a new subscription.
+func (s *Subscription) SetCreated(id string, at time.Time) {
+	s.ID = id
+	s.CreatedAt = at
+	s.UpdatedAt = at
+}
+
+// SetUpdated sets the last modification time of the subscription.
+func (s *Subscription) SetUpdated(at time.Time) {
+	s.UpdatedAt = at
+}
+
+// GetChecks returns the checks of the subscription.
+func (s *Subscription) GetChecks() *Checks {
+	return &s.Checks
+}
+
+// SubscriptionRequest represents the request for creating or replacing a subscription.
+type SubscriptionRequest struct {
+	Name     string `json:"name"`     // Display name (optional)
+	Interval string `json:"interval"` // Time between checks (optional, default: 1h)
+	Enabled  *bool  `json:"enabled"`  // Whether the subscription is checked (optional, default: true, or unchanged on update)
+	SubscriptionDefinition
+}
+
+// Apply copies the request into a subscription. Enabled is left unchanged if not supplied.
+func (r *SubscriptionRequest) Apply(s *Subscription) {
+	s.Name = r.Name
+	s.Interval = r.Interval
+	if r.Enabled != nil {
+		s.Enabled = *r.Enabled
+	}
+	s.SubscriptionDefinition = r.SubscriptionDefinition
+	s.Labels = CopyLabels(r.Labels)
+}
+
+// SubscriptionListResponse represents the response of listing subscriptions.
+type SubscriptionListResponse struct {
+	Subscriptions []*Subscription `json:"subscriptions"`
+	Total         int             `json:"total"`
+}
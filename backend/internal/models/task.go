@@ -5,6 +5,7 @@
 package models
 
 import (
+	"slices"
 	"sync"
 	"time"
 )
@@ -13,28 +14,83 @@ import (
 type SyncStatus string
 
 const (
-	StatusPending   SyncStatus = "pending"   // Task created, not yet started
-	StatusRunning   SyncStatus = "running"   // Task is currently executing
-	StatusCompleted SyncStatus = "completed" // Task completed successfully
-	StatusFailed    SyncStatus = "failed"    // Task failed with error
+	StatusPending     SyncStatus = "pending"     // Task created, not yet started
+	StatusRunning     SyncStatus = "running"     // Task is currently executing
+	StatusCompleted   SyncStatus = "completed"   // Task completed successfully
+	StatusFailed      SyncStatus = "failed"      // Task failed with error
+	StatusCancelled   SyncStatus = "cancelled"   // Task cancelled by a user
+	StatusInterrupted SyncStatus = "interrupted" // Task stopped by a server restart or shutdown
+	StatusSkipped     SyncStatus = "skipped"     // Copy skipped, destination already had the same digest
+	StatusPartial     SyncStatus = "partial"     // Copied to some destinations, the others failed (allowPartial)
 )
 
+// TerminalStatuses lists the final statuses, for which IsTerminal returns true.
+var TerminalStatuses = []SyncStatus{StatusCompleted, StatusFailed, StatusCancelled, StatusInterrupted, StatusSkipped, StatusPartial}
+
+// IsTerminal reports whether the status is final, i.e. the task is neither waiting nor running.
+func (s SyncStatus) IsTerminal() bool {
+	for _, terminal := range TerminalStatuses {
+		if s == terminal {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryable reports whether a task with this status may be retried,
+// i.e. it finished without completing successfully.
+func (s SyncStatus) IsRetryable() bool {
+	switch s {
+	case StatusFailed, StatusCancelled, StatusInterrupted, StatusPartial:
+		return true
+	default:
+		return false
+	}
+}
+
 // SyncTask represents an image synchronization task.
 // It tracks task metadata, status, logs, and provides real-time log streaming to clients.
 type SyncTask struct {
-	ID           string        `json:"id"`           // Unique task identifier (UUID)
-	SourceImage  string        `json:"sourceImage"`  // Source image address
-	DestImage    string        `json:"destImage"`    // Destination image address
-	Architecture string        `json:"architecture"` // Target architecture (e.g., "linux/amd64", "all")
-	Status       SyncStatus    `json:"status"`       // Current task status
-	Message      string        `json:"message"`      // Human-readable status message
-	Output       string        `json:"output"`       // Complete log output (set when task completes)
-	ErrorOutput  string        `json:"errorOutput"`  // Error message (if task failed)
-	StartTime    time.Time     `json:"startTime"`    // Task start timestamp
-	EndTime      *time.Time    `json:"endTime,omitempty"` // Task end timestamp (nil if not completed)
-	LogLines     []string      `json:"-"`            // In-memory log lines (not serialized)
-	LogListeners []chan string `json:"-"`            // Active log stream subscribers (SSE)
-	logMu        sync.Mutex    // Mutex for thread-safe log operations
+	ID             string              `json:"id"`                       // Unique task identifier (UUID)
+	SourceImage    string              `json:"sourceImage"`              // Source image address
+	DestImage      string              `json:"destImage"`                // Destination image address
+	Architecture   string              `json:"architecture"`             // Target architecture (e.g., "linux/amd64", "all"), or the comma-separated Architectures
+	Architectures  []string            `json:"architectures,omitempty"`  // Platforms copied into a trimmed manifest list (if any)
+	Status         SyncStatus          `json:"status"`                   // Current task status
+	Message        string              `json:"message"`                  // Human-readable status message
+	Output         string              `json:"output"`                   // Complete log output (set when task completes)
+	ErrorOutput    string              `json:"errorOutput"`              // Error message (if task failed)
+	StartTime      time.Time           `json:"startTime"`                // Task start timestamp
+	EndTime        *time.Time          `json:"endTime,omitempty"`        // Task end timestamp (nil if not completed)
+	RetryTimes     int                 `json:"retryTimes"`               // Retry times for network failures
+	SrcTLSVerify   bool                `json:"srcTlsVerify"`             // Source TLS verification
+	DestTLSVerify  bool                `json:"destTlsVerify"`            // Destination TLS verification
+	SourceAuth     bool                `json:"sourceAuth"`               // Whether source credentials were supplied (credentials are never stored)
+	DestAuth       bool                `json:"destAuth"`                 // Whether destination credentials were supplied (credentials are never stored)
+	Destinations   []DestinationResult `json:"destinations,omitempty"`   // Result of every destination, DestImage first (only for syncs with destinations)
+	AllowPartial   bool                `json:"allowPartial,omitempty"`   // Report failed destinations as partial success if others succeeded
+	Force          bool                `json:"force,omitempty"`          // Copy even if the destination already has the same digest
+	ConfigName     string              `json:"configName,omitempty"`     // Saved config the credentials were taken from (if any)
+	ParentTaskID   string              `json:"parentTaskId,omitempty"`   // Task this one was retried or cloned from (if any)
+	Owner          string              `json:"owner,omitempty"`          // User ID of the creator (empty if OIDC is disabled)
+	OwnerEmail     string              `json:"ownerEmail,omitempty"`     // Email of the creator (empty if OIDC is disabled)
+	Labels         map[string]string   `json:"labels,omitempty"`         // Free-form key/value labels, e.g. ticket or release (replaced, never modified in place)
+	Note           string              `json:"note,omitempty"`           // Free-form note
+	IdempotencyKey string              `json:"idempotencyKey,omitempty"` // Idempotency-Key header of the creating request (if any)
+	JobID          string              `json:"jobId,omitempty"`          // Batch job the task belongs to (if any)
+	ScheduleID     string              `json:"scheduleId,omitempty"`     // Schedule whose run created the task (if any)
+	WatchID        string              `json:"watchId,omitempty"`        // Watch that created the task on a digest change (if any)
+	HookID         string              `json:"hookId,omitempty"`         // Registry hook that created the task on a push (if any)
+	QueuePosition  int                 `json:"queuePosition,omitempty"`  // 1-based position in the task queue (0 if not waiting)
+	CancelledBy    string              `json:"cancelledBy,omitempty"`    // User who cancelled the task (if cancelled)
+	CancelledAt    *time.Time          `json:"cancelledAt,omitempty"`    // Cancellation timestamp (nil if not cancelled)
+	Resumable      bool                `json:"resumable,omitempty"`      // Interrupted by the last shutdown and not yet considered for resuming
+	Progress       *TaskProgress       `json:"progress,omitempty"`       // Copy progress parsed from skopeo output (nil until the copy starts)
+	LogLines       []string            `json:"-"`                        // In-memory log lines (not serialized)
+
+	logMu   sync.Mutex     // Mutex for thread-safe log and progress operations
+	changed chan struct{}  // Closed and replaced on every change to wake up log streams (guarded by logMu)
+	blobs   map[string]int // Blob states for progress parsing, keyed by digest (guarded by logMu)
 }
 
 // NewSyncTask creates a new sync task with initial pending status.
@@ -48,64 +104,140 @@ func NewSyncTask(id, sourceImage, destImage, architecture string) *SyncTask {
 		Message:      "Task created",
 		StartTime:    time.Now(),
 		LogLines:     []string{},
-		LogListeners: []chan string{},
 	}
 }
 
-// AddLog appends a log line to the task and broadcasts it to all active listeners.
-// Thread-safe for concurrent access.
-func (t *SyncTask) AddLog(line string) {
-	t.logMu.Lock()
-	defer t.logMu.Unlock()
+// Request reconstructs the sync request of the task without credentials.
+// The second return value is false if the original request used credentials,
+// in which case they must be supplied again before the request can be executed.
+func (t *SyncTask) Request() (*SyncRequest, bool) {
+	retryTimes := t.RetryTimes
+	srcTLSVerify := t.SrcTLSVerify
+	destTLSVerify := t.DestTLSVerify
+	architecture := t.Architecture
+	if len(t.Architectures) > 0 {
+		architecture = ""
+	}
+	req := &SyncRequest{
+		SourceImage:   t.SourceImage,
+		DestImage:     t.DestImage,
+		Architecture:  architecture,
+		Architectures: slices.Clone(t.Architectures),
+		SrcTLSVerify:  &srcTLSVerify,
+		DestTLSVerify: &destTLSVerify,
+		RetryTimes:    &retryTimes,
+		Force:         t.Force,
+		AllowPartial:  t.AllowPartial,
+		ConfigName:    t.ConfigName,
+		Labels:        CopyLabels(t.Labels),
+		Note:          t.Note,
+		JobID:         t.JobID,
+	}
+	complete := !t.SourceAuth && !t.DestAuth
+	// The first result is DestImage; the others are the additional destinations
+	for i := 1; i < len(t.Destinations); i++ {
+		dest := t.Destinations[i]
+		tlsVerify := dest.TLSVerify
+		req.Destinations = append(req.Destinations, SyncDestination{Image: dest.Image, TLSVerify: &tlsVerify})
+		complete = complete && !dest.Auth
+	}
+	return req, complete
+}
 
-	t.LogLines = append(t.LogLines, line)
-	// Broadcast to all SSE listeners
-	for _, ch := range t.LogListeners {
-		select {
-		case ch <- line:
-			// Successfully sent
-		default:
-			// Channel is full or closed, skip this listener
-		}
+// DestinationResult is the outcome of copying a task to one of its destinations.
+type DestinationResult struct {
+	Image     string     `json:"image"`           // Destination image address
+	TLSVerify bool       `json:"tlsVerify"`       // Destination TLS verification
+	Auth      bool       `json:"auth"`            // Whether credentials were supplied (credentials are never stored)
+	Status    SyncStatus `json:"status"`          // pending, running, completed, skipped, failed, cancelled or interrupted
+	Error     string     `json:"error,omitempty"` // Why the copy to this destination failed
+}
+
+// Summary returns the summarized view of the task, without logs.
+func (t *SyncTask) Summary() *TaskSummary {
+	return &TaskSummary{
+		ID:            t.ID,
+		SourceImage:   t.SourceImage,
+		DestImage:     t.DestImage,
+		Architecture:  t.Architecture,
+		Status:        t.Status,
+		Message:       t.Message,
+		QueuePosition: t.QueuePosition,
+		ParentTaskID:  t.ParentTaskID,
+		JobID:         t.JobID,
+		ScheduleID:    t.ScheduleID,
+		WatchID:       t.WatchID,
+		HookID:        t.HookID,
+		Owner:         t.Owner,
+		OwnerEmail:    t.OwnerEmail,
+		Labels:        t.Labels,
+		Note:          t.Note,
+		StartTime:     t.StartTime,
+		EndTime:       t.EndTime,
 	}
 }
 
-// AddLogListener creates a new log listener channel for SSE streaming.
-// Returns a buffered channel that will receive new log lines.
-func (t *SyncTask) AddLogListener() chan string {
+// CopyLabels returns a copy of labels, or nil if there are none.
+func CopyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(labels))
+	for key, value := range labels {
+		copied[key] = value
+	}
+	return copied
+}
+
+// AddLog appends a log line to the task and wakes up all log streams.
+// Thread-safe for concurrent access.
+func (t *SyncTask) AddLog(line string) {
 	t.logMu.Lock()
 	defer t.logMu.Unlock()
 
-	ch := make(chan string, 100)
-	t.LogListeners = append(t.LogListeners, ch)
-	return ch
+	t.LogLines = append(t.LogLines, line)
+	t.notifyLocked()
 }
 
-// RemoveLogListener removes and closes a log listener channel.
-// Should be called when an SSE client disconnects.
-func (t *SyncTask) RemoveLogListener(ch chan string) {
+// ReadLogs returns the log lines after the first from lines, for log streaming.
+// The sequence number of lines[i] is from+i+1. The returned channel is closed on the
+// next change of the task (a new log line or a Notify call), so a stream can wait on it
+// and read again from its new position without missing lines.
+// Thread-safe for concurrent access.
+func (t *SyncTask) ReadLogs(from int) (lines []string, changed <-chan struct{}) {
 	t.logMu.Lock()
 	defer t.logMu.Unlock()
 
-	for i, listener := range t.LogListeners {
-		if listener == ch {
-			t.LogListeners = append(t.LogListeners[:i], t.LogListeners[i+1:]...)
-			close(ch)
-			break
-		}
+	if from < 0 {
+		from = 0
+	}
+	if from < len(t.LogLines) {
+		lines = make([]string, len(t.LogLines)-from)
+		copy(lines, t.LogLines[from:])
+	}
+	if t.changed == nil {
+		t.changed = make(chan struct{})
 	}
+	return lines, t.changed
 }
 
-// CloseAllLogListeners closes all active log listener channels.
-// Called when task completes to notify all SSE clients.
-func (t *SyncTask) CloseAllLogListeners() {
+// Notify wakes up all log streams so they pick up changes other than log lines,
+// such as a new status. Must be called after the change has been made.
+// Thread-safe for concurrent access.
+func (t *SyncTask) Notify() {
 	t.logMu.Lock()
 	defer t.logMu.Unlock()
 
-	for _, ch := range t.LogListeners {
-		close(ch)
+	t.notifyLocked()
+}
+
+// notifyLocked closes the current change channel and replaces it with a new one.
+// Must be called with t.logMu held.
+func (t *SyncTask) notifyLocked() {
+	if t.changed != nil {
+		close(t.changed)
 	}
-	t.LogListeners = []chan string{}
+	t.changed = make(chan struct{})
 }
 
 // GetLogLines returns a copy of all log lines.
@@ -121,16 +253,52 @@ func (t *SyncTask) GetLogLines() []string {
 
 // SyncRequest represents the request body for creating a sync task.
 type SyncRequest struct {
-	SourceImage    string `json:"sourceImage" binding:"required"` // Source image address (required)
-	DestImage      string `json:"destImage" binding:"required"`   // Destination image address (required)
-	SourceUsername string `json:"sourceUsername"`                 // Source registry username (optional)
-	SourcePassword string `json:"sourcePassword"`                 // Source registry password (optional)
-	DestUsername   string `json:"destUsername"`                   // Destination registry username (optional)
-	DestPassword   string `json:"destPassword"`                   // Destination registry password (optional)
-	Architecture   string `json:"architecture"`                   // Target architecture (optional, default: "all")
-	SrcTLSVerify   *bool  `json:"srcTlsVerify"`                   // Source TLS verification (optional, default: false)
-	DestTLSVerify  *bool  `json:"destTlsVerify"`                  // Destination TLS verification (optional, default: false)
-	RetryTimes     *int   `json:"retryTimes"`                     // Retry times for network failures (optional, default: 3)
+	SourceImage    string            `json:"sourceImage" binding:"required"` // Source image address (required)
+	DestImage      string            `json:"destImage" binding:"required"`   // Destination image address (required)
+	SourceUsername string            `json:"sourceUsername"`                 // Source registry username (optional)
+	SourcePassword string            `json:"sourcePassword"`                 // Source registry password (optional)
+	DestUsername   string            `json:"destUsername"`                   // Destination registry username (optional)
+	DestPassword   string            `json:"destPassword"`                   // Destination registry password (optional)
+	Architecture   string            `json:"architecture"`                   // Target architecture (optional, default: "all")
+	Architectures  []string          `json:"architectures"`                  // Platforms to copy into a manifest list of only those platforms (optional, instead of architecture)
+	SrcTLSVerify   *bool             `json:"srcTlsVerify"`                   // Source TLS verification (optional, default: false)
+	DestTLSVerify  *bool             `json:"destTlsVerify"`                  // Destination TLS verification (optional, default: false)
+	RetryTimes     *int              `json:"retryTimes"`                     // Retry times for network failures (optional, default: 3)
+	ConfigName     string            `json:"configName"`                     // Saved config to take credentials from when not supplied (optional)
+	Force          bool              `json:"force"`                          // Copy even if the destination already has the same digest (optional)
+	Destinations   []SyncDestination `json:"destinations"`                   // Additional destinations, copied from the same source pull (optional)
+	AllowPartial   bool              `json:"allowPartial"`                   // Finish as partial instead of failed if only some destinations failed (optional)
+	DryRun         bool              `json:"dryRun"`                         // Only report what the sync would do (optional)
+	Labels         map[string]string `json:"labels"`                         // Free-form key/value labels (optional)
+	Note           string            `json:"note"`                           // Free-form note (optional)
+	Owner          string            `json:"-"`                              // User ID of the requester, set from the session
+	OwnerEmail     string            `json:"-"`                              // Email of the requester, set from the session
+	IdempotencyKey string            `json:"-"`                              // Idempotency-Key header of the request, set by the handler
+	JobID          string            `json:"-"`                              // Batch job of the task, set when creating a job or retrying one of its tasks
+	ScheduleID     string            `json:"-"`                              // Schedule of the task, set by scheduled runs
+	WatchID        string            `json:"-"`                              // Watch of the task, set by watches on a digest change
+	HookID         string            `json:"-"`                              // Registry hook of the task, set by pushes received by a hook
+}
+
+// SyncDestination is an additional destination of a sync request. The source is pulled
+// once and copied to DestImage and every additional destination.
+type SyncDestination struct {
+	Image     string `json:"image"`     // Destination image address (required)
+	Username  string `json:"username"`  // Registry username (optional)
+	Password  string `json:"password"`  // Registry password (optional)
+	TLSVerify *bool  `json:"tlsVerify"` // TLS verification (optional, default: destTlsVerify)
+}
+
+// RetryRequest represents the optional request body for retrying a task.
+// Credentials are never stored with a task, so they must be supplied again
+// or referenced through a saved config.
+type RetryRequest struct {
+	SourceUsername string            `json:"sourceUsername"` // Source registry username (optional)
+	SourcePassword string            `json:"sourcePassword"` // Source registry password (optional)
+	DestUsername   string            `json:"destUsername"`   // Destination registry username (optional)
+	DestPassword   string            `json:"destPassword"`   // Destination registry password (optional)
+	ConfigName     string            `json:"configName"`     // Saved config to take credentials from (optional, default: the original task's config)
+	Destinations   []SyncDestination `json:"destinations"`   // Credentials of the additional destinations, matched by image (optional)
 }
 
 // InspectRequest represents the request body for inspecting an image.
@@ -153,30 +321,66 @@ type EnvDefaults struct {
 }
 
 // TaskListRequest represents query parameters for listing tasks.
+// Times are RFC 3339; ranges include their lower bound and exclude their upper bound.
 type TaskListRequest struct {
-	Page      int        `form:"page,default=1"`              // Page number (default: 1)
-	PageSize  int        `form:"pageSize,default=20"`         // Items per page (default: 20, max: 100)
-	Status    SyncStatus `form:"status"`                      // Filter by status (optional)
-	SortBy    string     `form:"sortBy,default=startTime"`    // Sort field (default: startTime)
-	SortOrder string     `form:"sortOrder,default=desc"`      // Sort order: asc/desc (default: desc)
+	Page          int        `form:"page,default=1"`           // Page number (default: 1)
+	PageSize      int        `form:"pageSize,default=20"`      // Items per page (default: 20, max: 100)
+	Status        SyncStatus `form:"status"`                   // Filter by status (optional)
+	Owner         string     `form:"owner"`                    // Filter by owner user ID or email (optional)
+	Source        string     `form:"source"`                   // Source image substring, or glob with *, ? and [...] (optional)
+	Dest          string     `form:"dest"`                     // Destination image substring or glob (optional)
+	Registry      string     `form:"registry"`                 // Source or destination registry host (optional)
+	Architecture  string     `form:"architecture"`             // Filter by architecture (optional)
+	StartedAfter  time.Time  `form:"startedAfter"`             // Started at or after this time (optional)
+	StartedBefore time.Time  `form:"startedBefore"`            // Started before this time (optional)
+	EndedAfter    time.Time  `form:"endedAfter"`               // Ended at or after this time (optional)
+	EndedBefore   time.Time  `form:"endedBefore"`              // Ended before this time (optional)
+	MinDuration   string     `form:"minDuration"`              // Finished tasks that took at least this long, e.g. 5m (optional)
+	SortBy        string     `form:"sortBy,default=startTime"` // Sort field: startTime, endTime, duration, sourceImage or destImage (default: startTime)
+	SortOrder     string     `form:"sortOrder,default=desc"`   // Sort order: asc/desc (default: desc)
+	Cursor        string     `form:"cursor"`                   // Continue after this cursor instead of using page (optional)
+	Labels        []string   `form:"label"`                    // Label selectors, "key" or "key=value"; all must match (optional, repeatable)
+	JobID         string     `form:"jobId"`                    // Filter by batch job (optional)
+	ScheduleID    string     `form:"scheduleId"`               // Filter by schedule (optional)
+	WatchID       string     `form:"watchId"`                  // Filter by watch (optional)
+	HookID        string     `form:"hookId"`                   // Filter by registry hook (optional)
+}
+
+// TaskUpdateRequest represents the request body for editing the labels and note of a task.
+// Labels are merged into the existing ones, and a null value removes a label;
+// an omitted note is left unchanged, and an empty one removes it.
+type TaskUpdateRequest struct {
+	Labels map[string]*string `json:"labels"` // Labels to set, or to remove with null (optional)
+	Note   *string            `json:"note"`   // New note (optional)
 }
 
 // TaskSummary represents a summarized view of a task (without full logs).
 type TaskSummary struct {
-	ID           string     `json:"id"`
-	SourceImage  string     `json:"sourceImage"`
-	DestImage    string     `json:"destImage"`
-	Architecture string     `json:"architecture"`
-	Status       SyncStatus `json:"status"`
-	Message      string     `json:"message"`
-	StartTime    time.Time  `json:"startTime"`
-	EndTime      *time.Time `json:"endTime,omitempty"`
+	ID            string            `json:"id"`
+	SourceImage   string            `json:"sourceImage"`
+	DestImage     string            `json:"destImage"`
+	Architecture  string            `json:"architecture"`
+	Status        SyncStatus        `json:"status"`
+	Message       string            `json:"message"`
+	QueuePosition int               `json:"queuePosition,omitempty"`
+	ParentTaskID  string            `json:"parentTaskId,omitempty"`
+	JobID         string            `json:"jobId,omitempty"`
+	ScheduleID    string            `json:"scheduleId,omitempty"`
+	WatchID       string            `json:"watchId,omitempty"`
+	HookID        string            `json:"hookId,omitempty"`
+	Owner         string            `json:"owner,omitempty"`
+	OwnerEmail    string            `json:"ownerEmail,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Note          string            `json:"note,omitempty"`
+	StartTime     time.Time         `json:"startTime"`
+	EndTime       *time.Time        `json:"endTime,omitempty"`
 }
 
 // TaskListResponse represents the response for task list queries.
 type TaskListResponse struct {
-	Total    int            `json:"total"`    // Total number of tasks matching filter
-	Page     int            `json:"page"`     // Current page number
-	PageSize int            `json:"pageSize"` // Items per page
-	Tasks    []*TaskSummary `json:"tasks"`    // Task summaries for current page
-}
\ No newline at end of file
+	Total      int            `json:"total"`                // Total number of tasks matching filter
+	Page       int            `json:"page"`                 // Current page number (1 when paginating by cursor)
+	PageSize   int            `json:"pageSize"`             // Items per page
+	Tasks      []*TaskSummary `json:"tasks"`                // Task summaries for current page
+	NextCursor string         `json:"nextCursor,omitempty"` // Cursor of the next page (empty on the last page)
+}
@@ -3,10 +3,7 @@
 
 package models
 
-import (
-	"testing"
-	"time"
-)
+import "testing"
 
 func TestNewSyncTask(t *testing.T) {
 	task := NewSyncTask("test-id", "nginx:latest", "registry.example.com/nginx:latest", "all")
@@ -52,42 +49,166 @@ func TestSyncTask_AddLog(t *testing.T) {
 	}
 }
 
-func TestSyncTask_AddLogListener(t *testing.T) {
+func TestSyncTask_ReadLogs(t *testing.T) {
 	task := NewSyncTask("test-id", "src", "dest", "all")
+	task.AddLog("First")
 
-	ch := task.AddLogListener()
-	if ch == nil {
-		t.Error("Expected non-nil channel")
+	lines, changed := task.ReadLogs(0)
+	if len(lines) != 1 || lines[0] != "First" {
+		t.Fatalf("Expected [First], got %v", lines)
 	}
 
-	go func() {
-		task.AddLog("Test message")
-	}()
+	task.AddLog("Second")
+	task.AddLog("Third")
 
 	select {
-	case msg := <-ch:
-		if msg != "Test message" {
-			t.Errorf("Expected 'Test message', got '%s'", msg)
+	case <-changed:
+	default:
+		t.Fatal("Expected change channel to be closed by AddLog")
+	}
+
+	// Lines added while the reader was not waiting are not lost
+	lines, _ = task.ReadLogs(1)
+	if len(lines) != 2 || lines[0] != "Second" || lines[1] != "Third" {
+		t.Errorf("Expected [Second Third] after cursor 1, got %v", lines)
+	}
+
+	if lines, _ := task.ReadLogs(3); len(lines) != 0 {
+		t.Errorf("Expected no lines at end of buffer, got %v", lines)
+	}
+	if lines, _ := task.ReadLogs(10); len(lines) != 0 {
+		t.Errorf("Expected no lines past end of buffer, got %v", lines)
+	}
+}
+
+func TestSyncTask_Notify(t *testing.T) {
+	task := NewSyncTask("test-id", "src", "dest", "all")
+
+	_, ch1 := task.ReadLogs(0)
+	_, ch2 := task.ReadLogs(0)
+
+	task.Notify()
+
+	for _, ch := range []<-chan struct{}{ch1, ch2} {
+		select {
+		case <-ch:
+		default:
+			t.Error("Expected all change channels to be closed")
 		}
-	case <-time.After(1 * time.Second):
-		t.Error("Timeout waiting for log message")
 	}
 
-	task.RemoveLogListener(ch)
+	_, ch3 := task.ReadLogs(0)
+	select {
+	case <-ch3:
+		t.Error("Expected a new change channel after Notify")
+	default:
+	}
 }
 
-func TestSyncTask_CloseAllLogListeners(t *testing.T) {
+func TestSyncStatus_IsTerminal(t *testing.T) {
+	tests := []struct {
+		status   SyncStatus
+		terminal bool
+	}{
+		{StatusPending, false},
+		{StatusRunning, false},
+		{StatusCompleted, true},
+		{StatusFailed, true},
+		{StatusCancelled, true},
+		{StatusInterrupted, true},
+		{StatusSkipped, true},
+		{StatusPartial, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.IsTerminal(); got != tt.terminal {
+			t.Errorf("Expected %s.IsTerminal() = %v, got %v", tt.status, tt.terminal, got)
+		}
+	}
+}
+
+func TestSyncTask_Request(t *testing.T) {
+	task := NewSyncTask("test-id", "src", "dest", "linux/amd64")
+	task.RetryTimes = 5
+	task.SrcTLSVerify = false
+	task.DestTLSVerify = true
+
+	req, complete := task.Request()
+	if !complete {
+		t.Error("Expected request without credentials to be complete")
+	}
+	if req.SourceImage != "src" || req.DestImage != "dest" || req.Architecture != "linux/amd64" {
+		t.Errorf("Expected images and architecture to be restored, got %+v", req)
+	}
+	if *req.RetryTimes != 5 || *req.SrcTLSVerify || !*req.DestTLSVerify {
+		t.Errorf("Expected options to be restored, got retry=%d srcTLS=%v destTLS=%v",
+			*req.RetryTimes, *req.SrcTLSVerify, *req.DestTLSVerify)
+	}
+
+	task.DestAuth = true
+	if _, complete := task.Request(); complete {
+		t.Error("Expected request with destination credentials to be incomplete")
+	}
+}
+
+func TestSyncTask_RequestArchitectures(t *testing.T) {
+	task := NewSyncTask("test-id", "src", "dest", "linux/amd64,linux/arm64")
+	task.Architectures = []string{"linux/amd64", "linux/arm64"}
+
+	req, _ := task.Request()
+	if req.Architecture != "" || len(req.Architectures) != 2 || req.Architectures[1] != "linux/arm64" {
+		t.Errorf("Expected architectures to be restored instead of architecture, got %q and %v", req.Architecture, req.Architectures)
+	}
+
+	req.Architectures[0] = "linux/s390x"
+	if task.Architectures[0] != "linux/amd64" {
+		t.Error("Expected the request to own a copy of the architectures")
+	}
+}
+
+func TestSyncTask_RequestDestinations(t *testing.T) {
 	task := NewSyncTask("test-id", "src", "dest", "all")
+	task.AllowPartial = true
+	task.Destinations = []DestinationResult{
+		{Image: "dest", Status: StatusCompleted},
+		{Image: "dr/dest", TLSVerify: true, Status: StatusFailed, Error: "unauthorized"},
+	}
 
-	ch1 := task.AddLogListener()
-	ch2 := task.AddLogListener()
+	req, complete := task.Request()
+	if !complete {
+		t.Error("Expected request without credentials to be complete")
+	}
+	if len(req.Destinations) != 1 || req.Destinations[0].Image != "dr/dest" || !*req.Destinations[0].TLSVerify {
+		t.Fatalf("Expected the additional destination to be restored, got %+v", req.Destinations)
+	}
+	if !req.AllowPartial {
+		t.Error("Expected allowPartial to be restored")
+	}
 
-	task.CloseAllLogListeners()
+	task.Destinations[1].Auth = true
+	if _, complete := task.Request(); complete {
+		t.Error("Expected request with destination credentials to be incomplete")
+	}
+}
 
-	_, ok1 := <-ch1
-	_, ok2 := <-ch2
+func TestSyncStatus_IsRetryable(t *testing.T) {
+	tests := []struct {
+		status    SyncStatus
+		retryable bool
+	}{
+		{StatusPending, false},
+		{StatusRunning, false},
+		{StatusCompleted, false},
+		{StatusFailed, true},
+		{StatusCancelled, true},
+		{StatusInterrupted, true},
+		{StatusSkipped, false},
+		{StatusPartial, true},
+	}
 
-	if ok1 || ok2 {
-		t.Error("Expected all channels to be closed")
+	for _, tt := range tests {
+		if got := tt.status.IsRetryable(); got != tt.retryable {
+			t.Errorf("Expected %s.IsRetryable() = %v, got %v", tt.status, tt.retryable, got)
+		}
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// Checks holds the interval and the state of the periodic checks of a watch or subscription.
+type Checks struct {
+	Interval    string     `json:"interval"`              // Time between checks, e.g. "30m"
+	NextCheckAt *time.Time `json:"nextCheckAt,omitempty"` // Time of the next check (nil if disabled)
+	LastCheckAt *time.Time `json:"lastCheckAt,omitempty"` // Time of the last check (nil if never checked)
+	LastError   string     `json:"lastError,omitempty"`   // Why the last check failed or had no effect
+	Failures    int        `json:"failures,omitempty"`    // Consecutive failed checks; the interval is backed off while non-zero
+	RateLimited bool       `json:"rateLimited,omitempty"` // The last check was rate limited by a registry
+}
+
+// DigestChange records a change of the digest of a watched source tag.
+type DigestChange struct {
+	Digest     string    `json:"digest"`           // New digest of the source tag
+	DetectedAt time.Time `json:"detectedAt"`       // Time of the check that saw the new digest
+	TaskID     string    `json:"taskId,omitempty"` // Sync task created for the change
+}
+
+// Watch polls the manifest digest of a source tag and syncs it to the destination
+// whenever the digest changes, so that mirrors of mutable tags such as nginx:stable
+// do not go stale. Every sync creates a regular sync task with WatchID set.
+type Watch struct {
+	ID      string `json:"id"`      // Unique watch identifier (UUID)
+	Name    string `json:"name"`    // Display name
+	Enabled bool   `json:"enabled"` // Disabled watches keep their definition and history but are not checked
+	SyncDefinition
+	Owner      string         `json:"owner,omitempty"`      // User ID of the creator (empty if OIDC is disabled)
+	OwnerEmail string         `json:"ownerEmail,omitempty"` // Email of the creator (empty if OIDC is disabled)
+	CreatedAt  time.Time      `json:"createdAt"`            // Creation timestamp
+	UpdatedAt  time.Time      `json:"updatedAt"`            // Last modification timestamp
+	Checks                    // Interval and state of the digest checks; LastError also tells why no sync could be started
+	LastDigest string         `json:"lastDigest,omitempty"` // Digest of the source tag that was last synced
+	LastTaskID string         `json:"lastTaskId,omitempty"` // Task created for the most recent change
+	History    []DigestChange `json:"history,omitempty"`    // Digest changes, most recent first
+}
+
+// GetID returns the ID of the watch.
+func (w *Watch) GetID() string {
+	return w.ID
+}
+
+// IsEnabled reports whether the watch is checked.
+func (w *Watch) IsEnabled() bool {
+	return w.Enabled
+}
+
+// SetEnabled enables or disables the watch.
+func (w *Watch) SetEnabled(enabled bool) {
+	w.Enabled = enabled
+}
+
+// GetOwner returns the user ID of the creator of the watch.
+func (w *Watch) GetOwner() string {
+	return w.Owner
+}
+
+// SetOwner records the user ID and email of the creator of the watch.
+func (w *Watch) SetOwner(userID, email string) {
+	w.Owner = userID
+	w.OwnerEmail = email
+}
+
+// GetUpdatedAt returns the last modification time of the watch.
+func (w *Watch) GetUpdatedAt() time.Time {
+	return w.UpdatedAt
+}
+
+// SetCreated sets the ID and the creation and modification times of a new watch.
+func (w *Watch) SetCreated(id string, at time.Time) {
+	w.ID = id
+	w.CreatedAt = at
+	w.UpdatedAt = at
+}
+
+// SetUpdated sets the last modification time of the watch.
+func (w *Watch) SetUpdated(at time.Time) {
+	w.UpdatedAt = at
+}
+
+// GetChecks returns the checks of the watch.
+func (w *Watch) GetChecks() *Checks {
+	return &w.Checks
+}
+
+// WatchRequest represents the request for creating or replacing a watch.
+type WatchRequest struct {
+	Name     string `json:"name"`     // Display name (optional)
+	Interval string `json:"interval"` // Time between checks (optional, default: 1h)
+	Enabled  *bool  `json:"enabled"`  // Whether the watch is checked (optional, default: true, or unchanged on update)
+	SyncDefinition
+}
+
+// Apply copies the request into a watch. Enabled is left unchanged if not supplied.
+func (r *WatchRequest) Apply(w *Watch) {
+	w.Name = r.Name
+	w.Interval = r.Interval
+	if r.Enabled != nil {
+		w.Enabled = *r.Enabled
+	}
+	w.SyncDefinition = r.SyncDefinition
+	w.Labels = CopyLabels(r.Labels)
+}
+
+// WatchListResponse represents the response of listing watches.
+type WatchListResponse struct {
+	Watches []*Watch `json:"watches"`
+	Total   int      `json:"total"`
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package cron parses standard five-field cron expressions and computes their run times.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field describes one of the five fields of a cron expression.
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int // Symbolic values such as "jan" or "mon" (optional)
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Day of week 7 is accepted as an alias of Sunday (0)
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// macros maps the supported shorthands to their expressions.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxSearchYears bounds the search for the next run time, so that expressions that
+// can never match, such as "0 0 30 2 *", do not loop forever.
+const maxSearchYears = 5
+
+// Expression is a parsed cron expression. Each field is a bit set of the values it allows.
+type Expression struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool // Whether day of month and day of week start with "*"
+	original                      string
+}
+
+// Parse parses a cron expression with the fields minute, hour, day of month, month and
+// day of week. Each field is "*", a value, a range "a-b" or a list of those separated by
+// commas, optionally followed by a step "/n". Months and weekdays may be given by their
+// three-letter English names. The macros @yearly, @monthly, @weekly, @daily and @hourly
+// are supported as well.
+//
+// As in classic cron, if neither day of month nor day of week starts with "*", a day
+// matches if it satisfies either of them.
+func Parse(expr string) (*Expression, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := macros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	e := &Expression{original: expr}
+	var err error
+	if e.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if e.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if e.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if e.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if e.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if e.dow&(1<<7) != 0 {
+		e.dow |= 1
+	}
+	e.domAny = strings.HasPrefix(fields[2], "*")
+	e.dowAny = strings.HasPrefix(fields[4], "*")
+	return e, nil
+}
+
+// parseField parses one field into a bit set of allowed values.
+func parseField(text string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(text, ",") {
+		rangeText, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, part)
+			}
+			rangeText, step = part[:i], n
+		}
+
+		var lo, hi int
+		switch {
+		case rangeText == "*":
+			lo, hi = f.min, f.max
+		case strings.Contains(rangeText, "-"):
+			bounds := strings.SplitN(rangeText, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(bounds[1], f); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, rangeText)
+			}
+		default:
+			v, err := parseValue(rangeText, f)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			// "5/15" means every 15 starting at 5
+			if step > 1 {
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseValue parses a single number or symbolic name of a field.
+func parseValue(text string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", f.name, text)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s %d out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first run time strictly after t, in t's location. Runs fall on whole
+// minutes. Wall-clock times skipped by a daylight saving change do not run; times that
+// occur twice run at their first occurrence.
+// Returns the zero time if the expression never matches within the next few years.
+func (e *Expression) Next(t time.Time) time.Time {
+	loc := t.Location()
+	after := wallClock(t)
+	// Start at the next whole minute
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if !has(e.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !e.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !has(e.hour, t.Hour()) {
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+		// Skip the repeated hour when the clock is set back, if it already ran before
+		if !has(e.minute, t.Minute()) || !wallClock(t).After(after) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// wallClock returns the wall-clock time of t as a UTC time, for comparing times across
+// daylight saving changes.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// matchDay reports whether t's day satisfies the day of month and day of week fields.
+func (e *Expression) matchDay(t time.Time) bool {
+	domMatch := has(e.dom, t.Day())
+	dowMatch := has(e.dow, int(t.Weekday()))
+	if e.domAny || e.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// has reports whether bit v is set.
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// String returns the expression as it was parsed.
+func (e *Expression) String() string {
+	return e.original
+}
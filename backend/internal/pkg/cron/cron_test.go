@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@reboot",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr); err == nil {
+				t.Errorf("Expected error for %q, got nil", expr)
+			}
+		})
+	}
+}
+
+func TestExpression_Next(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+	from := time.Date(2025, 3, 14, 10, 30, 15, 0, shanghai) // Friday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 3, 14, 10, 31, 0, 0, shanghai)},
+		{"0 2 * * *", time.Date(2025, 3, 15, 2, 0, 0, 0, shanghai)},
+		{"@daily", time.Date(2025, 3, 15, 0, 0, 0, 0, shanghai)},
+		{"@hourly", time.Date(2025, 3, 14, 11, 0, 0, 0, shanghai)},
+		{"*/15 * * * *", time.Date(2025, 3, 14, 10, 45, 0, 0, shanghai)},
+		{"5/20 10 * * *", time.Date(2025, 3, 14, 10, 45, 0, 0, shanghai)},
+		{"0 9-17/4 * * *", time.Date(2025, 3, 14, 13, 0, 0, 0, shanghai)},
+		{"0 3 * * mon-wed", time.Date(2025, 3, 17, 3, 0, 0, 0, shanghai)},
+		{"0 0 * * 7", time.Date(2025, 3, 16, 0, 0, 0, 0, shanghai)},
+		{"0 0 1 jan,jul *", time.Date(2025, 7, 1, 0, 0, 0, 0, shanghai)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, shanghai)},
+		// Day of month or day of week when both are restricted, both if one starts with "*"
+		{"0 0 20 * mon", time.Date(2025, 3, 17, 0, 0, 0, 0, shanghai)},
+		{"0 0 */10 * mon", time.Date(2025, 3, 31, 0, 0, 0, 0, shanghai)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if got := expr.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExpression_NextNever(t *testing.T) {
+	expr, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got := expr.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Expected zero time, got %v", got)
+	}
+}
+
+func TestExpression_NextDaylightSaving(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+	daily, err := Parse("30 2 * * *")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// 02:30 does not exist on 2025-03-30, the next run is a day later
+	got := daily.Next(time.Date(2025, 3, 29, 3, 0, 0, 0, berlin))
+	if want := time.Date(2025, 3, 31, 2, 30, 0, 0, berlin); !got.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// 02:30 occurs twice on 2025-10-26, it runs once
+	first := daily.Next(time.Date(2025, 10, 26, 1, 0, 0, 0, berlin))
+	if _, offset := first.Zone(); first.Hour() != 2 || offset != 2*3600 {
+		t.Fatalf("Expected first run at 02:30 CEST, got %v", first)
+	}
+	second := daily.Next(first)
+	if want := time.Date(2025, 10, 27, 2, 30, 0, 0, berlin); !second.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, second)
+	}
+}
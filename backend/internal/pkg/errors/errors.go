@@ -12,10 +12,10 @@ import (
 // AppError represents an application error with HTTP status code and error code.
 // It implements the error interface and supports error wrapping (Go 1.13+).
 type AppError struct {
-	Code       string `json:"code"`       // Error code (e.g., "TASK_NOT_FOUND")
-	Message    string `json:"message"`    // Human-readable error message
-	StatusCode int    `json:"-"`          // HTTP status code (not serialized)
-	Err        error  `json:"-"`          // Wrapped error (not serialized)
+	Code       string `json:"code"`    // Error code (e.g., "TASK_NOT_FOUND")
+	Message    string `json:"message"` // Human-readable error message
+	StatusCode int    `json:"-"`       // HTTP status code (not serialized)
+	Err        error  `json:"-"`       // Wrapped error (not serialized)
 }
 
 // Error returns the error message string.
@@ -58,6 +58,8 @@ var (
 	ErrInvalidInput  = New("INVALID_INPUT", "Invalid input parameters", http.StatusBadRequest)
 	ErrInternal      = New("INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
 	ErrCommandFailed = New("COMMAND_FAILED", "Command execution failed", http.StatusInternalServerError)
+	ErrConflict      = New("CONFLICT", "Request conflicts with current state", http.StatusConflict)
+	ErrUnavailable   = New("SERVICE_UNAVAILABLE", "Service temporarily unavailable", http.StatusServiceUnavailable)
 )
 
 // WrapTaskNotFound wraps an error as a task not found error (404).
@@ -83,4 +85,44 @@ func WrapInternal(err error, message string) *AppError {
 // WrapCommandFailed wraps an error as a command execution failure (500).
 func WrapCommandFailed(err error, message string) *AppError {
 	return Wrap(err, "COMMAND_FAILED", message, http.StatusInternalServerError)
-}
\ No newline at end of file
+}
+
+// WrapConflict wraps an error as a conflict with the current resource state (409).
+func WrapConflict(err error, message string) *AppError {
+	return Wrap(err, "CONFLICT", message, http.StatusConflict)
+}
+
+// WrapUnavailable wraps an error as a temporary unavailability, e.g. during shutdown (503).
+func WrapUnavailable(err error, message string) *AppError {
+	return Wrap(err, "SERVICE_UNAVAILABLE", message, http.StatusServiceUnavailable)
+}
+
+// WrapUnauthorized wraps an error as a request that failed authentication (401).
+func WrapUnauthorized(err error, message string) *AppError {
+	return Wrap(err, "UNAUTHORIZED", message, http.StatusUnauthorized)
+}
+
+// WrapJobNotFound wraps an error as a job not found error (404).
+func WrapJobNotFound(err error) *AppError {
+	return Wrap(err, "JOB_NOT_FOUND", "Job not found", http.StatusNotFound)
+}
+
+// WrapScheduleNotFound wraps an error as a schedule not found error (404).
+func WrapScheduleNotFound(err error) *AppError {
+	return Wrap(err, "SCHEDULE_NOT_FOUND", "Schedule not found", http.StatusNotFound)
+}
+
+// WrapWatchNotFound wraps an error as a watch not found error (404).
+func WrapWatchNotFound(err error) *AppError {
+	return Wrap(err, "WATCH_NOT_FOUND", "Watch not found", http.StatusNotFound)
+}
+
+// WrapSubscriptionNotFound wraps an error as a subscription not found error (404).
+func WrapSubscriptionNotFound(err error) *AppError {
+	return Wrap(err, "SUBSCRIPTION_NOT_FOUND", "Subscription not found", http.StatusNotFound)
+}
+
+// WrapHookNotFound wraps an error as a registry hook not found error (404).
+func WrapHookNotFound(err error) *AppError {
+	return Wrap(err, "HOOK_NOT_FOUND", "Hook not found", http.StatusNotFound)
+}
@@ -69,6 +69,18 @@ func TestPredefinedErrors(t *testing.T) {
 			expectedCode:   "COMMAND_FAILED",
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name:           "ErrConflict",
+			err:            ErrConflict,
+			expectedCode:   "CONFLICT",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "ErrUnavailable",
+			err:            ErrUnavailable,
+			expectedCode:   "SERVICE_UNAVAILABLE",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -99,6 +111,36 @@ func TestWrappers(t *testing.T) {
 			expectedCode:   "TASK_NOT_FOUND",
 			expectedStatus: http.StatusNotFound,
 		},
+		{
+			name:           "WrapJobNotFound",
+			wrapper:        WrapJobNotFound,
+			expectedCode:   "JOB_NOT_FOUND",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "WrapScheduleNotFound",
+			wrapper:        WrapScheduleNotFound,
+			expectedCode:   "SCHEDULE_NOT_FOUND",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "WrapWatchNotFound",
+			wrapper:        WrapWatchNotFound,
+			expectedCode:   "WATCH_NOT_FOUND",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "WrapSubscriptionNotFound",
+			wrapper:        WrapSubscriptionNotFound,
+			expectedCode:   "SUBSCRIPTION_NOT_FOUND",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "WrapHookNotFound",
+			wrapper:        WrapHookNotFound,
+			expectedCode:   "HOOK_NOT_FOUND",
+			expectedStatus: http.StatusNotFound,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -176,3 +218,60 @@ func TestWrapCommandFailed(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, err.StatusCode)
 	}
 }
+
+func TestWrapConflict(t *testing.T) {
+	originalErr := errors.New("test error")
+	message := "Custom error message"
+
+	err := WrapConflict(originalErr, message)
+
+	if err.Code != "CONFLICT" {
+		t.Errorf("Expected code CONFLICT, got %s", err.Code)
+	}
+
+	if err.Message != message {
+		t.Errorf("Expected message %s, got %s", message, err.Message)
+	}
+
+	if err.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d", http.StatusConflict, err.StatusCode)
+	}
+}
+
+func TestWrapUnavailable(t *testing.T) {
+	originalErr := errors.New("test error")
+	message := "Custom error message"
+
+	err := WrapUnavailable(originalErr, message)
+
+	if err.Code != "SERVICE_UNAVAILABLE" {
+		t.Errorf("Expected code SERVICE_UNAVAILABLE, got %s", err.Code)
+	}
+
+	if err.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, err.StatusCode)
+	}
+
+	if !errors.Is(err, originalErr) {
+		t.Error("Expected wrapped error to match original error")
+	}
+}
+
+func TestWrapUnauthorized(t *testing.T) {
+	originalErr := errors.New("test error")
+	message := "Custom error message"
+
+	err := WrapUnauthorized(originalErr, message)
+
+	if err.Code != "UNAUTHORIZED" {
+		t.Errorf("Expected code UNAUTHORIZED, got %s", err.Code)
+	}
+
+	if err.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, err.StatusCode)
+	}
+
+	if !errors.Is(err, originalErr) {
+		t.Error("Expected wrapped error to match original error")
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// comparatorOps lists the supported operators, longest first so that prefixes match correctly.
+var comparatorOps = []string{">=", "<=", "!=", ">", "<", "=", "~", "^"}
+
+// comparator is a single comparison against a version, e.g. ">=1.24.0".
+type comparator struct {
+	op      string // One of >=, <=, !=, >, <, =
+	version *Version
+}
+
+// match reports whether v satisfies the comparison.
+func (c comparator) match(v *Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// Constraint is a set of version requirements, e.g. ">=1.24 <2" or "^1.2 || ~2.0".
+type Constraint struct {
+	alternatives [][]comparator // A version matches if it satisfies all comparators of any alternative
+	original     string
+}
+
+// ParseConstraint parses a version constraint. Comparators separated by spaces or commas
+// must all hold, and "||" separates alternatives. Supported operators are =, !=, >, >=,
+// <, <=, ~ (patch updates: ~1.2 is >=1.2.0 <1.3.0) and ^ (compatible updates: ^1.2 is
+// >=1.2.0 <2.0.0). Missing components are zero, except that a version without operator
+// or with = matches all versions it is a prefix of: "1.24" is >=1.24.0 <1.25.0.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{original: s}
+	for _, alternative := range strings.Split(s, "||") {
+		tokens := strings.FieldsFunc(alternative, func(r rune) bool {
+			return r == ' ' || r == ',' || r == '\t'
+		})
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("invalid constraint %q: empty alternative", s)
+		}
+
+		var comparators []comparator
+		for i := 0; i < len(tokens); i++ {
+			token := tokens[i]
+			// Allow a space between operator and version, e.g. ">= 1.24"
+			if isOperator(token) && i+1 < len(tokens) {
+				i++
+				token += tokens[i]
+			}
+			parsed, err := parseComparator(token)
+			if err != nil {
+				return nil, fmt.Errorf("invalid constraint %q: %v", s, err)
+			}
+			comparators = append(comparators, parsed...)
+		}
+		c.alternatives = append(c.alternatives, comparators)
+	}
+	return c, nil
+}
+
+// isOperator reports whether token consists of an operator only.
+func isOperator(token string) bool {
+	for _, op := range comparatorOps {
+		if token == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComparator parses one operator and version into the comparisons it stands for.
+func parseComparator(token string) ([]comparator, error) {
+	op := ""
+	for _, candidate := range comparatorOps {
+		if strings.HasPrefix(token, candidate) {
+			op = candidate
+			break
+		}
+	}
+	versionText := token[len(op):]
+	v, err := Parse(versionText)
+	if err != nil {
+		return nil, err
+	}
+	// Number of components given, which determines the ranges of =, ~ and ^
+	release := strings.TrimPrefix(versionText, "v")
+	if i := strings.IndexAny(release, "-+"); i >= 0 {
+		release = release[:i]
+	}
+	components := strings.Count(release, ".") + 1
+
+	switch op {
+	case "", "=":
+		if components == 3 {
+			return []comparator{{"=", v}}, nil
+		}
+		return rangeOf(v, bump(v, components-1)), nil
+	case "~":
+		if components == 1 {
+			return rangeOf(v, bump(v, 0)), nil
+		}
+		return rangeOf(v, bump(v, 1)), nil
+	case "^":
+		switch {
+		case v.Major > 0 || components == 1:
+			return rangeOf(v, bump(v, 0)), nil
+		case v.Minor > 0 || components == 2:
+			return rangeOf(v, bump(v, 1)), nil
+		default:
+			return rangeOf(v, bump(v, 2)), nil
+		}
+	default:
+		return []comparator{{op, v}}, nil
+	}
+}
+
+// rangeOf returns the comparisons of the half-open range [lower, upper).
+func rangeOf(lower, upper *Version) []comparator {
+	return []comparator{{">=", lower}, {"<", upper}}
+}
+
+// bump returns the upper bound of the versions sharing the first i+1 components of v
+// (i = 0 for major, 1 for minor, 2 for patch): the lowest prerelease "-0" of the next
+// version, so that prereleases of the next version are outside of the range as well.
+func bump(v *Version, i int) *Version {
+	next := &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch i {
+	case 0:
+		next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+	case 1:
+		next.Minor, next.Patch = v.Minor+1, 0
+	default:
+		next.Patch = v.Patch + 1
+	}
+	next.Prerelease = "0"
+	return next
+}
+
+// Match reports whether v satisfies the constraint.
+func (c *Constraint) Match(v *Version) bool {
+	for _, comparators := range c.alternatives {
+		matched := true
+		for _, comp := range comparators {
+			if !comp.match(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the constraint as it was parsed.
+func (c *Constraint) String() string {
+	return c.original
+}
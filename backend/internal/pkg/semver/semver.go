@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package semver parses image tags as semantic versions and matches them against
+// version constraints such as ">=1.24 <2".
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version parsed from an image tag.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string // Dot-separated identifiers after "-" (empty for releases)
+	Original   string // Tag the version was parsed from
+}
+
+// Parse parses a tag as a semantic version. It is lenient in the ways image tags
+// commonly differ from SemVer: a leading "v" is allowed and minor and patch may be
+// omitted ("1.24" is 1.24.0). Build metadata after "+" is ignored.
+func Parse(tag string) (*Version, error) {
+	s := strings.TrimPrefix(tag, "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	prerelease := ""
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		s, prerelease = s[:i], s[i+1:]
+		if prerelease == "" {
+			return nil, fmt.Errorf("invalid version %q: empty prerelease", tag)
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid version %q: too many components", tag)
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := parseNumber(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %v", tag, err)
+		}
+		numbers[i] = n
+	}
+
+	return &Version{
+		Major:      numbers[0],
+		Minor:      numbers[1],
+		Patch:      numbers[2],
+		Prerelease: prerelease,
+		Original:   tag,
+	}, nil
+}
+
+// parseNumber parses a non-negative version component.
+func parseNumber(s string) (int, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty component")
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("component %q is not a number", s)
+		}
+	}
+	return strconv.Atoi(s)
+}
+
+// String returns the version in major.minor.patch[-prerelease] form.
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or higher than o.
+// Following SemVer precedence, a prerelease is lower than the release it precedes.
+func (v *Version) Compare(o *Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// comparePrerelease compares prerelease strings by SemVer rules: no prerelease ranks
+// highest, numeric identifiers compare numerically and rank below alphanumeric ones.
+func comparePrerelease(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return 1
+	}
+	if b == "" {
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+// sign returns -1, 0 or 1 according to the sign of n.
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
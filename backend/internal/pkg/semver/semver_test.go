@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{"1.25.3", "1.25.3", false},
+		{"v1.25.3", "1.25.3", false},
+		{"1.25", "1.25.0", false},
+		{"2", "2.0.0", false},
+		{"1.26.0-rc.1", "1.26.0-rc.1", false},
+		{"1.25.3+build.7", "1.25.3", false},
+		{"1.25-alpine", "1.25.0-alpine", false},
+		{"latest", "", true},
+		{"1.2.3.4", "", true},
+		{"1..2", "", true},
+		{"1.2-", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			v, err := Parse(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && v.String() != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, v.String())
+			}
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.2", "1.10.0", "2.0.0"}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, _ := Parse(ordered[i])
+		b, _ := Parse(ordered[i+1])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("Expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+	a, _ := Parse("v1.2")
+	b, _ := Parse("1.2.0")
+	if a.Compare(b) != 0 {
+		t.Error("Expected v1.2 to equal 1.2.0")
+	}
+}
+
+func TestConstraint_Match(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		{">=1.24 <2", []string{"1.24.0", "1.25.3", "1.99"}, []string{"1.23.9", "2.0.0"}},
+		{">= 1.24, < 2", []string{"1.24.1"}, []string{"2.1.0"}},
+		{"1.24", []string{"1.24.0", "1.24.7"}, []string{"1.25.0", "1.25.0-rc.1", "1.23.0"}},
+		{"=1.24.2", []string{"1.24.2"}, []string{"1.24.3"}},
+		{"!=1.24.2", []string{"1.24.3"}, []string{"1.24.2"}},
+		{"~1.24.2", []string{"1.24.2", "1.24.9"}, []string{"1.25.0", "1.24.1"}},
+		{"^1.2", []string{"1.2.0", "1.9.0"}, []string{"2.0.0", "1.1.0"}},
+		{"^0.2.3", []string{"0.2.5"}, []string{"0.3.0"}},
+		{"<1.20 || >=1.26", []string{"1.19.3", "1.26.1"}, []string{"1.22.0"}},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q) failed: %v", tt.constraint, err)
+		}
+		for _, tag := range tt.matches {
+			v, _ := Parse(tag)
+			if !c.Match(v) {
+				t.Errorf("Expected %q to match %s", tt.constraint, tag)
+			}
+		}
+		for _, tag := range tt.rejects {
+			v, _ := Parse(tag)
+			if c.Match(v) {
+				t.Errorf("Expected %q to reject %s", tt.constraint, tag)
+			}
+		}
+	}
+
+	for _, invalid := range []string{"", ">=", ">=abc", "1.2 ||", "=>1.2"} {
+		if _, err := ParseConstraint(invalid); err == nil {
+			t.Errorf("Expected error for constraint %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package timeutil provides time parsing helpers for command-line flags and query parameters.
+package timeutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Day and Week are the lengths of the "d" and "w" units accepted by ParseDuration.
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// durationPattern splits a duration into optional week and day parts and the rest,
+// which is parsed by time.ParseDuration.
+var durationPattern = regexp.MustCompile(`^(?:(\d+)w)?(?:(\d+)d)?(.*)$`)
+
+// ParseDuration parses a non-negative duration such as "7d", "2w", "1d12h" or "90m".
+// In addition to the units of time.ParseDuration it accepts "w" (weeks) and "d" (days),
+// which must come first and in that order. An empty string is an error.
+func ParseDuration(s string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(s)
+	if s == "" || m == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	var d time.Duration
+	if m[1] != "" {
+		weeks, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += time.Duration(weeks) * Week
+	}
+	if m[2] != "" {
+		days, err := strconv.Atoi(m[2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += time.Duration(days) * Day
+	}
+	if m[3] != "" {
+		rest, err := time.ParseDuration(m[3])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		if rest < 0 {
+			return 0, fmt.Errorf("duration %q must not be negative", s)
+		}
+		d += rest
+	}
+	return d, nil
+}
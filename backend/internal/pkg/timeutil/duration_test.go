@@ -0,0 +1,43 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"days", "7d", 7 * Day, false},
+		{"weeks", "2w", 2 * Week, false},
+		{"weeks and days", "1w2d", 9 * Day, false},
+		{"days and hours", "1d12h", 36 * time.Hour, false},
+		{"go duration", "90m", 90 * time.Minute, false},
+		{"zero", "0", 0, false},
+
+		{"empty", "", 0, true},
+		{"missing unit", "7", 0, true},
+		{"unknown unit", "7y", 0, true},
+		{"days before weeks", "1d1w", 0, true},
+		{"negative", "-1h", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDuration(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDuration(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
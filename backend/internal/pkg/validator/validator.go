@@ -13,11 +13,25 @@ import (
 
 const (
 	// Maximum input lengths to prevent DoS
-	MaxImageNameLength    = 512
-	MaxUsernameLength     = 256
-	MaxPasswordLength     = 512
-	MaxArchitectureLength = 64
-	MaxConfigNameLength   = 64
+	MaxImageNameLength        = 512
+	MaxUsernameLength         = 256
+	MaxPasswordLength         = 512
+	MaxArchitectureLength     = 64
+	MaxConfigNameLength       = 64
+	MaxLabels                 = 32
+	MaxLabelKeyLength         = 63
+	MaxLabelValueLength       = 256
+	MaxNoteLength             = 2048
+	MaxIdempotencyKeyLength   = 255
+	MaxJobNameLength          = 128
+	MaxJobItems               = 500
+	MaxDestinations           = 16
+	MaxScheduleNameLength     = 128
+	MaxWatchNameLength        = 128
+	MaxSubscriptionNameLength = 128
+	MaxHookNameLength         = 128
+	MinHookSecretLength       = 16
+	MaxHookSecretLength       = 256
 )
 
 // Image name validation regex patterns
@@ -36,6 +50,10 @@ var (
 	// Valid config name format: alphanumeric, dash, underscore, dot
 	// Examples: default, prod-env, my.config, dev_1
 	configNameRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+	// Valid label key format: alphanumeric start and end, with dash, underscore, dot and slash inside
+	// Examples: ticket, release, team/owner, app.kubernetes.io_name
+	labelKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._/-]*[a-zA-Z0-9])?$`)
 )
 
 // ValidationError represents an input validation error.
@@ -87,6 +105,23 @@ func ValidateImageName(image string) error {
 	return nil
 }
 
+// ValidateRepositoryName validates a repository name, i.e. an image name without tag or digest.
+func ValidateRepositoryName(repository string) error {
+	if err := ValidateImageName(repository); err != nil {
+		return err
+	}
+
+	// A colon after the last slash separates a tag (a colon before it is a registry port)
+	if strings.Contains(repository, "@") || strings.LastIndex(repository, ":") > strings.LastIndex(repository, "/") {
+		return &ValidationError{
+			Field:   "repository",
+			Message: "repository name cannot contain a tag or digest",
+		}
+	}
+
+	return nil
+}
+
 // ValidateArchitecture validates an architecture string.
 // Accepts "all" or format like "linux/amd64" or "linux/arm/v7".
 func ValidateArchitecture(arch string) error {
@@ -241,3 +276,187 @@ func ValidateRetryTimes(retryTimes *int) error {
 
 	return nil
 }
+
+// ValidateLabels validates the labels of a sync task.
+// Keys must be label keys as described by labelKeyRegex; values are free-form text
+// without control characters. Both are limited in length, and so is the number of labels.
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return &ValidationError{
+			Field:   "labels",
+			Message: fmt.Sprintf("at most %d labels are allowed", MaxLabels),
+		}
+	}
+
+	for key, value := range labels {
+		if len(key) > MaxLabelKeyLength {
+			return &ValidationError{
+				Field:   "labels",
+				Message: fmt.Sprintf("label key exceeds maximum length of %d characters", MaxLabelKeyLength),
+			}
+		}
+		if !labelKeyRegex.MatchString(key) {
+			return &ValidationError{
+				Field:   "labels",
+				Message: fmt.Sprintf("invalid label key %q: use letters, numbers, dash, underscore, dot and slash", key),
+			}
+		}
+		if len(value) > MaxLabelValueLength {
+			return &ValidationError{
+				Field:   "labels",
+				Message: fmt.Sprintf("value of label %q exceeds maximum length of %d characters", key, MaxLabelValueLength),
+			}
+		}
+		if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+			return &ValidationError{
+				Field:   "labels",
+				Message: fmt.Sprintf("value of label %q contains control characters", key),
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateNote validates the note of a sync task.
+// Line breaks and tabs are allowed; other control characters are not.
+func ValidateNote(note string) error {
+	if len(note) > MaxNoteLength {
+		return &ValidationError{
+			Field:   "note",
+			Message: fmt.Sprintf("note exceeds maximum length of %d characters", MaxNoteLength),
+		}
+	}
+
+	for _, r := range note {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return &ValidationError{
+				Field:   "note",
+				Message: "note contains control characters",
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateIdempotencyKey validates the Idempotency-Key header of a request.
+// Keys are usually UUIDs or other opaque tokens; any printable ASCII is accepted.
+func ValidateIdempotencyKey(key string) error {
+	if len(key) > MaxIdempotencyKeyLength {
+		return &ValidationError{
+			Field:   "Idempotency-Key",
+			Message: fmt.Sprintf("idempotency key exceeds maximum length of %d characters", MaxIdempotencyKeyLength),
+		}
+	}
+
+	for _, r := range key {
+		if r < 0x20 || r > 0x7e {
+			return &ValidationError{
+				Field:   "Idempotency-Key",
+				Message: "idempotency key can only contain printable ASCII characters",
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateJobName validates the display name of a batch job.
+func ValidateJobName(name string) error {
+	return validateDisplayName("job", name, MaxJobNameLength)
+}
+
+// ValidateScheduleName validates the display name of a schedule.
+func ValidateScheduleName(name string) error {
+	return validateDisplayName("schedule", name, MaxScheduleNameLength)
+}
+
+// ValidateWatchName validates the display name of a watch.
+func ValidateWatchName(name string) error {
+	return validateDisplayName("watch", name, MaxWatchNameLength)
+}
+
+// ValidateSubscriptionName validates the display name of a tag subscription.
+func ValidateSubscriptionName(name string) error {
+	return validateDisplayName("subscription", name, MaxSubscriptionNameLength)
+}
+
+// ValidateHookName validates the display name of a registry hook.
+func ValidateHookName(name string) error {
+	return validateDisplayName("hook", name, MaxHookNameLength)
+}
+
+// ValidateHookSecret validates the shared secret of a registry hook, which registries
+// send in a header and use as HMAC key, so it must be printable ASCII.
+func ValidateHookSecret(secret string) error {
+	if len(secret) < MinHookSecretLength || len(secret) > MaxHookSecretLength {
+		return &ValidationError{
+			Field:   "secret",
+			Message: fmt.Sprintf("secret must be between %d and %d characters", MinHookSecretLength, MaxHookSecretLength),
+		}
+	}
+
+	for _, r := range secret {
+		if r <= ' ' || r > '~' {
+			return &ValidationError{
+				Field:   "secret",
+				Message: "secret must only contain printable ASCII characters without spaces",
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateDisplayName validates the display name of a kind of object, such as a job.
+func validateDisplayName(kind, name string, maxLength int) error {
+	if len(name) > maxLength {
+		return &ValidationError{
+			Field:   "name",
+			Message: fmt.Sprintf("%s name exceeds maximum length of %d characters", kind, maxLength),
+		}
+	}
+
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return &ValidationError{
+				Field:   "name",
+				Message: fmt.Sprintf("%s name contains control characters", kind),
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateJobItems validates the number of items of a batch job.
+func ValidateJobItems(count int) error {
+	if count == 0 {
+		return &ValidationError{
+			Field:   "items",
+			Message: "job must contain at least one item",
+		}
+	}
+
+	if count > MaxJobItems {
+		return &ValidationError{
+			Field:   "items",
+			Message: fmt.Sprintf("job exceeds maximum of %d items", MaxJobItems),
+		}
+	}
+
+	return nil
+}
+
+// ValidateDestinations validates the number of additional destinations of a sync request.
+func ValidateDestinations(count int) error {
+	if count > MaxDestinations {
+		return &ValidationError{
+			Field:   "destinations",
+			Message: fmt.Sprintf("request exceeds maximum of %d destinations", MaxDestinations),
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@
 package validator
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -58,6 +59,30 @@ func TestValidateImageName(t *testing.T) {
 	}
 }
 
+func TestValidateRepositoryName(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		wantErr    bool
+	}{
+		{"short name", "nginx", false},
+		{"full name", "docker.io/library/nginx", false},
+		{"registry with port", "registry.example.com:5000/team/app", false},
+		{"with tag", "docker.io/library/nginx:latest", true},
+		{"with digest", "nginx@sha256:" + strings.Repeat("a", 64), true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRepositoryName(tt.repository)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRepositoryName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateArchitecture(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -191,6 +216,219 @@ func TestValidateCredentials(t *testing.T) {
 	}
 }
 
+func TestValidateLabels(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := 0; i <= MaxLabels; i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = "value"
+	}
+
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		// Valid cases
+		{"nil", nil, false},
+		{"simple", map[string]string{"ticket": "OPS-123", "release": "2025.03"}, false},
+		{"key with separators", map[string]string{"team/owner": "platform", "app.name_v2": ""}, false},
+		{"value with spaces", map[string]string{"reason": "hotfix for CVE-2025-1234"}, false},
+
+		// Invalid cases
+		{"empty key", map[string]string{"": "value"}, true},
+		{"key with equals", map[string]string{"a=b": "value"}, true},
+		{"key with comma", map[string]string{"a,b": "value"}, true},
+		{"key ending with dash", map[string]string{"ticket-": "value"}, true},
+		{"key too long", map[string]string{strings.Repeat("a", MaxLabelKeyLength+1): "value"}, true},
+		{"value too long", map[string]string{"key": strings.Repeat("a", MaxLabelValueLength+1)}, true},
+		{"value with newline", map[string]string{"key": "a\nb"}, true},
+		{"too many labels", tooMany, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLabels(tt.labels)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNote(t *testing.T) {
+	tests := []struct {
+		name    string
+		note    string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"multi-line", "Synced for release 2025.03\n\tSee OPS-123", false},
+		{"unicode", "发布前同步", false},
+		{"too long", strings.Repeat("a", MaxNoteLength+1), true},
+		{"control character", "bell\a", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNote(tt.note)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"uuid", "3f1c2a9e-7b4d-4c1e-9a55-0d2f6b8e4c21", false},
+		{"ci job", "pipeline-1234/job:sync nginx", false},
+		{"too long", strings.Repeat("a", MaxIdempotencyKeyLength+1), true},
+		{"newline", "key\nvalue", true},
+		{"non-ascii", "schlüssel", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIdempotencyKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateIdempotencyKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateJobName(t *testing.T) {
+	tests := []struct {
+		name    string
+		jobName string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"valid", "Release 2025.03 mirrors", false},
+		{"too long", strings.Repeat("a", MaxJobNameLength+1), true},
+		{"newline", "two\nlines", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJobName(tt.jobName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateJobName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateScheduleName(t *testing.T) {
+	if err := ValidateScheduleName("Nightly redis mirror"); err != nil {
+		t.Errorf("Expected valid schedule name, got %v", err)
+	}
+	err := ValidateScheduleName(strings.Repeat("a", MaxScheduleNameLength+1))
+	if err == nil || !strings.Contains(err.Error(), "schedule name") {
+		t.Errorf("Expected schedule name length error, got %v", err)
+	}
+}
+
+func TestValidateWatchName(t *testing.T) {
+	if err := ValidateWatchName("nginx stable"); err != nil {
+		t.Errorf("Expected valid watch name, got %v", err)
+	}
+	err := ValidateWatchName(strings.Repeat("a", MaxWatchNameLength+1))
+	if err == nil || !strings.Contains(err.Error(), "watch name") {
+		t.Errorf("Expected watch name length error, got %v", err)
+	}
+}
+
+func TestValidateSubscriptionName(t *testing.T) {
+	if err := ValidateSubscriptionName("nginx releases"); err != nil {
+		t.Errorf("Expected valid subscription name, got %v", err)
+	}
+	err := ValidateSubscriptionName(strings.Repeat("a", MaxSubscriptionNameLength+1))
+	if err == nil || !strings.Contains(err.Error(), "subscription name") {
+		t.Errorf("Expected subscription name length error, got %v", err)
+	}
+}
+
+func TestValidateHookName(t *testing.T) {
+	if err := ValidateHookName("harbor pushes"); err != nil {
+		t.Errorf("Expected valid hook name, got %v", err)
+	}
+	err := ValidateHookName(strings.Repeat("a", MaxHookNameLength+1))
+	if err == nil || !strings.Contains(err.Error(), "hook name") {
+		t.Errorf("Expected hook name length error, got %v", err)
+	}
+}
+
+func TestValidateHookSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{"valid", "s3cr3t-0123456789", false},
+		{"too short", "short", true},
+		{"too long", strings.Repeat("a", MaxHookSecretLength+1), true},
+		{"space", "s3cr3t 0123456789", true},
+		{"non-ASCII", "s3cr3t-0123456789é", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHookSecret(tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHookSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateJobItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		wantErr bool
+	}{
+		{"empty", 0, true},
+		{"single", 1, false},
+		{"maximum", MaxJobItems, false},
+		{"too many", MaxJobItems + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJobItems(tt.count)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateJobItems() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDestinations(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		wantErr bool
+	}{
+		{"none", 0, false},
+		{"maximum", MaxDestinations, false},
+		{"too many", MaxDestinations + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDestinations(tt.count)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDestinations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidationError(t *testing.T) {
 	err := &ValidationError{
 		Field:   "testField",
@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// fileRecord describes how a fileStore identifies, orders, filters and copies its records.
+type fileRecord[T any] struct {
+	id        func(*T) string
+	createdAt func(*T) time.Time
+	owned     func(r *T, owner string) bool // Whether the record belongs to an owner (user ID or email)
+	clone     func(*T) *T                   // Deep copy, so that callers never share state with the store
+}
+
+// fileStore keeps records in memory and writes all of them to a JSON file after every change.
+// It backs the repositories of definitions such as schedules and watches, which are few
+// and are kept next to the saved configs.
+type fileStore[T any] struct {
+	path     string
+	kind     string // Plural record name for error messages, e.g. "schedules"
+	record   fileRecord[T]
+	notFound error
+	records  map[string]*T
+	mu       sync.RWMutex
+}
+
+// newFileStore loads the records stored in path, which is created on the first change
+// if it does not exist. notFound is returned for unknown IDs.
+func newFileStore[T any](path, kind string, record fileRecord[T], notFound error) (*fileStore[T], error) {
+	s := &fileStore[T]{
+		path:     path,
+		kind:     kind,
+		record:   record,
+		notFound: notFound,
+		records:  make(map[string]*T),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", kind, err)
+	}
+	var records []*T
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse %s file %s: %w", kind, path, err)
+	}
+	for _, r := range records {
+		s.records[record.id(r)] = r
+	}
+	return s, nil
+}
+
+// Save creates or replaces a record and writes all records to the file.
+func (s *fileStore[T]) Save(r *T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.record.id(r)
+	previous, existed := s.records[id]
+	s.records[id] = s.record.clone(r)
+	if err := s.writeLocked(); err != nil {
+		if existed {
+			s.records[id] = previous
+		} else {
+			delete(s.records, id)
+		}
+		return err
+	}
+	return nil
+}
+
+// Get retrieves a copy of a record by ID.
+func (s *fileStore[T]) Get(id string) (*T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, exists := s.records[id]
+	if !exists {
+		return nil, s.notFound
+	}
+	return s.record.clone(r), nil
+}
+
+// Delete removes a record and writes the remaining records to the file.
+func (s *fileStore[T]) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, exists := s.records[id]
+	if !exists {
+		return s.notFound
+	}
+	delete(s.records, id)
+	if err := s.writeLocked(); err != nil {
+		s.records[id] = r
+		return err
+	}
+	return nil
+}
+
+// List returns copies of the records of an owner (all if empty), oldest first.
+func (s *fileStore[T]) List(owner string) ([]*T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedLocked(owner), nil
+}
+
+// sortedLocked returns copies of the records of an owner, oldest first.
+// Must be called with s.mu held.
+func (s *fileStore[T]) sortedLocked(owner string) []*T {
+	records := make([]*T, 0, len(s.records))
+	for _, r := range s.records {
+		if owner == "" || s.record.owned(r, owner) {
+			records = append(records, s.record.clone(r))
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		ci, cj := s.record.createdAt(records[i]), s.record.createdAt(records[j])
+		if !ci.Equal(cj) {
+			return ci.Before(cj)
+		}
+		return s.record.id(records[i]) < s.record.id(records[j])
+	})
+	return records
+}
+
+// writeLocked writes all records to a temporary file and renames it over the
+// file, so that a crash never leaves a partially written file.
+// Must be called with s.mu held for writing.
+func (s *fileStore[T]) writeLocked() error {
+	data, err := json.MarshalIndent(s.sortedLocked(""), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", s.kind, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", s.kind, err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.kind, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", s.kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+var (
+	// ErrHookNotFound is returned when a requested registry hook does not exist.
+	ErrHookNotFound = errors.New("hook not found")
+)
+
+// HookRepository defines the interface for registry hook persistence operations.
+// Hooks are returned as copies, so callers may modify them and Save them back.
+type HookRepository interface {
+	// Save creates the hook or replaces the one with the same ID.
+	Save(hook *models.Hook) error
+	Get(id string) (*models.Hook, error)
+	Delete(id string) error
+	// List returns the hooks of an owner (user ID or email; all if empty), oldest first.
+	List(owner string) ([]*models.Hook, error)
+}
+
+// FileHookRepository implements HookRepository with a JSON file, next to the schedules.
+// The file holds the shared secrets of the hooks and is only readable by the server.
+type FileHookRepository struct {
+	*fileStore[models.Hook]
+}
+
+// NewFileHookRepository loads the hooks stored in path, which is created on the
+// first change if it does not exist.
+func NewFileHookRepository(path string) (*FileHookRepository, error) {
+	store, err := newFileStore(path, "hooks", fileRecord[models.Hook]{
+		id:        func(h *models.Hook) string { return h.ID },
+		createdAt: func(h *models.Hook) time.Time { return h.CreatedAt },
+		owned: func(h *models.Hook, owner string) bool {
+			return h.Owner == owner || h.OwnerEmail == owner
+		},
+		clone: func(h *models.Hook) *models.Hook {
+			copied := *h
+			copied.Labels = models.CopyLabels(h.Labels)
+			copied.Rules = append([]models.HookRule(nil), h.Rules...)
+			copied.Events = append([]models.HookEvent(nil), h.Events...)
+			return &copied
+		},
+	}, ErrHookNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &FileHookRepository{store}, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+func TestFileHookRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hooks.json")
+	repo, err := NewFileHookRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileHookRepository failed: %v", err)
+	}
+
+	hook := &models.Hook{ID: "h1", Owner: "u1", Secret: "s3cr3t-0123456789"}
+	hook.Rules = []models.HookRule{{Destination: "registry.example.com/{repository}:{tag}"}}
+	if err := repo.Save(hook); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// The rules of returned hooks are a copy
+	stored, _ := repo.Get("h1")
+	stored.Rules[0].Destination = "changed"
+	if stored, _ := repo.Get("h1"); stored.Rules[0].Destination == "changed" {
+		t.Error("Expected stored rules to be unchanged")
+	}
+
+	// The file holds the secrets, so only the server may read it
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected file mode 0600, got %o", perm)
+	}
+
+	reopened, err := NewFileHookRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileHookRepository failed: %v", err)
+	}
+	if hooks, _ := reopened.List("u1"); len(hooks) != 1 || hooks[0].Secret != hook.Secret {
+		t.Errorf("Expected 1 reloaded hook with its secret, got %d", len(hooks))
+	}
+	if _, err := reopened.Get("h2"); !errors.Is(err, ErrHookNotFound) {
+		t.Errorf("Expected ErrHookNotFound, got %v", err)
+	}
+}
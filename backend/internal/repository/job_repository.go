@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+var (
+	// ErrJobNotFound is returned when a requested job does not exist.
+	ErrJobNotFound = errors.New("job not found")
+)
+
+// JobRepository defines the interface for batch job persistence operations.
+// Jobs are immutable once created; their tasks are stored in the TaskRepository.
+type JobRepository interface {
+	Create(job *models.SyncJob) error
+	Get(id string) (*models.SyncJob, error)
+	Delete(id string) error
+	// Query returns one page of jobs of an owner (user ID or email; any owner if empty),
+	// newest first, plus the total number of matches. A limit of 0 means no limit.
+	Query(owner string, offset, limit int) ([]*models.SyncJob, int, error)
+}
+
+// InMemoryJobRepository implements JobRepository using in-memory storage.
+// Note: All data is lost when the process restarts.
+type InMemoryJobRepository struct {
+	jobs map[string]*models.SyncJob
+	mu   sync.RWMutex
+}
+
+// NewInMemoryJobRepository creates a new in-memory job repository.
+func NewInMemoryJobRepository() *InMemoryJobRepository {
+	return &InMemoryJobRepository{
+		jobs: make(map[string]*models.SyncJob),
+	}
+}
+
+// Create adds a new job to the repository.
+func (r *InMemoryJobRepository) Create(job *models.SyncJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs[job.ID] = job
+	return nil
+}
+
+// Get retrieves a job by ID.
+func (r *InMemoryJobRepository) Get(id string) (*models.SyncJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// Delete removes a job from the repository.
+func (r *InMemoryJobRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.jobs[id]; !exists {
+		return ErrJobNotFound
+	}
+	delete(r.jobs, id)
+	return nil
+}
+
+// Query returns one page of jobs of an owner, newest first, plus the total number of matches.
+func (r *InMemoryJobRepository) Query(owner string, offset, limit int) ([]*models.SyncJob, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*models.SyncJob, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		if owner == "" || job.Owner == owner || job.OwnerEmail == owner {
+			matched = append(matched, job)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+	total := len(matched)
+
+	if offset >= total {
+		return []*models.SyncJob{}, total, nil
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
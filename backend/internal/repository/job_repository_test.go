@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+func TestJobRepositories(t *testing.T) {
+	sqliteRepo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, repo := range map[string]JobRepository{"sqlite": sqliteRepo.Jobs(), "memory": NewInMemoryJobRepository()} {
+		for i := 0; i < 3; i++ {
+			job := &models.SyncJob{
+				ID:        fmt.Sprintf("job%d", i),
+				Name:      "mirrors",
+				Owner:     fmt.Sprintf("u%d", i%2),
+				TaskIDs:   []string{"t1", "t2"},
+				CreatedAt: base.Add(time.Duration(i) * time.Hour),
+			}
+			if err := repo.Create(job); err != nil {
+				t.Fatalf("%s: Create failed: %v", name, err)
+			}
+		}
+
+		job, err := repo.Get("job1")
+		if err != nil {
+			t.Fatalf("%s: Get failed: %v", name, err)
+		}
+		if job.Name != "mirrors" || len(job.TaskIDs) != 2 || !job.CreatedAt.Equal(base.Add(time.Hour)) {
+			t.Errorf("%s: unexpected job %+v", name, job)
+		}
+
+		jobs, total, _ := repo.Query("", 0, 2)
+		if total != 3 || len(jobs) != 2 || jobs[0].ID != "job2" {
+			t.Errorf("%s: expected newest job first of 3, got total=%d len=%d", name, total, len(jobs))
+		}
+		if jobs, total, _ := repo.Query("u0", 1, 0); total != 2 || len(jobs) != 1 || jobs[0].ID != "job0" {
+			t.Errorf("%s: expected second job of u0 to be job0, got total=%d", name, total)
+		}
+
+		if err := repo.Delete("job1"); err != nil {
+			t.Fatalf("%s: Delete failed: %v", name, err)
+		}
+		if _, err := repo.Get("job1"); err != ErrJobNotFound {
+			t.Errorf("%s: expected ErrJobNotFound after delete, got %v", name, err)
+		}
+		if err := repo.Delete("job1"); err != ErrJobNotFound {
+			t.Errorf("%s: expected ErrJobNotFound deleting twice, got %v", name, err)
+		}
+	}
+}
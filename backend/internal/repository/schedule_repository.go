@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+var (
+	// ErrScheduleNotFound is returned when a requested schedule does not exist.
+	ErrScheduleNotFound = errors.New("schedule not found")
+)
+
+// ScheduleRepository defines the interface for schedule persistence operations.
+// Schedules are returned as copies, so callers may modify them and Save them back.
+type ScheduleRepository interface {
+	// Save creates the schedule or replaces the one with the same ID.
+	Save(schedule *models.Schedule) error
+	Get(id string) (*models.Schedule, error)
+	Delete(id string) error
+	// List returns the schedules of an owner (user ID or email; all if empty), oldest first.
+	List(owner string) ([]*models.Schedule, error)
+}
+
+// FileScheduleRepository implements ScheduleRepository with a JSON file.
+// Schedules are definitions rather than history, so they are kept next to the saved
+// configs and survive restarts whichever task store is used.
+type FileScheduleRepository struct {
+	*fileStore[models.Schedule]
+}
+
+// NewFileScheduleRepository loads the schedules stored in path, which is created on
+// the first change if it does not exist.
+func NewFileScheduleRepository(path string) (*FileScheduleRepository, error) {
+	store, err := newFileStore(path, "schedules", fileRecord[models.Schedule]{
+		id:        func(s *models.Schedule) string { return s.ID },
+		createdAt: func(s *models.Schedule) time.Time { return s.CreatedAt },
+		owned: func(s *models.Schedule, owner string) bool {
+			return s.Owner == owner || s.OwnerEmail == owner
+		},
+		clone: func(s *models.Schedule) *models.Schedule {
+			copied := *s
+			copied.Labels = models.CopyLabels(s.Labels)
+			return &copied
+		},
+	}, ErrScheduleNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &FileScheduleRepository{store}, nil
+}
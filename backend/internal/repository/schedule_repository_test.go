@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+func TestFileScheduleRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedules.json")
+	repo, err := NewFileScheduleRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileScheduleRepository failed: %v", err)
+	}
+
+	now := time.Now()
+	for i, owner := range []string{"u1", "u2", "u1"} {
+		schedule := &models.Schedule{
+			ID:        string(rune('a' + i)),
+			Cron:      "0 2 * * *",
+			Owner:     owner,
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		}
+		schedule.SourceImage = "docker.io/bitnami/redis:7"
+		if err := repo.Save(schedule); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	// Returned schedules are copies
+	schedule, err := repo.Get("a")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	schedule.Cron = "changed"
+	if stored, _ := repo.Get("a"); stored.Cron != "0 2 * * *" {
+		t.Errorf("Expected stored schedule to be unchanged, got cron %q", stored.Cron)
+	}
+
+	schedules, _ := repo.List("u1")
+	if len(schedules) != 2 || schedules[0].ID != "a" || schedules[1].ID != "c" {
+		t.Errorf("Expected schedules a and c of u1, got %d", len(schedules))
+	}
+
+	if err := repo.Delete("b"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := repo.Delete("b"); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("Expected ErrScheduleNotFound, got %v", err)
+	}
+
+	// Schedules survive reopening the file
+	reopened, err := NewFileScheduleRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileScheduleRepository failed: %v", err)
+	}
+	schedules, _ = reopened.List("")
+	if len(schedules) != 2 || schedules[0].SourceImage != "docker.io/bitnami/redis:7" {
+		t.Errorf("Expected 2 reloaded schedules, got %d", len(schedules))
+	}
+	if _, err := reopened.Get("b"); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("Expected ErrScheduleNotFound, got %v", err)
+	}
+}
+
+func TestFileScheduleRepository_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedules.json")
+	if err := os.WriteFile(path, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileScheduleRepository(path); err == nil {
+		t.Error("Expected error for invalid schedules file, got nil")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+// SQLiteJobRepository implements JobRepository in the database of a SQLiteTaskRepository.
+// The owner columns are indexed for listing; the complete job is stored as JSON.
+type SQLiteJobRepository struct {
+	db *sql.DB
+}
+
+// Jobs returns the job repository stored in the same database as the tasks.
+func (r *SQLiteTaskRepository) Jobs() *SQLiteJobRepository {
+	return &SQLiteJobRepository{db: r.db}
+}
+
+// Create adds a new job to the repository.
+func (r *SQLiteJobRepository) Create(job *models.SyncJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	_, err = r.db.Exec(
+		"INSERT INTO jobs (id, owner, owner_email, created_at, data) VALUES (?, ?, ?, ?, ?)",
+		job.ID, job.Owner, job.OwnerEmail, job.CreatedAt.UnixNano(), string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a job by ID.
+func (r *SQLiteJobRepository) Get(id string) (*models.SyncJob, error) {
+	var data string
+	err := r.db.QueryRow("SELECT data FROM jobs WHERE id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return decodeJob(data)
+}
+
+// Delete removes a job from the repository. Its tasks are left untouched.
+func (r *SQLiteJobRepository) Delete(id string) error {
+	res, err := r.db.Exec("DELETE FROM jobs WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// Query returns one page of jobs of an owner, newest first, plus the total number of matches.
+func (r *SQLiteJobRepository) Query(owner string, offset, limit int) ([]*models.SyncJob, int, error) {
+	where := ""
+	var args []interface{}
+	if owner != "" {
+		where = " WHERE owner = ? OR owner_email = ?"
+		args = append(args, owner, owner)
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM jobs"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	query := "SELECT data FROM jobs" + where + " ORDER BY created_at DESC, id DESC"
+	if limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, offset)
+	} else if offset > 0 {
+		query += " LIMIT -1 OFFSET ?"
+		args = append(args, offset)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*models.SyncJob{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan job: %w", err)
+		}
+		job, err := decodeJob(data)
+		if err != nil {
+			return nil, 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read jobs: %w", err)
+	}
+	return jobs, total, nil
+}
+
+// decodeJob restores a job from its JSON representation.
+func decodeJob(data string) (*models.SyncJob, error) {
+	job := &models.SyncJob{}
+	if err := json.Unmarshal([]byte(data), job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+	return job, nil
+}
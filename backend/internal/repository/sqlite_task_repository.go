@@ -0,0 +1,624 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver (no cgo required)
+)
+
+// sqliteMigrations holds the schema migrations, applied in order.
+// The number of applied migrations is tracked in PRAGMA user_version,
+// so existing entries must never be modified; append new ones instead.
+var sqliteMigrations = []string{
+	// 1: tasks and their log lines
+	`CREATE TABLE tasks (
+		id           TEXT PRIMARY KEY,
+		status       TEXT NOT NULL,
+		source_image TEXT NOT NULL,
+		dest_image   TEXT NOT NULL,
+		architecture TEXT NOT NULL,
+		start_time   INTEGER NOT NULL,
+		end_time     INTEGER,
+		data         TEXT NOT NULL
+	);
+	CREATE INDEX idx_tasks_status_start_time ON tasks (status, start_time);
+	CREATE INDEX idx_tasks_start_time ON tasks (start_time);
+	CREATE INDEX idx_tasks_end_time ON tasks (end_time);
+	CREATE TABLE task_logs (
+		task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+		seq     INTEGER NOT NULL,
+		line    TEXT NOT NULL,
+		PRIMARY KEY (task_id, seq)
+	);`,
+	// 2: task owners, backfilled from the JSON of tasks that already recorded one
+	`ALTER TABLE tasks ADD COLUMN owner TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN owner_email TEXT NOT NULL DEFAULT '';
+	UPDATE tasks SET
+		owner = COALESCE(json_extract(data, '$.owner'), ''),
+		owner_email = COALESCE(json_extract(data, '$.ownerEmail'), '');
+	CREATE INDEX idx_tasks_owner_start_time ON tasks (owner, start_time);
+	CREATE INDEX idx_tasks_owner_email_start_time ON tasks (owner_email, start_time);`,
+	// 3: registry hosts of source and destination, as computed by models.RegistryHost
+	`ALTER TABLE tasks ADD COLUMN source_registry TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN dest_registry TEXT NOT NULL DEFAULT '';
+	UPDATE tasks SET source_registry = CASE
+		WHEN instr(source_image, '/') = 0 THEN 'docker.io'
+		WHEN instr(substr(source_image, 1, instr(source_image, '/') - 1), '.') > 0
+			OR instr(substr(source_image, 1, instr(source_image, '/') - 1), ':') > 0
+			OR substr(source_image, 1, instr(source_image, '/') - 1) = 'localhost'
+		THEN substr(source_image, 1, instr(source_image, '/') - 1)
+		ELSE 'docker.io'
+	END,
+	dest_registry = CASE
+		WHEN instr(dest_image, '/') = 0 THEN 'docker.io'
+		WHEN instr(substr(dest_image, 1, instr(dest_image, '/') - 1), '.') > 0
+			OR instr(substr(dest_image, 1, instr(dest_image, '/') - 1), ':') > 0
+			OR substr(dest_image, 1, instr(dest_image, '/') - 1) = 'localhost'
+		THEN substr(dest_image, 1, instr(dest_image, '/') - 1)
+		ELSE 'docker.io'
+	END;
+	CREATE INDEX idx_tasks_source_registry ON tasks (source_registry);
+	CREATE INDEX idx_tasks_dest_registry ON tasks (dest_registry);`,
+	// 4: task labels, one row per label
+	`CREATE TABLE task_labels (
+		task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+		key     TEXT NOT NULL,
+		value   TEXT NOT NULL,
+		PRIMARY KEY (task_id, key)
+	);
+	CREATE INDEX idx_task_labels_key_value ON task_labels (key, value);
+	INSERT INTO task_labels (task_id, key, value)
+		SELECT tasks.id, labels.key, labels.value FROM tasks, json_each(tasks.data, '$.labels') AS labels;`,
+	// 5: Idempotency-Key of the creating request
+	`ALTER TABLE tasks ADD COLUMN idempotency_key TEXT NOT NULL DEFAULT '';
+	CREATE INDEX idx_tasks_idempotency_key ON tasks (idempotency_key) WHERE idempotency_key != '';`,
+	// 6: batch jobs, and the job of each task
+	`ALTER TABLE tasks ADD COLUMN job_id TEXT NOT NULL DEFAULT '';
+	CREATE INDEX idx_tasks_job_id ON tasks (job_id) WHERE job_id != '';
+	CREATE TABLE jobs (
+		id          TEXT PRIMARY KEY,
+		owner       TEXT NOT NULL,
+		owner_email TEXT NOT NULL,
+		created_at  INTEGER NOT NULL,
+		data        TEXT NOT NULL
+	);
+	CREATE INDEX idx_jobs_created_at ON jobs (created_at);`,
+	// 7: the schedule of each task
+	`ALTER TABLE tasks ADD COLUMN schedule_id TEXT NOT NULL DEFAULT '';
+	CREATE INDEX idx_tasks_schedule_id ON tasks (schedule_id) WHERE schedule_id != '';`,
+	// 8: the watch of each task
+	`ALTER TABLE tasks ADD COLUMN watch_id TEXT NOT NULL DEFAULT '';
+	CREATE INDEX idx_tasks_watch_id ON tasks (watch_id) WHERE watch_id != '';`,
+	// 9: the registry hook of each task
+	`ALTER TABLE tasks ADD COLUMN hook_id TEXT NOT NULL DEFAULT '';
+	CREATE INDEX idx_tasks_hook_id ON tasks (hook_id) WHERE hook_id != '';`,
+}
+
+// SQLiteTaskRepository implements TaskRepository on top of a SQLite database file.
+// Queryable fields are stored in indexed columns, the complete task as JSON, and
+// log lines in a separate table so that finished tasks keep their output across restarts.
+//
+// Tasks that are still active in this process are also kept in memory, so that all
+// callers share the same instance (required for live log streaming and queue positions).
+type SQLiteTaskRepository struct {
+	db   *sql.DB
+	mu   sync.RWMutex
+	live map[string]*models.SyncTask // Active tasks created or updated by this process
+}
+
+// NewSQLiteTaskRepository opens (or creates) the SQLite database at path and applies pending migrations.
+func NewSQLiteTaskRepository(path string) (*SQLiteTaskRepository, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open task database: %w", err)
+	}
+	// SQLite allows a single writer; serializing access avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteTaskRepository{
+		db:   db,
+		live: make(map[string]*models.SyncTask),
+	}, nil
+}
+
+// migrate applies all migrations that have not been applied yet.
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not support bind parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying database.
+func (r *SQLiteTaskRepository) Close() error {
+	return r.db.Close()
+}
+
+// Create adds a new task to the repository.
+func (r *SQLiteTaskRepository) Create(task *models.SyncTask) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO tasks (id, status, source_image, dest_image, architecture, start_time, end_time,
+			owner, owner_email, source_registry, dest_registry, idempotency_key, job_id, schedule_id, watch_id, hook_id, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		task.ID, string(task.Status), task.SourceImage, task.DestImage, task.Architecture,
+		task.StartTime.UnixNano(), nullableTime(task.EndTime), task.Owner, task.OwnerEmail,
+		models.RegistryHost(task.SourceImage), models.RegistryHost(task.DestImage), task.IdempotencyKey,
+		task.JobID, task.ScheduleID, task.WatchID, task.HookID, string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert task: %w", err)
+	}
+	if err := insertLogLines(tx, task.ID, task.GetLogLines(), 0); err != nil {
+		return err
+	}
+	if err := replaceLabels(tx, task.ID, task.Labels); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if !task.Status.IsTerminal() {
+		r.live[task.ID] = task
+	}
+	return nil
+}
+
+// Get retrieves a task by ID.
+func (r *SQLiteTaskRepository) Get(id string) (*models.SyncTask, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if task, ok := r.live[id]; ok {
+		return task, nil
+	}
+
+	var data string
+	err := r.db.QueryRow("SELECT data FROM tasks WHERE id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	task, err := decodeTask(data)
+	if err != nil {
+		return nil, err
+	}
+	if task.LogLines, err = r.loadLogLines(id); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Update modifies an existing task and persists log lines added since the last write.
+// Tasks that reached a terminal status are dropped from the in-memory cache.
+func (r *SQLiteTaskRepository) Update(task *models.SyncTask) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE tasks SET status = ?, source_image = ?, dest_image = ?, architecture = ?,
+			start_time = ?, end_time = ?, owner = ?, owner_email = ?, source_registry = ?, dest_registry = ?,
+			idempotency_key = ?, job_id = ?, schedule_id = ?, watch_id = ?, hook_id = ?, data = ?
+		WHERE id = ?`,
+		string(task.Status), task.SourceImage, task.DestImage, task.Architecture,
+		task.StartTime.UnixNano(), nullableTime(task.EndTime), task.Owner, task.OwnerEmail,
+		models.RegistryHost(task.SourceImage), models.RegistryHost(task.DestImage), task.IdempotencyKey,
+		task.JobID, task.ScheduleID, task.WatchID, task.HookID, string(data), task.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTaskNotFound
+	}
+	if err := replaceLabels(tx, task.ID, task.Labels); err != nil {
+		return err
+	}
+
+	// Log lines are append-only, so only the lines beyond the stored count are new
+	var stored int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM task_logs WHERE task_id = ?", task.ID).Scan(&stored); err != nil {
+		return fmt.Errorf("failed to count log lines: %w", err)
+	}
+	lines := task.GetLogLines()
+	if stored < len(lines) {
+		if err := insertLogLines(tx, task.ID, lines[stored:], stored); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if task.Status.IsTerminal() {
+		delete(r.live, task.ID)
+	} else {
+		r.live[task.ID] = task
+	}
+	return nil
+}
+
+// Delete removes a task and its log lines from the repository.
+func (r *SQLiteTaskRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, err := r.db.Exec("DELETE FROM tasks WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTaskNotFound
+	}
+	delete(r.live, id)
+	return nil
+}
+
+// List returns all tasks in the repository.
+// Log lines are not loaded; use Get to retrieve a single task with its logs.
+func (r *SQLiteTaskRepository) List() ([]*models.SyncTask, error) {
+	tasks, _, err := r.Query(&TaskQuery{})
+	return tasks, err
+}
+
+// Query returns one page of tasks matching the query, plus the total number of matches.
+// Filtering, sorting and pagination are performed by SQLite using the indexed columns.
+// The total ignores the cursor, so it is the same for every page.
+// Log lines are not loaded for the returned tasks.
+func (r *SQLiteTaskRepository) Query(q *TaskQuery) ([]*models.SyncTask, int, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if q.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, string(q.Status))
+	}
+	if q.Owner != "" {
+		conditions = append(conditions, "(owner = ? OR owner_email = ?)")
+		args = append(args, q.Owner, q.Owner)
+	}
+	if q.Finished {
+		placeholders := make([]string, len(models.TerminalStatuses))
+		for i, status := range models.TerminalStatuses {
+			placeholders[i] = "?"
+			args = append(args, string(status))
+		}
+		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if q.Active {
+		placeholders := make([]string, len(models.TerminalStatuses))
+		for i, status := range models.TerminalStatuses {
+			placeholders[i] = "?"
+			args = append(args, string(status))
+		}
+		conditions = append(conditions, "status NOT IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if q.IdempotencyKey != "" {
+		conditions = append(conditions, "idempotency_key = ?")
+		args = append(args, q.IdempotencyKey)
+	}
+	if q.JobID != "" {
+		conditions = append(conditions, "job_id = ?")
+		args = append(args, q.JobID)
+	}
+	if q.ScheduleID != "" {
+		conditions = append(conditions, "schedule_id = ?")
+		args = append(args, q.ScheduleID)
+	}
+	if q.WatchID != "" {
+		conditions = append(conditions, "watch_id = ?")
+		args = append(args, q.WatchID)
+	}
+	if q.HookID != "" {
+		conditions = append(conditions, "hook_id = ?")
+		args = append(args, q.HookID)
+	}
+	if q.Source != "" {
+		conditions = append(conditions, imageCondition("source_image", q.Source))
+		args = append(args, q.Source)
+	}
+	if q.Dest != "" {
+		conditions = append(conditions, imageCondition("dest_image", q.Dest))
+		args = append(args, q.Dest)
+	}
+	if q.Registry != "" {
+		conditions = append(conditions, "(source_registry = ? OR dest_registry = ?)")
+		args = append(args, q.Registry, q.Registry)
+	}
+	if q.Architecture != "" {
+		conditions = append(conditions, "architecture = ?")
+		args = append(args, q.Architecture)
+	}
+	for _, selector := range q.Labels {
+		if selector.AnyValue {
+			conditions = append(conditions, "EXISTS (SELECT 1 FROM task_labels WHERE task_id = tasks.id AND key = ?)")
+			args = append(args, selector.Key)
+		} else {
+			conditions = append(conditions, "EXISTS (SELECT 1 FROM task_labels WHERE task_id = tasks.id AND key = ? AND value = ?)")
+			args = append(args, selector.Key, selector.Value)
+		}
+	}
+	if !q.StartedAfter.IsZero() {
+		conditions = append(conditions, "start_time >= ?")
+		args = append(args, q.StartedAfter.UnixNano())
+	}
+	if !q.StartedBefore.IsZero() {
+		conditions = append(conditions, "start_time < ?")
+		args = append(args, q.StartedBefore.UnixNano())
+	}
+	if !q.EndedAfter.IsZero() {
+		conditions = append(conditions, "end_time >= ?")
+		args = append(args, q.EndedAfter.UnixNano())
+	}
+	if !q.EndedBefore.IsZero() {
+		conditions = append(conditions, "end_time < ?")
+		args = append(args, q.EndedBefore.UnixNano())
+	}
+	if q.MinDuration > 0 {
+		conditions = append(conditions, "end_time - start_time >= ?")
+		args = append(args, int64(q.MinDuration))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM tasks"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	sortBy, sortOrder := normalizeSort(q.SortBy, q.SortOrder)
+	nullExpr, valueExpr := sortExprs(sortBy)
+	if q.After != nil {
+		// Keyset pagination: row value comparison against the cursor's sort key
+		op := "<"
+		if sortOrder == "asc" {
+			op = ">"
+		}
+		if nullExpr == "" {
+			nullExpr = "0"
+		}
+		keyset := fmt.Sprintf("(%s, %s, id) %s (?, ?, ?)", nullExpr, valueExpr, op)
+		if where == "" {
+			where = " WHERE " + keyset
+		} else {
+			where += " AND " + keyset
+		}
+		var value interface{} = q.After.Num
+		if sortBy == SortBySourceImage || sortBy == SortByDestImage {
+			value = q.After.Str
+		}
+		null := 0
+		if q.After.Null {
+			null = 1
+		}
+		args = append(args, null, value, q.After.ID)
+	}
+
+	query := "SELECT id, data FROM tasks" + where + " ORDER BY " + orderClause(sortBy, sortOrder)
+	if q.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, q.Limit, q.Offset)
+	} else if q.Offset > 0 {
+		query += " LIMIT -1 OFFSET ?"
+		args = append(args, q.Offset)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []*models.SyncTask{}
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan task: %w", err)
+		}
+		// Prefer the live instance, which reflects in-flight state
+		if task, ok := r.live[id]; ok {
+			tasks = append(tasks, task)
+			continue
+		}
+		task, err := decodeTask(data)
+		if err != nil {
+			return nil, 0, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	return tasks, total, nil
+}
+
+// loadLogLines returns the stored log lines of a task in order.
+func (r *SQLiteTaskRepository) loadLogLines(id string) ([]string, error) {
+	rows, err := r.db.Query("SELECT line FROM task_logs WHERE task_id = ? ORDER BY seq", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load log lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []string{}
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("failed to scan log line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// sortExprs returns the SQL expressions of the sort key for a normalized sort field,
+// matching taskSortKey of the in-memory repository: whether the value is missing
+// (missing values sort after present ones; empty if it is never missing) and the value itself.
+func sortExprs(sortBy string) (nullExpr, valueExpr string) {
+	switch sortBy {
+	case SortByEndTime:
+		return "end_time IS NULL", "COALESCE(end_time, 0)"
+	case SortByDuration:
+		return "end_time IS NULL", "COALESCE(end_time - start_time, 0)"
+	case SortBySourceImage:
+		return "", "source_image"
+	case SortByDestImage:
+		return "", "dest_image"
+	default:
+		return "", "start_time"
+	}
+}
+
+// orderClause builds the ORDER BY clause matching sortTasks of the in-memory repository:
+// tasks without an end time sort as the most recent and longest ones, ties are broken by ID.
+func orderClause(sortBy, sortOrder string) string {
+	dir := "DESC"
+	if sortOrder == "asc" {
+		dir = "ASC"
+	}
+	nullExpr, valueExpr := sortExprs(sortBy)
+	if nullExpr == "" {
+		return fmt.Sprintf("%s %s, id %s", valueExpr, dir, dir)
+	}
+	return fmt.Sprintf("%s %s, %s %s, id %s", nullExpr, dir, valueExpr, dir, dir)
+}
+
+// imageCondition returns the condition matching an image column against a substring
+// or, if the filter contains glob characters, a GLOB pattern. It takes one argument.
+func imageCondition(column, filter string) string {
+	if isGlob(filter) {
+		return column + " GLOB ?"
+	}
+	return "instr(" + column + ", ?) > 0"
+}
+
+// insertLogLines stores log lines of a task, numbering them from firstSeq.
+func insertLogLines(tx *sql.Tx, taskID string, lines []string, firstSeq int) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	stmt, err := tx.Prepare("INSERT INTO task_logs (task_id, seq, line) VALUES (?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare log insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for i, line := range lines {
+		if _, err := stmt.Exec(taskID, firstSeq+i, line); err != nil {
+			return fmt.Errorf("failed to insert log line: %w", err)
+		}
+	}
+	return nil
+}
+
+// replaceLabels stores labels as the complete set of labels of a task.
+func replaceLabels(tx *sql.Tx, taskID string, labels map[string]string) error {
+	if _, err := tx.Exec("DELETE FROM task_labels WHERE task_id = ?", taskID); err != nil {
+		return fmt.Errorf("failed to delete labels: %w", err)
+	}
+	for key, value := range labels {
+		if _, err := tx.Exec("INSERT INTO task_labels (task_id, key, value) VALUES (?, ?, ?)", taskID, key, value); err != nil {
+			return fmt.Errorf("failed to insert label: %w", err)
+		}
+	}
+	return nil
+}
+
+// decodeTask restores a task from its JSON representation.
+func decodeTask(data string) (*models.SyncTask, error) {
+	task := &models.SyncTask{}
+	if err := json.Unmarshal([]byte(data), task); err != nil {
+		return nil, fmt.Errorf("failed to decode task: %w", err)
+	}
+	task.LogLines = []string{}
+	return task, nil
+}
+
+// nullableTime converts an optional timestamp to a value for a nullable INTEGER column.
+func nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UnixNano()
+}
@@ -0,0 +1,521 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+func newTestSQLiteRepository(t *testing.T, path string) *SQLiteTaskRepository {
+	t.Helper()
+	repo, err := NewSQLiteTaskRepository(path)
+	if err != nil {
+		t.Fatalf("Failed to open SQLite repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestSQLiteTaskRepository_CreateGet(t *testing.T) {
+	repo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	task := models.NewSyncTask("test-id", "src", "dest", "all")
+
+	if err := repo.Create(task); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	retrieved, err := repo.Get("test-id")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if retrieved != task {
+		t.Error("Expected active task to be served from memory")
+	}
+
+	if _, err := repo.Get("non-existent"); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestSQLiteTaskRepository_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	repo := newTestSQLiteRepository(t, path)
+
+	task := models.NewSyncTask("test-id", "nginx:latest", "registry.example.com/nginx:latest", "linux/amd64")
+	repo.Create(task)
+	task.AddLog("First log")
+	task.Status = models.StatusRunning
+	repo.Update(task)
+	task.AddLog("Second log")
+	endTime := time.Now()
+	task.EndTime = &endTime
+	task.Status = models.StatusCompleted
+	task.Message = "Done"
+	repo.Update(task)
+	repo.Close()
+
+	reopened := newTestSQLiteRepository(t, path)
+	retrieved, err := reopened.Get("test-id")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if retrieved.Status != models.StatusCompleted || retrieved.Message != "Done" {
+		t.Errorf("Expected completed/Done, got %s/%s", retrieved.Status, retrieved.Message)
+	}
+	if retrieved.Architecture != "linux/amd64" || retrieved.EndTime == nil {
+		t.Errorf("Expected task fields to be restored, got %+v", retrieved)
+	}
+
+	logs := retrieved.GetLogLines()
+	if len(logs) != 2 || logs[0] != "First log" || logs[1] != "Second log" {
+		t.Errorf("Expected persisted log lines, got %v", logs)
+	}
+}
+
+func TestSQLiteTaskRepository_UpdateDelete(t *testing.T) {
+	repo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+
+	if err := repo.Update(models.NewSyncTask("missing", "src", "dest", "all")); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound on update, got %v", err)
+	}
+
+	repo.Create(models.NewSyncTask("test-id", "src", "dest", "all"))
+	if err := repo.Delete("test-id"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if _, err := repo.Get("test-id"); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound after delete, got %v", err)
+	}
+	if err := repo.Delete("test-id"); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound on second delete, got %v", err)
+	}
+}
+
+func TestSQLiteTaskRepository_Query(t *testing.T) {
+	repo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	base := time.Now()
+
+	for i := 0; i < 5; i++ {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		task.StartTime = base.Add(time.Duration(i) * time.Minute)
+		if i%2 == 0 {
+			task.Status = models.StatusCompleted
+			endTime := task.StartTime.Add(time.Duration(10-i) * time.Minute)
+			task.EndTime = &endTime
+		}
+		repo.Create(task)
+	}
+
+	tasks, total, err := repo.Query(&TaskQuery{Status: models.StatusCompleted, SortOrder: "asc"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != 3 || len(tasks) != 3 {
+		t.Fatalf("Expected 3 completed tasks, got total=%d len=%d", total, len(tasks))
+	}
+	if tasks[0].ID != "id0" || tasks[2].ID != "id4" {
+		t.Errorf("Expected ascending start time order, got %s..%s", tasks[0].ID, tasks[2].ID)
+	}
+
+	tasks, total, _ = repo.Query(&TaskQuery{Offset: 1, Limit: 2})
+	if total != 5 || len(tasks) != 2 {
+		t.Fatalf("Expected page of 2 out of 5, got total=%d len=%d", total, len(tasks))
+	}
+	if tasks[0].ID != "id3" || tasks[1].ID != "id2" {
+		t.Errorf("Expected id3, id2 on second page (desc), got %s, %s", tasks[0].ID, tasks[1].ID)
+	}
+
+	// Tasks without an end time sort first in descending end time order
+	tasks, _, _ = repo.Query(&TaskQuery{SortBy: "endTime", SortOrder: "desc"})
+	if tasks[0].EndTime != nil || tasks[1].EndTime != nil {
+		t.Error("Expected active tasks first when sorting by end time descending")
+	}
+	if tasks[2].ID != "id4" || tasks[4].ID != "id0" {
+		t.Errorf("Expected id4..id0 by end time, got %s..%s", tasks[2].ID, tasks[4].ID)
+	}
+}
+
+func TestSQLiteTaskRepository_MatchesInMemoryOrdering(t *testing.T) {
+	sqliteRepo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	memoryRepo := NewInMemoryTaskRepository()
+	base := time.Now()
+
+	for i := 0; i < 6; i++ {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		// Duplicate start times exercise the ID tie-breaker
+		task.StartTime = base.Add(time.Duration(i/2) * time.Minute)
+		sqliteRepo.Create(task)
+		memoryRepo.Create(task)
+	}
+
+	for _, order := range []string{"asc", "desc"} {
+		for _, sortBy := range []string{"startTime", "endTime"} {
+			q := &TaskQuery{SortBy: sortBy, SortOrder: order}
+			fromSQLite, _, _ := sqliteRepo.Query(q)
+			fromMemory, _, _ := memoryRepo.Query(q)
+			for i := range fromMemory {
+				if fromSQLite[i].ID != fromMemory[i].ID {
+					t.Errorf("%s %s: position %d differs: sqlite=%s memory=%s",
+						sortBy, order, i, fromSQLite[i].ID, fromMemory[i].ID)
+				}
+			}
+		}
+	}
+}
+
+func TestSQLiteTaskRepository_OwnerFilter(t *testing.T) {
+	sqliteRepo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	memoryRepo := NewInMemoryTaskRepository()
+
+	owners := []struct{ id, email string }{{"u1", "alice@example.com"}, {"u2", "bob@example.com"}, {"", ""}}
+	for i, owner := range owners {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		task.Owner, task.OwnerEmail = owner.id, owner.email
+		sqliteRepo.Create(task)
+		memoryRepo.Create(task)
+	}
+
+	for name, repo := range map[string]TaskRepository{"sqlite": sqliteRepo, "memory": memoryRepo} {
+		for _, owner := range []string{"u1", "alice@example.com"} {
+			tasks, total, err := repo.Query(&TaskQuery{Owner: owner})
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", name, err)
+			}
+			if total != 1 || tasks[0].ID != "id0" {
+				t.Errorf("%s: expected only id0 for owner %s, got total=%d", name, owner, total)
+			}
+		}
+		if _, total, _ := repo.Query(&TaskQuery{Owner: "carol@example.com"}); total != 0 {
+			t.Errorf("%s: expected no tasks for unknown owner, got %d", name, total)
+		}
+	}
+}
+
+func TestSQLiteTaskRepository_MigratesOwnerColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+
+	// Create a database at schema version 1 holding a task that recorded its owner in JSON only
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if _, err := db.Exec(sqliteMigrations[0]); err != nil {
+		t.Fatalf("Failed to apply migration 1: %v", err)
+	}
+	db.Exec("PRAGMA user_version = 1")
+	task := models.NewSyncTask("old-id", "src", "dest", "all")
+	task.Owner, task.OwnerEmail = "u1", "alice@example.com"
+	data, _ := json.Marshal(task)
+	_, err = db.Exec(`INSERT INTO tasks (id, status, source_image, dest_image, architecture, start_time, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, task.ID, string(task.Status), "src", "dest", "all", task.StartTime.UnixNano(), string(data))
+	if err != nil {
+		t.Fatalf("Failed to insert task: %v", err)
+	}
+	db.Close()
+
+	repo := newTestSQLiteRepository(t, path)
+	tasks, total, err := repo.Query(&TaskQuery{Owner: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != 1 || tasks[0].ID != "old-id" {
+		t.Errorf("Expected migrated task to match its owner, got total=%d", total)
+	}
+}
+
+func TestSQLiteTaskRepository_FinishedFilter(t *testing.T) {
+	sqliteRepo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	memoryRepo := NewInMemoryTaskRepository()
+	base := time.Now()
+
+	for i, status := range []models.SyncStatus{models.StatusRunning, models.StatusCompleted, models.StatusFailed, models.StatusSkipped} {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		task.Status = status
+		if status.IsTerminal() {
+			endTime := base.Add(-time.Duration(i) * time.Hour)
+			task.EndTime = &endTime
+		}
+		sqliteRepo.Create(task)
+		memoryRepo.Create(task)
+	}
+
+	for name, repo := range map[string]TaskRepository{"sqlite": sqliteRepo, "memory": memoryRepo} {
+		if _, total, _ := repo.Query(&TaskQuery{Finished: true}); total != 3 {
+			t.Errorf("%s: expected 3 finished tasks, got %d", name, total)
+		}
+		tasks, total, _ := repo.Query(&TaskQuery{Finished: true, EndedBefore: base.Add(-90 * time.Minute)})
+		if total != 2 || tasks[0].ID == "id1" || tasks[1].ID == "id1" {
+			t.Errorf("%s: expected id2 and id3 to have ended before the cutoff, got total=%d", name, total)
+		}
+	}
+}
+
+func TestSQLiteTaskRepository_ImageAndTimeFilters(t *testing.T) {
+	sqliteRepo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	memoryRepo := NewInMemoryTaskRepository()
+	base := time.Now()
+
+	images := []struct{ src, dest, arch string }{
+		{"nginx:latest", "registry.example.com/nginx:latest", "all"},
+		{"docker.io/library/redis:7", "registry.example.com/redis:7", "linux/amd64"},
+		{"ghcr.io/acme/app:v1", "harbor.local:5000/acme/app:v1", "linux/arm64"},
+	}
+	for i, img := range images {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), img.src, img.dest, img.arch)
+		task.StartTime = base.Add(time.Duration(i) * time.Hour)
+		task.Status = models.StatusCompleted
+		endTime := task.StartTime.Add(time.Duration(i+1) * time.Minute)
+		task.EndTime = &endTime
+		sqliteRepo.Create(task)
+		memoryRepo.Create(task)
+	}
+
+	tests := []struct {
+		name  string
+		query TaskQuery
+		ids   []string
+	}{
+		{"source substring", TaskQuery{Source: "redis"}, []string{"id1"}},
+		{"source glob", TaskQuery{Source: "*/acme/*"}, []string{"id2"}},
+		{"dest glob", TaskQuery{Dest: "registry.example.com/*:latest"}, []string{"id0"}},
+		{"registry default", TaskQuery{Registry: "docker.io"}, []string{"id1", "id0"}},
+		{"registry with port", TaskQuery{Registry: "harbor.local:5000"}, []string{"id2"}},
+		{"architecture", TaskQuery{Architecture: "linux/arm64"}, []string{"id2"}},
+		{"started after", TaskQuery{StartedAfter: base.Add(30 * time.Minute)}, []string{"id2", "id1"}},
+		{"started before", TaskQuery{StartedBefore: base.Add(30 * time.Minute)}, []string{"id0"}},
+		{"ended after", TaskQuery{EndedAfter: base.Add(61 * time.Minute)}, []string{"id2", "id1"}},
+		{"min duration", TaskQuery{MinDuration: 2 * time.Minute}, []string{"id2", "id1"}},
+		{"sort by duration", TaskQuery{SortBy: SortByDuration, SortOrder: "asc"}, []string{"id0", "id1", "id2"}},
+		{"sort by source", TaskQuery{SortBy: SortBySourceImage, SortOrder: "asc"}, []string{"id1", "id2", "id0"}},
+	}
+
+	for _, tt := range tests {
+		for name, repo := range map[string]TaskRepository{"sqlite": sqliteRepo, "memory": memoryRepo} {
+			q := tt.query
+			tasks, total, err := repo.Query(&q)
+			if err != nil {
+				t.Fatalf("%s %s: expected no error, got %v", tt.name, name, err)
+			}
+			if total != len(tt.ids) || len(tasks) != len(tt.ids) {
+				t.Errorf("%s %s: expected %v, got total=%d len=%d", tt.name, name, tt.ids, total, len(tasks))
+				continue
+			}
+			for i, id := range tt.ids {
+				if tasks[i].ID != id {
+					t.Errorf("%s %s: position %d expected %s, got %s", tt.name, name, i, id, tasks[i].ID)
+				}
+			}
+		}
+	}
+}
+
+func TestSQLiteTaskRepository_CursorPagination(t *testing.T) {
+	sqliteRepo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	memoryRepo := NewInMemoryTaskRepository()
+	base := time.Now()
+
+	for i := 0; i < 7; i++ {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		// Duplicate start times exercise the ID tie-breaker, and odd tasks have no end time
+		task.StartTime = base.Add(time.Duration(i/2) * time.Minute)
+		if i%2 == 0 {
+			endTime := task.StartTime.Add(time.Duration(i) * time.Second)
+			task.EndTime = &endTime
+		}
+		sqliteRepo.Create(task)
+		memoryRepo.Create(task)
+	}
+
+	for _, sortBy := range []string{SortByStartTime, SortByEndTime, SortByDuration} {
+		for _, order := range []string{"asc", "desc"} {
+			for name, repo := range map[string]TaskRepository{"sqlite": sqliteRepo, "memory": memoryRepo} {
+				all, _, _ := repo.Query(&TaskQuery{SortBy: sortBy, SortOrder: order})
+
+				var paged []*models.SyncTask
+				var after *TaskCursor
+				for pages := 0; pages < 10; pages++ {
+					tasks, total, err := repo.Query(&TaskQuery{SortBy: sortBy, SortOrder: order, After: after, Limit: 3})
+					if err != nil {
+						t.Fatalf("%s %s %s: expected no error, got %v", name, sortBy, order, err)
+					}
+					if total != 7 {
+						t.Errorf("%s %s %s: expected total to ignore the cursor, got %d", name, sortBy, order, total)
+					}
+					if len(tasks) == 0 {
+						break
+					}
+					paged = append(paged, tasks...)
+					after = NewTaskCursor(tasks[len(tasks)-1], sortBy, order)
+				}
+
+				if len(paged) != len(all) {
+					t.Fatalf("%s %s %s: expected %d tasks across pages, got %d", name, sortBy, order, len(all), len(paged))
+				}
+				for i := range all {
+					if paged[i].ID != all[i].ID {
+						t.Errorf("%s %s %s: position %d expected %s, got %s", name, sortBy, order, i, all[i].ID, paged[i].ID)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSQLiteTaskRepository_LabelFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	sqliteRepo := newTestSQLiteRepository(t, path)
+	memoryRepo := NewInMemoryTaskRepository()
+
+	labels := []map[string]string{
+		{"ticket": "OPS-1", "release": "2025.03"},
+		{"ticket": "OPS-2"},
+		nil,
+	}
+	for i, l := range labels {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		task.Labels = l
+		sqliteRepo.Create(task)
+		memoryRepo.Create(task)
+	}
+
+	// Labels replaced after creation must be reflected in queries
+	task, _ := sqliteRepo.Get("id1")
+	task.Labels = map[string]string{"ticket": "OPS-3"}
+	sqliteRepo.Update(task)
+	memoryRepo.Update(task)
+
+	tests := []struct {
+		selectors []string
+		total     int
+	}{
+		{[]string{"ticket"}, 2},
+		{[]string{"ticket=OPS-1"}, 1},
+		{[]string{"ticket=OPS-2"}, 0},
+		{[]string{"ticket=OPS-3"}, 1},
+		{[]string{"ticket", "release=2025.03"}, 1},
+		{[]string{"release="}, 0},
+		{[]string{"missing"}, 0},
+	}
+
+	for _, tt := range tests {
+		q := &TaskQuery{}
+		for _, s := range tt.selectors {
+			selector, err := ParseLabelSelector(s)
+			if err != nil {
+				t.Fatalf("Failed to parse selector %q: %v", s, err)
+			}
+			q.Labels = append(q.Labels, selector)
+		}
+		for name, repo := range map[string]TaskRepository{"sqlite": sqliteRepo, "memory": memoryRepo} {
+			if _, total, _ := repo.Query(q); total != tt.total {
+				t.Errorf("%s %v: expected %d tasks, got %d", name, tt.selectors, tt.total, total)
+			}
+		}
+	}
+
+	sqliteRepo.Close()
+	reopened := newTestSQLiteRepository(t, path)
+	retrieved, err := reopened.Get("id0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if retrieved.Labels["release"] != "2025.03" {
+		t.Errorf("Expected labels to be persisted, got %v", retrieved.Labels)
+	}
+	if _, err := ParseLabelSelector("=value"); err == nil {
+		t.Error("Expected error for selector without key")
+	}
+}
+
+func TestSQLiteTaskRepository_ActiveAndIdempotencyKeyFilters(t *testing.T) {
+	sqliteRepo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	memoryRepo := NewInMemoryTaskRepository()
+
+	for i, status := range []models.SyncStatus{models.StatusPending, models.StatusRunning, models.StatusCompleted} {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		task.Status = status
+		task.IdempotencyKey = fmt.Sprintf("key-%d", i%2)
+		sqliteRepo.Create(task)
+		memoryRepo.Create(task)
+	}
+
+	for name, repo := range map[string]TaskRepository{"sqlite": sqliteRepo, "memory": memoryRepo} {
+		if _, total, _ := repo.Query(&TaskQuery{Active: true}); total != 2 {
+			t.Errorf("%s: expected 2 active tasks, got %d", name, total)
+		}
+		tasks, total, _ := repo.Query(&TaskQuery{IdempotencyKey: "key-1"})
+		if total != 1 || tasks[0].ID != "id1" {
+			t.Errorf("%s: expected only id1 for key-1, got total=%d", name, total)
+		}
+		if _, total, _ := repo.Query(&TaskQuery{IdempotencyKey: "key-0", Active: true}); total != 1 {
+			t.Errorf("%s: expected one active task for key-0, got %d", name, total)
+		}
+	}
+}
+
+func TestSQLiteTaskRepository_JobFilter(t *testing.T) {
+	sqliteRepo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	memoryRepo := NewInMemoryTaskRepository()
+
+	for i := 0; i < 3; i++ {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		if i > 0 {
+			task.JobID = "job1"
+		}
+		sqliteRepo.Create(task)
+		memoryRepo.Create(task)
+	}
+
+	for name, repo := range map[string]TaskRepository{"sqlite": sqliteRepo, "memory": memoryRepo} {
+		if _, total, _ := repo.Query(&TaskQuery{JobID: "job1"}); total != 2 {
+			t.Errorf("%s: expected 2 tasks of job1, got %d", name, total)
+		}
+	}
+}
+
+func TestSQLiteTaskRepository_ScheduleWatchAndHookFilter(t *testing.T) {
+	sqliteRepo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "tasks.db"))
+	memoryRepo := NewInMemoryTaskRepository()
+
+	for i := 0; i < 4; i++ {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		if i < 2 {
+			task.ScheduleID = "schedule1"
+		} else if i == 2 {
+			task.WatchID = "watch1"
+		} else {
+			task.HookID = "hook1"
+		}
+		sqliteRepo.Create(task)
+		memoryRepo.Create(task)
+	}
+
+	for name, repo := range map[string]TaskRepository{"sqlite": sqliteRepo, "memory": memoryRepo} {
+		tasks, total, _ := repo.Query(&TaskQuery{ScheduleID: "schedule1"})
+		if total != 2 {
+			t.Errorf("%s: expected 2 tasks of schedule1, got %d", name, total)
+		}
+		for _, task := range tasks {
+			if task.ScheduleID != "schedule1" {
+				t.Errorf("%s: expected schedule1, got %q", name, task.ScheduleID)
+			}
+		}
+		tasks, total, _ = repo.Query(&TaskQuery{WatchID: "watch1"})
+		if total != 1 || tasks[0].ID != "id2" {
+			t.Errorf("%s: expected task id2 of watch1, got %d tasks", name, total)
+		}
+		tasks, total, _ = repo.Query(&TaskQuery{HookID: "hook1"})
+		if total != 1 || tasks[0].ID != "id3" {
+			t.Errorf("%s: expected task id3 of hook1, got %d tasks", name, total)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+var (
+	// ErrSubscriptionNotFound is returned when a requested subscription does not exist.
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+)
+
+// SubscriptionRepository defines the interface for subscription persistence operations.
+// Subscriptions are returned as copies, so callers may modify them and Save them back.
+type SubscriptionRepository interface {
+	// Save creates the subscription or replaces the one with the same ID.
+	Save(subscription *models.Subscription) error
+	Get(id string) (*models.Subscription, error)
+	Delete(id string) error
+	// List returns the subscriptions of an owner (user ID or email; all if empty), oldest first.
+	List(owner string) ([]*models.Subscription, error)
+}
+
+// FileSubscriptionRepository implements SubscriptionRepository with a JSON file, next to the schedules.
+type FileSubscriptionRepository struct {
+	*fileStore[models.Subscription]
+}
+
+// NewFileSubscriptionRepository loads the subscriptions stored in path, which is created
+// on the first change if it does not exist.
+func NewFileSubscriptionRepository(path string) (*FileSubscriptionRepository, error) {
+	store, err := newFileStore(path, "subscriptions", fileRecord[models.Subscription]{
+		id:        func(s *models.Subscription) string { return s.ID },
+		createdAt: func(s *models.Subscription) time.Time { return s.CreatedAt },
+		owned: func(s *models.Subscription, owner string) bool {
+			return s.Owner == owner || s.OwnerEmail == owner
+		},
+		clone: func(s *models.Subscription) *models.Subscription {
+			copied := *s
+			copied.Labels = models.CopyLabels(s.Labels)
+			copied.Tags.Include = append([]string(nil), s.Tags.Include...)
+			copied.Tags.Exclude = append([]string(nil), s.Tags.Exclude...)
+			copied.Discoveries = make([]models.TagDiscovery, len(s.Discoveries))
+			for i, discovery := range s.Discoveries {
+				discovery.Tags = append([]string(nil), discovery.Tags...)
+				copied.Discoveries[i] = discovery
+			}
+			return &copied
+		},
+	}, ErrSubscriptionNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSubscriptionRepository{store}, nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+func TestFileSubscriptionRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subscriptions.json")
+	repo, err := NewFileSubscriptionRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileSubscriptionRepository failed: %v", err)
+	}
+
+	subscription := &models.Subscription{
+		ID:          "s1",
+		Owner:       "u1",
+		Discoveries: []models.TagDiscovery{{Tags: []string{"1.27.0"}, JobID: "job1"}},
+	}
+	subscription.Tags.Include = []string{"^1\\."}
+	if err := repo.Save(subscription); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Discovered tags and filters of returned subscriptions are copies
+	stored, _ := repo.Get("s1")
+	stored.Discoveries[0].Tags[0] = "changed"
+	stored.Tags.Include[0] = "changed"
+	if stored, _ := repo.Get("s1"); stored.Discoveries[0].Tags[0] != "1.27.0" || stored.Tags.Include[0] != "^1\\." {
+		t.Errorf("Expected stored subscription to be unchanged, got %v and %v", stored.Discoveries[0].Tags, stored.Tags.Include)
+	}
+
+	reopened, err := NewFileSubscriptionRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileSubscriptionRepository failed: %v", err)
+	}
+	if subscriptions, _ := reopened.List("u1"); len(subscriptions) != 1 || subscriptions[0].Discoveries[0].JobID != "job1" {
+		t.Errorf("Expected 1 reloaded subscription with discoveries, got %d", len(subscriptions))
+	}
+	if err := reopened.Delete("s2"); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("Expected ErrSubscriptionNotFound, got %v", err)
+	}
+}
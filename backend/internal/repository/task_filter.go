@@ -0,0 +1,288 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Sort fields supported by TaskQuery.SortBy.
+const (
+	SortByStartTime   = "startTime"
+	SortByEndTime     = "endTime"
+	SortByDuration    = "duration"
+	SortBySourceImage = "sourceImage"
+	SortByDestImage   = "destImage"
+)
+
+// normalizeSort returns the effective sort field and order of a query:
+// unknown fields sort by start time, and any order other than "asc" is descending.
+func normalizeSort(sortBy, sortOrder string) (string, string) {
+	switch sortBy {
+	case SortByEndTime, SortByDuration, SortBySourceImage, SortByDestImage:
+	default:
+		sortBy = SortByStartTime
+	}
+	if sortOrder != "asc" {
+		sortOrder = "desc"
+	}
+	return sortBy, sortOrder
+}
+
+// TaskCursor marks a position in a sorted task list for keyset pagination.
+// It holds the sort key of the last task of a page, so that the next page starts
+// right after it even if tasks were added or removed in the meantime.
+type TaskCursor struct {
+	SortBy    string `json:"s"`
+	SortOrder string `json:"o"`
+	Null      bool   `json:"n,omitempty"` // The sort value is missing (end time or duration of an active task)
+	Num       int64  `json:"v,omitempty"` // Numeric sort value (times and durations in nanoseconds)
+	Str       string `json:"t,omitempty"` // String sort value (image names)
+	ID        string `json:"i"`           // Task ID, the tie-breaker
+}
+
+// NewTaskCursor returns the cursor pointing right after task in the given sort order.
+func NewTaskCursor(task *models.SyncTask, sortBy, sortOrder string) *TaskCursor {
+	sortBy, sortOrder = normalizeSort(sortBy, sortOrder)
+	key := taskSortKey(task, sortBy)
+	key.SortBy, key.SortOrder = sortBy, sortOrder
+	return &key
+}
+
+// Encode returns the opaque string form of the cursor used in API responses.
+func (c *TaskCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeTaskCursor parses a cursor returned by Encode.
+// Returns ErrInvalidCursor if the string is not a valid cursor.
+func DecodeTaskCursor(s string) (*TaskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c TaskCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Matches reports whether the cursor was created for the given sort field and order.
+func (c *TaskCursor) Matches(sortBy, sortOrder string) bool {
+	sortBy, sortOrder = normalizeSort(sortBy, sortOrder)
+	return c.SortBy == sortBy && c.SortOrder == sortOrder
+}
+
+// taskSortKey returns the sort key of a task for a normalized sort field.
+// Missing values (active tasks) sort after all present ones.
+func taskSortKey(task *models.SyncTask, sortBy string) TaskCursor {
+	key := TaskCursor{ID: task.ID}
+	switch sortBy {
+	case SortByEndTime:
+		if task.EndTime == nil {
+			key.Null = true
+		} else {
+			key.Num = task.EndTime.UnixNano()
+		}
+	case SortByDuration:
+		if task.EndTime == nil {
+			key.Null = true
+		} else {
+			key.Num = task.EndTime.UnixNano() - task.StartTime.UnixNano()
+		}
+	case SortBySourceImage:
+		key.Str = task.SourceImage
+	case SortByDestImage:
+		key.Str = task.DestImage
+	default:
+		key.Num = task.StartTime.UnixNano()
+	}
+	return key
+}
+
+// compareSortKeys compares two sort keys of the same sort field.
+func compareSortKeys(a, b *TaskCursor) int {
+	if a.Null != b.Null {
+		if a.Null {
+			return 1
+		}
+		return -1
+	}
+	switch {
+	case a.Num < b.Num:
+		return -1
+	case a.Num > b.Num:
+		return 1
+	}
+	if c := strings.Compare(a.Str, b.Str); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// isGlob reports whether an image filter is a glob pattern rather than a substring.
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// globToRegexp converts a glob pattern with the semantics of SQLite's GLOB operator
+// (* matches any sequence including "/", ? any single character, [...] a character
+// class, negated with ^) to an anchored regular expression.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta(pattern[i:]))
+				i = len(pattern)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// LabelSelector matches tasks by label: tasks that have the key, with the given value
+// unless AnyValue is set.
+type LabelSelector struct {
+	Key      string
+	Value    string
+	AnyValue bool
+}
+
+// ParseLabelSelector parses a label selector in "key" or "key=value" form.
+func ParseLabelSelector(s string) (LabelSelector, error) {
+	key, value, hasValue := strings.Cut(s, "=")
+	if key == "" {
+		return LabelSelector{}, fmt.Errorf("invalid label selector %q: expected key or key=value", s)
+	}
+	return LabelSelector{Key: key, Value: value, AnyValue: !hasValue}, nil
+}
+
+// Matches reports whether labels satisfy the selector.
+func (l LabelSelector) Matches(labels map[string]string) bool {
+	value, ok := labels[l.Key]
+	return ok && (l.AnyValue || value == l.Value)
+}
+
+// taskFilter evaluates the filters of a TaskQuery against tasks held in memory.
+type taskFilter struct {
+	q      *TaskQuery
+	source *regexp.Regexp // Compiled source glob (nil for substring matching)
+	dest   *regexp.Regexp // Compiled destination glob (nil for substring matching)
+}
+
+// newTaskFilter prepares the filters of q for matching.
+func newTaskFilter(q *TaskQuery) (*taskFilter, error) {
+	f := &taskFilter{q: q}
+	var err error
+	if isGlob(q.Source) {
+		if f.source, err = globToRegexp(q.Source); err != nil {
+			return nil, err
+		}
+	}
+	if isGlob(q.Dest) {
+		if f.dest, err = globToRegexp(q.Dest); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// match reports whether task satisfies all filters of the query.
+func (f *taskFilter) match(task *models.SyncTask) bool {
+	q := f.q
+	if q.Status != "" && task.Status != q.Status {
+		return false
+	}
+	if q.Owner != "" && task.Owner != q.Owner && task.OwnerEmail != q.Owner {
+		return false
+	}
+	if q.Finished && !task.Status.IsTerminal() {
+		return false
+	}
+	if q.Active && task.Status.IsTerminal() {
+		return false
+	}
+	if q.IdempotencyKey != "" && task.IdempotencyKey != q.IdempotencyKey {
+		return false
+	}
+	if q.JobID != "" && task.JobID != q.JobID {
+		return false
+	}
+	if q.ScheduleID != "" && task.ScheduleID != q.ScheduleID {
+		return false
+	}
+	if q.WatchID != "" && task.WatchID != q.WatchID {
+		return false
+	}
+	if q.HookID != "" && task.HookID != q.HookID {
+		return false
+	}
+	if !matchImage(task.SourceImage, q.Source, f.source) || !matchImage(task.DestImage, q.Dest, f.dest) {
+		return false
+	}
+	if q.Registry != "" && models.RegistryHost(task.SourceImage) != q.Registry &&
+		models.RegistryHost(task.DestImage) != q.Registry {
+		return false
+	}
+	if q.Architecture != "" && task.Architecture != q.Architecture {
+		return false
+	}
+	for _, selector := range q.Labels {
+		if !selector.Matches(task.Labels) {
+			return false
+		}
+	}
+	if !q.StartedAfter.IsZero() && task.StartTime.Before(q.StartedAfter) {
+		return false
+	}
+	if !q.StartedBefore.IsZero() && !task.StartTime.Before(q.StartedBefore) {
+		return false
+	}
+	if !q.EndedAfter.IsZero() && (task.EndTime == nil || task.EndTime.Before(q.EndedAfter)) {
+		return false
+	}
+	if !q.EndedBefore.IsZero() && (task.EndTime == nil || !task.EndTime.Before(q.EndedBefore)) {
+		return false
+	}
+	if q.MinDuration > 0 && (task.EndTime == nil ||
+		task.EndTime.UnixNano()-task.StartTime.UnixNano() < int64(q.MinDuration)) {
+		return false
+	}
+	return true
+}
+
+// matchImage matches an image name against a substring filter or compiled glob.
+func matchImage(image, filter string, glob *regexp.Regexp) bool {
+	if glob != nil {
+		return glob.MatchString(image)
+	}
+	return strings.Contains(image, filter)
+}
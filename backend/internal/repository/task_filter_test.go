@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+func TestTaskCursor_EncodeDecode(t *testing.T) {
+	task := models.NewSyncTask("test-id", "nginx:latest", "registry.example.com/nginx:latest", "all")
+	cursor := NewTaskCursor(task, SortBySourceImage, "asc")
+
+	decoded, err := DecodeTaskCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *decoded != *cursor {
+		t.Errorf("Expected %+v, got %+v", cursor, decoded)
+	}
+	if !decoded.Matches(SortBySourceImage, "asc") || decoded.Matches(SortBySourceImage, "desc") {
+		t.Error("Expected cursor to match only its own sort")
+	}
+	// Defaults are normalized, so an empty sort matches a start time descending cursor
+	if !NewTaskCursor(task, "", "").Matches(SortByStartTime, "desc") {
+		t.Error("Expected default sort to match startTime desc")
+	}
+
+	for _, s := range []string{"", "not base64!", "bm90IGpzb24"} {
+		if _, err := DecodeTaskCursor(s); err == nil {
+			t.Errorf("Expected error decoding %q", s)
+		}
+	}
+}
+
+func TestMatchImage(t *testing.T) {
+	tests := []struct {
+		image  string
+		filter string
+		want   bool
+	}{
+		{"registry.example.com/nginx:latest", "nginx", true},
+		{"registry.example.com/nginx:latest", "redis", false},
+		{"registry.example.com/nginx:latest", "*/nginx:*", true},
+		{"registry.example.com/nginx:latest", "nginx:*", false},
+		{"registry.example.com/nginx:1.25", "*:1.2[0-9]", true},
+		{"registry.example.com/nginx:1.25", "*:1.2?", true},
+		{"registry.example.com/nginx:1.25", "*:1.3?", false},
+	}
+
+	for _, tt := range tests {
+		glob := mustGlob(t, tt.filter)
+		if got := matchImage(tt.image, tt.filter, glob); got != tt.want {
+			t.Errorf("matchImage(%q, %q) = %v, expected %v", tt.image, tt.filter, got, tt.want)
+		}
+	}
+}
+
+func mustGlob(t *testing.T, filter string) *regexp.Regexp {
+	t.Helper()
+	if !isGlob(filter) {
+		return nil
+	}
+	re, err := globToRegexp(filter)
+	if err != nil {
+		t.Fatalf("Failed to compile glob %q: %v", filter, err)
+	}
+	return re
+}
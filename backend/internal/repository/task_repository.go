@@ -6,7 +6,9 @@ package repository
 
 import (
 	"errors"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/lazycatapps/image-sync/internal/models"
 )
@@ -23,6 +25,36 @@ type TaskRepository interface {
 	Update(task *models.SyncTask) error
 	Delete(id string) error
 	List() ([]*models.SyncTask, error)
+	Query(q *TaskQuery) ([]*models.SyncTask, int, error)
+}
+
+// TaskQuery describes a filtered, sorted and paginated task lookup.
+// Time ranges include their lower bound and exclude their upper bound.
+type TaskQuery struct {
+	Status         models.SyncStatus // Filter by status (optional)
+	Owner          string            // Filter by owner user ID or email (optional)
+	Finished       bool              // Only include tasks with a terminal status
+	Active         bool              // Only include pending and running tasks
+	Source         string            // Source image substring, or glob if it contains *, ? or [ (optional)
+	Dest           string            // Destination image substring or glob (optional)
+	Registry       string            // Source or destination registry host (optional)
+	Architecture   string            // Filter by architecture (optional)
+	Labels         []LabelSelector   // Only include tasks matching all label selectors (optional)
+	IdempotencyKey string            // Filter by the Idempotency-Key of the creating request (optional)
+	JobID          string            // Filter by batch job (optional)
+	ScheduleID     string            // Filter by schedule (optional)
+	WatchID        string            // Filter by watch (optional)
+	HookID         string            // Filter by registry hook (optional)
+	StartedAfter   time.Time         // Only include tasks started at or after this time (optional)
+	StartedBefore  time.Time         // Only include tasks started before this time (optional)
+	EndedAfter     time.Time         // Only include tasks that ended at or after this time (optional)
+	EndedBefore    time.Time         // Only include tasks that ended before this time (optional)
+	MinDuration    time.Duration     // Only include finished tasks that took at least this long (optional)
+	SortBy         string            // Sort field: startTime (default), endTime, duration, sourceImage or destImage
+	SortOrder      string            // Sort order: asc or desc (default)
+	After          *TaskCursor       // Only include tasks after this cursor in sort order (keyset pagination)
+	Offset         int               // Number of matching tasks to skip
+	Limit          int               // Maximum number of tasks to return (0 = no limit)
 }
 
 // InMemoryTaskRepository implements TaskRepository using in-memory storage.
@@ -95,4 +127,67 @@ func (r *InMemoryTaskRepository) List() ([]*models.SyncTask, error) {
 		tasks = append(tasks, task)
 	}
 	return tasks, nil
-}
\ No newline at end of file
+}
+
+// Query returns one page of tasks matching the query, plus the total number of matches.
+// The total ignores the cursor, so it is the same for every page.
+func (r *InMemoryTaskRepository) Query(q *TaskQuery) ([]*models.SyncTask, int, error) {
+	filter, err := newTaskFilter(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*models.SyncTask, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		if filter.match(task) {
+			matched = append(matched, task)
+		}
+	}
+
+	sortBy, sortOrder := normalizeSort(q.SortBy, q.SortOrder)
+	sortTasks(matched, sortBy, sortOrder)
+	total := len(matched)
+
+	if q.After != nil {
+		// Skip up to and including the cursor position
+		start := sort.Search(len(matched), func(i int) bool {
+			key := taskSortKey(matched[i], sortBy)
+			cmp := compareSortKeys(&key, q.After)
+			if sortOrder == "asc" {
+				return cmp > 0
+			}
+			return cmp < 0
+		})
+		matched = matched[start:]
+	}
+
+	start := q.Offset
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := len(matched)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+
+	return matched[start:end], total, nil
+}
+
+// sortTasks sorts tasks in-place by a normalized sort field, in ascending or descending order.
+// Tasks without an end time (still active) sort as the most recent and longest ones.
+// Ties are broken by task ID so that pagination is deterministic.
+func sortTasks(tasks []*models.SyncTask, sortBy, sortOrder string) {
+	desc := sortOrder != "asc"
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := taskSortKey(tasks[i], sortBy), taskSortKey(tasks[j], sortBy)
+		cmp := compareSortKeys(&a, &b)
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
@@ -4,7 +4,9 @@
 package repository
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/lazycatapps/image-sync/internal/models"
 )
@@ -94,4 +96,36 @@ func TestInMemoryTaskRepository_List(t *testing.T) {
 	if len(tasks) != 2 {
 		t.Errorf("Expected 2 tasks, got %d", len(tasks))
 	}
-}
\ No newline at end of file
+}
+func TestInMemoryTaskRepository_Query(t *testing.T) {
+	repo := NewInMemoryTaskRepository()
+	base := time.Now()
+
+	for i := 0; i < 5; i++ {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		task.StartTime = base.Add(time.Duration(i) * time.Minute)
+		if i%2 == 0 {
+			task.Status = models.StatusCompleted
+		}
+		repo.Create(task)
+	}
+
+	tasks, total, err := repo.Query(&TaskQuery{Status: models.StatusCompleted, SortOrder: "asc"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != 3 || len(tasks) != 3 {
+		t.Fatalf("Expected 3 completed tasks, got total=%d len=%d", total, len(tasks))
+	}
+	if tasks[0].ID != "id0" || tasks[2].ID != "id4" {
+		t.Errorf("Expected ascending start time order, got %s..%s", tasks[0].ID, tasks[2].ID)
+	}
+
+	tasks, total, _ = repo.Query(&TaskQuery{Offset: 1, Limit: 2})
+	if total != 5 || len(tasks) != 2 {
+		t.Fatalf("Expected page of 2 out of 5, got total=%d len=%d", total, len(tasks))
+	}
+	if tasks[0].ID != "id3" || tasks[1].ID != "id2" {
+		t.Errorf("Expected id3, id2 on second page (desc), got %s, %s", tasks[0].ID, tasks[1].ID)
+	}
+}
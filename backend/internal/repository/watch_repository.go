@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+var (
+	// ErrWatchNotFound is returned when a requested watch does not exist.
+	ErrWatchNotFound = errors.New("watch not found")
+)
+
+// WatchRepository defines the interface for watch persistence operations.
+// Watches are returned as copies, so callers may modify them and Save them back.
+type WatchRepository interface {
+	// Save creates the watch or replaces the one with the same ID.
+	Save(watch *models.Watch) error
+	Get(id string) (*models.Watch, error)
+	Delete(id string) error
+	// List returns the watches of an owner (user ID or email; all if empty), oldest first.
+	List(owner string) ([]*models.Watch, error)
+}
+
+// FileWatchRepository implements WatchRepository with a JSON file, next to the schedules.
+type FileWatchRepository struct {
+	*fileStore[models.Watch]
+}
+
+// NewFileWatchRepository loads the watches stored in path, which is created on
+// the first change if it does not exist.
+func NewFileWatchRepository(path string) (*FileWatchRepository, error) {
+	store, err := newFileStore(path, "watches", fileRecord[models.Watch]{
+		id:        func(w *models.Watch) string { return w.ID },
+		createdAt: func(w *models.Watch) time.Time { return w.CreatedAt },
+		owned: func(w *models.Watch, owner string) bool {
+			return w.Owner == owner || w.OwnerEmail == owner
+		},
+		clone: func(w *models.Watch) *models.Watch {
+			copied := *w
+			copied.Labels = models.CopyLabels(w.Labels)
+			copied.History = append([]models.DigestChange(nil), w.History...)
+			return &copied
+		},
+	}, ErrWatchNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &FileWatchRepository{store}, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+func TestFileWatchRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watches.json")
+	repo, err := NewFileWatchRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileWatchRepository failed: %v", err)
+	}
+
+	watch := &models.Watch{
+		ID:      "w1",
+		Owner:   "u1",
+		History: []models.DigestChange{{Digest: "sha256:aaa"}},
+	}
+	if err := repo.Save(watch); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// The history of returned watches is a copy
+	stored, _ := repo.Get("w1")
+	stored.History[0].Digest = "changed"
+	if stored, _ := repo.Get("w1"); stored.History[0].Digest != "sha256:aaa" {
+		t.Errorf("Expected stored history to be unchanged, got %q", stored.History[0].Digest)
+	}
+
+	reopened, err := NewFileWatchRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileWatchRepository failed: %v", err)
+	}
+	if watches, _ := reopened.List("u1"); len(watches) != 1 || len(watches[0].History) != 1 {
+		t.Errorf("Expected 1 reloaded watch with history, got %d", len(watches))
+	}
+	if _, err := reopened.Get("w2"); !errors.Is(err, ErrWatchNotFound) {
+		t.Errorf("Expected ErrWatchNotFound, got %v", err)
+	}
+}
@@ -15,21 +15,29 @@ import (
 // Router manages HTTP request routing and handler registration.
 // It holds references to all HTTP handlers (sync, image inspection, config, etc.).
 type Router struct {
-	syncHandler      *handler.SyncHandler
-	imageHandler     *handler.ImageHandler
-	configHandler    *handler.ConfigHandler
-	authHandler      *handler.AuthHandler
-	sessionValidator middleware.SessionValidator
+	syncHandler         *handler.SyncHandler
+	scheduleHandler     *handler.ScheduleHandler
+	watchHandler        *handler.WatchHandler
+	subscriptionHandler *handler.SubscriptionHandler
+	hookHandler         *handler.HookHandler
+	imageHandler        *handler.ImageHandler
+	configHandler       *handler.ConfigHandler
+	authHandler         *handler.AuthHandler
+	sessionValidator    middleware.SessionValidator
 }
 
 // New creates a new Router instance with the provided handlers.
-func New(syncHandler *handler.SyncHandler, imageHandler *handler.ImageHandler, configHandler *handler.ConfigHandler, authHandler *handler.AuthHandler, sessionValidator middleware.SessionValidator) *Router {
+func New(syncHandler *handler.SyncHandler, scheduleHandler *handler.ScheduleHandler, watchHandler *handler.WatchHandler, subscriptionHandler *handler.SubscriptionHandler, hookHandler *handler.HookHandler, imageHandler *handler.ImageHandler, configHandler *handler.ConfigHandler, authHandler *handler.AuthHandler, sessionValidator middleware.SessionValidator) *Router {
 	return &Router{
-		syncHandler:      syncHandler,
-		imageHandler:     imageHandler,
-		configHandler:    configHandler,
-		authHandler:      authHandler,
-		sessionValidator: sessionValidator,
+		syncHandler:         syncHandler,
+		scheduleHandler:     scheduleHandler,
+		watchHandler:        watchHandler,
+		subscriptionHandler: subscriptionHandler,
+		hookHandler:         hookHandler,
+		imageHandler:        imageHandler,
+		configHandler:       configHandler,
+		authHandler:         authHandler,
+		sessionValidator:    sessionValidator,
 	}
 }
 
@@ -65,8 +73,49 @@ func (r *Router) Setup(cfg *types.Config) *gin.Engine {
 //   - GET    /auth/userinfo        - Get current user information
 //   - GET    /sync                 - List sync tasks with pagination and filtering
 //   - POST   /sync                 - Create a new sync task
+//   - DELETE /sync                 - Delete finished tasks matching status/age filters
+//   - POST   /sync/plan            - Report what a sync would do without running it
 //   - GET    /sync/:id             - Get sync task status and details
+//   - PATCH  /sync/:id             - Edit the labels and note of a sync task
+//   - DELETE /sync/:id             - Delete a finished sync task
 //   - GET    /sync/:id/logs        - Stream sync task logs via SSE
+//   - POST   /sync/:id/cancel      - Cancel a pending or running sync task
+//   - POST   /sync/:id/retry       - Re-run a failed, cancelled, interrupted or partial task as a new task
+//   - POST   /sync/:id/clone       - Create a new task from an existing one with overrides
+//   - GET    /jobs                 - List batch jobs with aggregated status
+//   - POST   /jobs                 - Create a batch job with one sync task per source/destination pair
+//   - GET    /jobs/:id             - Get batch job status, counts and tasks
+//   - POST   /jobs/:id/cancel      - Cancel the pending and running tasks of a batch job
+//   - POST   /jobs/:id/retry       - Re-run the failed, cancelled and interrupted items of a batch job
+//   - POST   /repo-sync            - Sync the tags of a repository matching a tag filter as a batch job
+//   - POST   /repo-sync/preview    - List the tags a repository sync would copy
+//   - GET    /schedules            - List schedules with their last and next run times
+//   - POST   /schedules            - Create a schedule running a sync by cron expression
+//   - GET    /schedules/:id        - Get a schedule
+//   - PUT    /schedules/:id        - Replace the definition of a schedule
+//   - DELETE /schedules/:id        - Delete a schedule
+//   - POST   /schedules/:id/enable - Enable a schedule (POST /schedules/:id/disable disables it)
+//   - GET    /watches              - List watches with their last check and digest history
+//   - POST   /watches              - Create a watch syncing a source tag whenever its digest changes
+//   - GET    /watches/:id          - Get a watch
+//   - PUT    /watches/:id          - Replace the definition of a watch
+//   - DELETE /watches/:id          - Delete a watch
+//   - POST   /watches/:id/enable   - Enable a watch (POST /watches/:id/disable disables it)
+//   - GET    /subscriptions        - List tag subscriptions with their discoveries
+//   - POST   /subscriptions        - Create a subscription mirroring new matching tags of a repository
+//   - GET    /subscriptions/:id    - Get a subscription
+//   - PUT    /subscriptions/:id    - Replace the definition of a subscription
+//   - DELETE /subscriptions/:id    - Delete a subscription
+//   - POST   /subscriptions/:id/enable - Enable a subscription (POST /subscriptions/:id/disable disables it)
+//   - GET    /hooks                - List registry hooks with the pushes they received
+//   - POST   /hooks                - Create a hook syncing the tags pushed to a registry by rules
+//   - GET    /hooks/:id            - Get a hook
+//   - PUT    /hooks/:id            - Replace the definition of a hook
+//   - DELETE /hooks/:id            - Delete a hook
+//   - POST   /hooks/:id/enable     - Enable a hook (POST /hooks/:id/disable disables it)
+//   - POST   /hooks/registry/:hookId - Receive a registry push notification (authenticated by the hook secret)
+//   - GET    /events               - Stream lifecycle events of all visible tasks via SSE
+//   - GET    /stats                - Task statistics and time series over a time window
 //   - GET    /env/defaults         - Get default registry configuration
 //   - POST   /inspect              - Inspect image and list available architectures
 //   - GET    /configs              - List all saved configuration names
@@ -80,6 +129,9 @@ func (r *Router) registerRoutes(engine *gin.Engine) {
 		// Public endpoints (no auth required)
 		api.GET("/health", r.syncHandler.Health)
 
+		// Registry notifications, authenticated by the secret of the hook instead of a session
+		api.POST("/hooks/registry/:hookId", r.hookHandler.ReceiveRegistryHook)
+
 		// Auth endpoints
 		auth := api.Group("/auth")
 		{
@@ -92,8 +144,52 @@ func (r *Router) registerRoutes(engine *gin.Engine) {
 		// Protected endpoints (require auth if OIDC enabled)
 		api.GET("/sync", r.syncHandler.ListTasks)
 		api.POST("/sync", r.syncHandler.SyncImage)
+		api.DELETE("/sync", r.syncHandler.DeleteTasks)
+		api.POST("/sync/plan", r.syncHandler.PlanSync)
 		api.GET("/sync/:id", r.syncHandler.GetSyncStatus)
+		api.PATCH("/sync/:id", r.syncHandler.UpdateSync)
+		api.DELETE("/sync/:id", r.syncHandler.DeleteSync)
 		api.GET("/sync/:id/logs", r.syncHandler.StreamLogs)
+		api.POST("/sync/:id/cancel", r.syncHandler.CancelSync)
+		api.POST("/sync/:id/retry", r.syncHandler.RetrySync)
+		api.POST("/sync/:id/clone", r.syncHandler.CloneSync)
+		api.GET("/jobs", r.syncHandler.ListJobs)
+		api.POST("/jobs", r.syncHandler.CreateJob)
+		api.GET("/jobs/:id", r.syncHandler.GetJob)
+		api.POST("/jobs/:id/cancel", r.syncHandler.CancelJob)
+		api.POST("/jobs/:id/retry", r.syncHandler.RetryJob)
+		api.POST("/repo-sync", r.syncHandler.SyncRepository)
+		api.POST("/repo-sync/preview", r.syncHandler.PreviewRepoSync)
+		api.GET("/schedules", r.scheduleHandler.ListSchedules)
+		api.POST("/schedules", r.scheduleHandler.CreateSchedule)
+		api.GET("/schedules/:id", r.scheduleHandler.GetSchedule)
+		api.PUT("/schedules/:id", r.scheduleHandler.UpdateSchedule)
+		api.DELETE("/schedules/:id", r.scheduleHandler.DeleteSchedule)
+		api.POST("/schedules/:id/enable", r.scheduleHandler.EnableSchedule)
+		api.POST("/schedules/:id/disable", r.scheduleHandler.DisableSchedule)
+		api.GET("/watches", r.watchHandler.ListWatches)
+		api.POST("/watches", r.watchHandler.CreateWatch)
+		api.GET("/watches/:id", r.watchHandler.GetWatch)
+		api.PUT("/watches/:id", r.watchHandler.UpdateWatch)
+		api.DELETE("/watches/:id", r.watchHandler.DeleteWatch)
+		api.POST("/watches/:id/enable", r.watchHandler.EnableWatch)
+		api.POST("/watches/:id/disable", r.watchHandler.DisableWatch)
+		api.GET("/subscriptions", r.subscriptionHandler.ListSubscriptions)
+		api.POST("/subscriptions", r.subscriptionHandler.CreateSubscription)
+		api.GET("/subscriptions/:id", r.subscriptionHandler.GetSubscription)
+		api.PUT("/subscriptions/:id", r.subscriptionHandler.UpdateSubscription)
+		api.DELETE("/subscriptions/:id", r.subscriptionHandler.DeleteSubscription)
+		api.POST("/subscriptions/:id/enable", r.subscriptionHandler.EnableSubscription)
+		api.POST("/subscriptions/:id/disable", r.subscriptionHandler.DisableSubscription)
+		api.GET("/hooks", r.hookHandler.ListHooks)
+		api.POST("/hooks", r.hookHandler.CreateHook)
+		api.GET("/hooks/:id", r.hookHandler.GetHook)
+		api.PUT("/hooks/:id", r.hookHandler.UpdateHook)
+		api.DELETE("/hooks/:id", r.hookHandler.DeleteHook)
+		api.POST("/hooks/:id/enable", r.hookHandler.EnableHook)
+		api.POST("/hooks/:id/disable", r.hookHandler.DisableHook)
+		api.GET("/events", r.syncHandler.StreamEvents)
+		api.GET("/stats", r.syncHandler.GetStats)
 		api.GET("/env/defaults", r.syncHandler.GetEnvDefaults)
 		api.POST("/inspect", r.imageHandler.InspectImage)
 
@@ -104,4 +200,4 @@ func (r *Router) registerRoutes(engine *gin.Engine) {
 		api.POST("/config/:name", r.configHandler.SaveConfig)
 		api.DELETE("/config/:name", r.configHandler.DeleteConfig)
 	}
-}
\ No newline at end of file
+}
@@ -60,6 +60,19 @@ func NewConfigService(configDir string, allowPasswordSave bool, maxConfigSize, m
 	return service
 }
 
+// UserIdentifier returns the identifier whose config directory holds the configs of a user,
+// or "" (the shared directory) if OIDC is disabled. Background work on behalf of a user,
+// such as scheduled syncs, uses it to find the configs the user saved.
+//
+// Current implementation: Uses the email for better readability in file system.
+// Change the return line below if you want to use a different identifier.
+func UserIdentifier(userID, email string) string {
+	if userID == "" {
+		return ""
+	}
+	return email + "_" + userID // Could also be: userID, username, etc.
+}
+
 // getUserConfigDir returns the config directory for a specific user
 // If userIdentifier is empty, returns the shared config directory
 // userIdentifier can be email, userID, username, etc. - comes from handler layer
@@ -177,6 +190,46 @@ func (s *ConfigService) GetConfig(userIdentifier, name string) (*models.UserConf
 	return &config, nil
 }
 
+// ApplyCredentials fills in the registry credentials of a sync request from a saved config.
+// Only sides without credentials in the request are filled, and only if the config
+// holds both a username and a password for that side (passwords are only saved when
+// allowPasswordSave is enabled). Credentials are decoded from their base64 form.
+func (s *ConfigService) ApplyCredentials(userIdentifier, name string, req *models.SyncRequest) error {
+	if err := validator.ValidateConfigName(name); err != nil {
+		return errors.NewInvalidInput(err.Error())
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(s.getConfigPath(userIdentifier, name))
+	if os.IsNotExist(err) {
+		return errors.NewInvalidInput(fmt.Sprintf("Config '%s' not found", name))
+	}
+	if err != nil {
+		s.logger.Error("Failed to read config file %s: %v", name, err)
+		return errors.WrapInternal(err, "Failed to read config file")
+	}
+
+	var config models.UserConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		s.logger.Error("Failed to parse config file %s: %v", name, err)
+		return errors.WrapInternal(err, "Failed to parse config file")
+	}
+
+	if req.SourceUsername == "" && config.SourceUsername != "" && config.SourcePassword != "" {
+		req.SourceUsername = decodeFromBase64(config.SourceUsername)
+		req.SourcePassword = decodeFromBase64(config.SourcePassword)
+	}
+	if req.DestUsername == "" && config.DestUsername != "" && config.DestPassword != "" {
+		req.DestUsername = decodeFromBase64(config.DestUsername)
+		req.DestPassword = decodeFromBase64(config.DestPassword)
+	}
+
+	s.logger.Info("Credentials applied from config '%s' for user %s", name, userIdentifier)
+	return nil
+}
+
 // SaveConfig saves a configuration with the given name for a user
 func (s *ConfigService) SaveConfig(userIdentifier, name string, config *models.UserConfig) error {
 	// Validate config name
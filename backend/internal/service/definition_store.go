@@ -0,0 +1,363 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/pkg/timeutil"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxCheckBackoff  = 6 * time.Hour    // Longest delay between failing checks (unless the interval is longer)
+	rateLimitBackoff = 15 * time.Minute // Shortest delay after a check was rate limited
+)
+
+// definition is implemented by the stored definitions, such as *models.Schedule.
+type definition interface {
+	GetID() string
+	IsEnabled() bool
+	SetEnabled(enabled bool)
+	GetUpdatedAt() time.Time
+	SetCreated(id string, at time.Time)
+	SetUpdated(at time.Time)
+}
+
+// checkedDefinition is implemented by the definitions that are checked periodically,
+// such as *models.Watch.
+type checkedDefinition interface {
+	definition
+	GetChecks() *models.Checks
+}
+
+// definitionRepository stores the definitions of one kind, such as
+// repository.ScheduleRepository. Definitions are returned as copies.
+type definitionRepository[D definition] interface {
+	Save(def D) error
+	Get(id string) (D, error)
+	Delete(id string) error
+	List(owner string) ([]D, error)
+}
+
+// definitionKind describes how a definitionStore validates and logs its definitions.
+type definitionKind[D definition] struct {
+	name   string // Singular name for logs and errors, e.g. "schedule"
+	plural string
+	// prepare validates a definition and sets its derived fields before it is saved.
+	// previous is the stored definition for an update, and nil for a new definition.
+	prepare  func(def, previous D, now time.Time) error
+	describe func(def D) string // Summary for the log
+	checks   checkPolicy        // How often the definitions are checked, if they are
+}
+
+// definitionStore keeps the definitions of one kind, such as schedules or hooks, and
+// wakes up the loop acting on them, if the service has one, after every change.
+// Services embed it to manage their definitions.
+type definitionStore[D definition] struct {
+	repo   definitionRepository[D]
+	kind   definitionKind[D]
+	logger logger.Logger
+
+	mu   sync.Mutex    // Serializes changes and the recording of results, so that a result never overwrites a concurrent update
+	wake chan struct{} // Signals run to recompute its sleep after a change
+	now  func() time.Time
+}
+
+// newDefinitionStore creates a store for the definitions in repo.
+func newDefinitionStore[D definition](repo definitionRepository[D], kind definitionKind[D], logger logger.Logger) *definitionStore[D] {
+	return &definitionStore[D]{
+		repo:   repo,
+		kind:   kind,
+		logger: logger,
+		wake:   make(chan struct{}, 1),
+		now:    time.Now,
+	}
+}
+
+// Create validates a new definition, assigns its ID and stores it.
+// Returns the error of the validation if the definition is malformed.
+func (s *definitionStore[D]) Create(def D) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	def.SetCreated(uuid.New().String(), now)
+	var none D // A new definition has no previous state
+	if err := s.kind.prepare(def, none, now); err != nil {
+		return err
+	}
+	if err := s.repo.Save(def); err != nil {
+		return fmt.Errorf("failed to save %s: %w", s.kind.name, err)
+	}
+
+	s.logger.Info("[%s %s] Created: %s", s.kind.name, def.GetID(), s.kind.describe(def))
+	s.notify()
+	return nil
+}
+
+// Get retrieves a definition by ID.
+func (s *definitionStore[D]) Get(id string) (D, error) {
+	return s.repo.Get(id)
+}
+
+// List returns the definitions of an owner (user ID or email; all if empty), oldest first.
+func (s *definitionStore[D]) List(owner string) ([]D, error) {
+	return s.repo.List(owner)
+}
+
+// Update changes an existing definition with apply, which is called with the stored
+// definition and may reject the change by returning an error.
+// Returns the not found error of the repository if the definition does not exist, the
+// error of apply, or the error of the validation if the result is malformed.
+func (s *definitionStore[D]) Update(id string, apply func(def D) error) (D, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var none D
+	def, err := s.repo.Get(id)
+	if err != nil {
+		return none, err
+	}
+	// The repository returns copies, so previous keeps the stored state
+	previous, err := s.repo.Get(id)
+	if err != nil {
+		return none, err
+	}
+	if err := apply(def); err != nil {
+		return none, err
+	}
+
+	now := s.now()
+	def.SetUpdated(now)
+	if err := s.kind.prepare(def, previous, now); err != nil {
+		return none, err
+	}
+	if err := s.repo.Save(def); err != nil {
+		return none, fmt.Errorf("failed to save %s: %w", s.kind.name, err)
+	}
+
+	s.logger.Info("[%s %s] Updated: %s, enabled: %v", s.kind.name, id, s.kind.describe(def), def.IsEnabled())
+	s.notify()
+	return def, nil
+}
+
+// SetEnabled enables or disables a definition.
+// Returns the not found error of the repository if the definition does not exist.
+func (s *definitionStore[D]) SetEnabled(id string, enabled bool) (D, error) {
+	return s.Update(id, func(def D) error {
+		def.SetEnabled(enabled)
+		return nil
+	})
+}
+
+// Delete removes a definition. Tasks and jobs it created are kept.
+// Returns the not found error of the repository if the definition does not exist.
+func (s *definitionStore[D]) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.logger.Info("[%s %s] Deleted", s.kind.name, id)
+	s.notify()
+	return nil
+}
+
+// run calls due until ctx is done: right away, after every change, and otherwise at the
+// time due returned.
+func (s *definitionStore[D]) run(ctx context.Context, due func(ctx context.Context) time.Time) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-s.wake:
+		case <-ctx.Done():
+			return
+		}
+
+		wakeAt := due(ctx)
+		timer.Reset(time.Until(wakeAt))
+	}
+}
+
+// notify wakes up run to pick up a changed definition.
+func (s *definitionStore[D]) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// checkPolicy sets how often the definitions of a kind, such as watches, are checked.
+type checkPolicy struct {
+	defaultInterval string        // Interval of definitions that do not set one
+	minInterval     time.Duration // Shortest allowed interval
+	maxSleep        time.Duration // Longest time between two passes over the definitions
+}
+
+// checkDue checks the enabled definitions of s whose next check is due, one at a time,
+// and returns when run should check again. check performs the check without holding
+// s.mu and returns the function that records its result, which is applied by record.
+func checkDue[D checkedDefinition](ctx context.Context, s *definitionStore[D], check func(ctx context.Context, def D) func(stored D)) time.Time {
+	s.mu.Lock()
+	defs, err := s.repo.List("")
+	now := s.now()
+	s.mu.Unlock()
+
+	wakeAt := now.Add(s.kind.checks.maxSleep)
+	if err != nil {
+		s.logger.Error("Failed to list %s: %v", s.kind.plural, err)
+		return wakeAt
+	}
+
+	for _, def := range defs {
+		next := def.GetChecks().NextCheckAt
+		if !def.IsEnabled() || next == nil {
+			continue
+		}
+		if next.After(now) {
+			wakeAt = earliest(wakeAt, *next)
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		apply := check(ctx, def)
+		if ctx.Err() != nil {
+			// Shutting down; the check is repeated after the restart
+			break
+		}
+		if updated, ok := s.record(def, apply); ok {
+			if next := updated.GetChecks().NextCheckAt; next != nil {
+				wakeAt = earliest(wakeAt, *next)
+			}
+		}
+	}
+	return wakeAt
+}
+
+// record applies the result of a check to the stored definition and saves it. The
+// result is dropped if the definition was changed, disabled or deleted while it was checked.
+// Returns the saved definition, and false if the result was dropped.
+func (s *definitionStore[D]) record(checked D, apply func(stored D)) (D, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	def, err := s.repo.Get(checked.GetID())
+	if err != nil || !def.GetUpdatedAt().Equal(checked.GetUpdatedAt()) || !def.IsEnabled() {
+		var none D
+		return none, false
+	}
+	apply(def)
+	if err := s.repo.Save(def); err != nil {
+		s.logger.Error("[%s %s] Failed to save %s: %v", s.kind.name, def.GetID(), s.kind.name, err)
+	}
+	return def, true
+}
+
+// earliest returns the earlier of two times.
+func earliest(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// interval parses the interval between the checks of a definition.
+// Returns an error if it is malformed or shorter than the minimum of the policy.
+func (p checkPolicy) interval(s string) (time.Duration, error) {
+	interval, err := timeutil.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if interval < p.minInterval {
+		return 0, fmt.Errorf("interval must be at least %s", strings.TrimSuffix(p.minInterval.String(), "0s"))
+	}
+	return interval, nil
+}
+
+// prepare defaults the interval of a periodically checked definition, validates it and
+// sets the next check time: now if reset is set or it was never checked, otherwise one
+// interval after the last check, or unchanged while checks are backed off.
+// Returns an error if the interval is malformed.
+func (p checkPolicy) prepare(checks *models.Checks, enabled bool, now time.Time, reset bool) error {
+	if checks.Interval == "" {
+		checks.Interval = p.defaultInterval
+	}
+	interval, err := p.interval(checks.Interval)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !enabled:
+		checks.NextCheckAt = nil
+	case reset || checks.LastCheckAt == nil:
+		checks.NextCheckAt = &now
+	case checks.Failures > 0 && checks.NextCheckAt != nil:
+		// Keep the backoff
+	default:
+		next := checks.LastCheckAt.Add(interval)
+		if next.Before(now) {
+			next = now
+		}
+		checks.NextCheckAt = &next
+	}
+	return nil
+}
+
+// record records the time and outcome of a check and sets the next check time: one
+// interval later, or backed off after a failed check.
+func (p checkPolicy) record(checks *models.Checks, now time.Time, checkErr error) {
+	checks.LastCheckAt = &now
+	interval, err := p.interval(checks.Interval)
+	if err != nil {
+		// Only possible for a hand-edited definitions file
+		interval = p.minInterval
+	}
+
+	if checkErr != nil {
+		checks.Failures++
+		checks.RateLimited = errors.Is(checkErr, ErrRateLimited)
+		checks.LastError = checkErr.Error()
+		next := now.Add(checkBackoff(interval, checks.Failures, checks.RateLimited))
+		checks.NextCheckAt = &next
+		return
+	}
+
+	checks.Failures = 0
+	checks.RateLimited = false
+	checks.LastError = ""
+	next := now.Add(interval)
+	checks.NextCheckAt = &next
+}
+
+// checkBackoff returns the delay before the next check after the given number of
+// consecutive failures: the interval, doubled for every further failure, and at least
+// rateLimitBackoff if the registry rate limited the check.
+func checkBackoff(interval time.Duration, failures int, rateLimited bool) time.Duration {
+	d := interval
+	if rateLimited && d < rateLimitBackoff {
+		d = rateLimitBackoff
+	}
+	for i := 1; i < failures && d < maxCheckBackoff; i++ {
+		d *= 2
+	}
+	if d > maxCheckBackoff {
+		d = max(maxCheckBackoff, interval)
+	}
+	return d
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+// newTestService sets up a service of definitions under test. newService creates the
+// service and returns it with its store, given a sync service and a temporary directory
+// for its repository. Syncs are never started, so tasks stay pending until they are
+// cancelled. The clock of the store starts at 2025-03-14 10:00 UTC and is set by the
+// returned function.
+func newTestService[S any, D definition](t *testing.T, newService func(svc *syncService, dir string) (S, *definitionStore[D], error)) (S, *syncService, func(time.Time)) {
+	t.Helper()
+	svc := NewSyncService(repository.NewInMemoryTaskRepository(), repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour).(*syncService)
+	svc.queue.maxConcurrent = 0
+
+	service, store, err := newService(svc, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	now := time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	return service, svc, func(t time.Time) { now = t }
+}
+
+func TestDefinitionStore_Update(t *testing.T) {
+	scheduler, _, setNow := newTestScheduler(t)
+
+	schedule := newTestSchedule("0 2 * * *", "")
+	if err := scheduler.Create(schedule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	created := schedule.CreatedAt
+
+	setNow(created.Add(time.Minute))
+	rejected := errors.New("rejected")
+	if _, err := scheduler.Update(schedule.ID, func(*models.Schedule) error { return rejected }); !errors.Is(err, rejected) {
+		t.Errorf("Expected the error of apply, got %v", err)
+	}
+	updated, err := scheduler.SetEnabled(schedule.ID, false)
+	if err != nil {
+		t.Fatalf("SetEnabled failed: %v", err)
+	}
+	if updated.Enabled || !updated.CreatedAt.Equal(created) || !updated.UpdatedAt.Equal(created.Add(time.Minute)) {
+		t.Errorf("Expected disabled schedule updated at %s, got enabled %v, updated at %s", created.Add(time.Minute), updated.Enabled, updated.UpdatedAt)
+	}
+	if _, err := scheduler.SetEnabled("missing", true); !errors.Is(err, repository.ErrScheduleNotFound) {
+		t.Errorf("Expected ErrScheduleNotFound, got %v", err)
+	}
+
+	schedules, _ := scheduler.List("u2")
+	if len(schedules) != 0 {
+		t.Errorf("Expected no schedules of another owner, got %d", len(schedules))
+	}
+	if err := scheduler.Delete(schedule.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := scheduler.Get(schedule.ID); !errors.Is(err, repository.ErrScheduleNotFound) {
+		t.Errorf("Expected ErrScheduleNotFound after Delete, got %v", err)
+	}
+}
+
+func TestDefinitionStore_Record(t *testing.T) {
+	watcher, _, _, setNow := newTestWatcher(t)
+
+	watch := newTestWatch("docker.io/library/nginx:stable", "")
+	if err := watcher.Create(watch); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	checked, _ := watcher.Get(watch.ID)
+	setError := func(stored *models.Watch) { stored.LastError = "checked" }
+
+	// A watch changed while it was checked keeps its state
+	setNow(watch.CreatedAt.Add(time.Minute))
+	if _, err := watcher.Update(watch.ID, func(w *models.Watch) error { w.Name = "renamed"; return nil }); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated, ok := watcher.record(checked, setError); ok {
+		t.Errorf("Expected the result for a changed watch to be dropped, got %+v", updated)
+	}
+
+	checked, _ = watcher.Get(watch.ID)
+	if updated, ok := watcher.record(checked, setError); !ok || updated.LastError != "checked" {
+		t.Errorf("Expected the result to be recorded, got %+v", updated)
+	}
+	if stored, _ := watcher.Get(watch.ID); stored.LastError != "checked" {
+		t.Errorf("Expected the result to be saved, got %q", stored.LastError)
+	}
+
+	watcher.Delete(watch.ID)
+	if updated, ok := watcher.record(checked, setError); ok {
+		t.Errorf("Expected the result for a deleted watch to be dropped, got %+v", updated)
+	}
+}
+
+func TestCheckBackoff(t *testing.T) {
+	tests := []struct {
+		interval    time.Duration
+		failures    int
+		rateLimited bool
+		want        time.Duration
+	}{
+		{10 * time.Minute, 1, false, 10 * time.Minute},
+		{10 * time.Minute, 3, false, 40 * time.Minute},
+		{10 * time.Minute, 1, true, 15 * time.Minute},
+		{time.Hour, 10, false, 6 * time.Hour},
+		{24 * time.Hour, 3, false, 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := checkBackoff(tt.interval, tt.failures, tt.rateLimited); got != tt.want {
+			t.Errorf("checkBackoff(%v, %d, %v) = %v, want %v", tt.interval, tt.failures, tt.rateLimited, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+const (
+	defaultSubscriptionInterval = "1h"
+	minSubscriptionInterval     = 5 * time.Minute // Keeps subscriptions from using up registry quotas
+	maxDiscoveredTags           = 100             // Tags mirrored per check; the rest are picked up by the next checks
+	discoveryHistLimit          = 50              // Discoveries kept per subscription
+	discovererMaxSleep          = time.Minute
+)
+
+// subscriptionChecks sets how often subscriptions are checked.
+var subscriptionChecks = checkPolicy{defaultInterval: defaultSubscriptionInterval, minInterval: minSubscriptionInterval, maxSleep: discovererMaxSleep}
+
+// ErrInvalidSubscription is returned when a subscription has a malformed interval or tag filter.
+var ErrInvalidSubscription = errors.New("invalid subscription")
+
+// Discoverer stores tag subscriptions, lists the tags of their source repositories when
+// due and creates a batch job mirroring the matching tags the destination does not have.
+// Subscriptions are managed with the methods of definitionStore: Create and Update
+// return an error wrapping ErrInvalidSubscription if a subscription is malformed, and
+// Get, Update, SetEnabled and Delete return repository.ErrSubscriptionNotFound for
+// unknown IDs. A new subscription is checked right away, which mirrors all matching tags
+// the destination does not have yet. An updated subscription keeps its discoveries, and
+// is checked right away if its repositories or tag filter changed.
+type Discoverer struct {
+	*definitionStore[*models.Subscription]
+	syncService   SyncService
+	configService *ConfigService // Resolves the credentials of the saved config a subscription references
+	// listTags returns the tags of a repository; username and password may be empty
+	listTags func(ctx context.Context, repository string, tlsVerify bool, username, password string) ([]string, error)
+}
+
+// NewDiscoverer creates a discoverer for the subscriptions in repo.
+func NewDiscoverer(syncService SyncService, configService *ConfigService, repo repository.SubscriptionRepository, logger logger.Logger) *Discoverer {
+	d := &Discoverer{
+		definitionStore: newDefinitionStore(repo, definitionKind[*models.Subscription]{
+			name:    "subscription",
+			plural:  "subscriptions",
+			prepare: prepareSubscription,
+			describe: func(s *models.Subscription) string {
+				return fmt.Sprintf("%s -> %s every %s", s.SourceRepository, s.DestRepository, s.Interval)
+			},
+			checks: subscriptionChecks,
+		}, logger),
+		syncService:   syncService,
+		configService: configService,
+	}
+	d.listTags = d.listRepositoryTags
+	return d
+}
+
+// Run checks due subscriptions until ctx is done. Subscriptions are checked one at a time.
+func (d *Discoverer) Run(ctx context.Context) {
+	d.run(ctx, d.checkDue)
+}
+
+// prepareSubscription validates a subscription, defaults its interval and sets its next
+// check time. A new subscription, or one whose repositories or tag filter changed, is
+// checked right away, with its failures reset.
+func prepareSubscription(subscription, previous *models.Subscription, now time.Time) error {
+	retarget := previous == nil || subscription.SourceRepository != previous.SourceRepository ||
+		subscription.DestRepository != previous.DestRepository || !sameTagFilter(&subscription.Tags, &previous.Tags)
+	if retarget {
+		subscription.Failures = 0
+		subscription.RateLimited = false
+	}
+	if err := subscriptionChecks.prepare(&subscription.Checks, subscription.Enabled, now, retarget); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	if _, err := filterTags(nil, &subscription.Tags); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	return nil
+}
+
+// sameTagFilter reports whether two tag filters select the same tags.
+func sameTagFilter(a, b *models.TagFilter) bool {
+	return slices.Equal(a.Include, b.Include) && slices.Equal(a.Exclude, b.Exclude) &&
+		a.Semver == b.Semver && a.Latest == b.Latest && a.Prerelease == b.Prerelease
+}
+
+// discoveryResult is the outcome of checking a subscription.
+type discoveryResult struct {
+	repoReq *models.RepoSyncRequest // The repository sync, with credentials
+	missing []string                // Matching tags the destination does not have
+	skipped string                  // Why the check was skipped, if it was
+	err     error
+}
+
+// checkDue checks all subscriptions that are due and returns when Run should check again.
+func (d *Discoverer) checkDue(ctx context.Context) time.Time {
+	return checkDue(ctx, d.definitionStore, func(ctx context.Context, subscription *models.Subscription) func(*models.Subscription) {
+		result := d.check(ctx, subscription)
+		return func(stored *models.Subscription) {
+			d.recordCheck(stored, result)
+		}
+	})
+}
+
+// check lists the source and destination tags of a subscription and returns the
+// matching tags missing at the destination. The check is skipped while the job of
+// the previous discovery is still in progress, so that its tags are not copied twice.
+func (d *Discoverer) check(ctx context.Context, subscription *models.Subscription) *discoveryResult {
+	if subscription.LastJobID != "" {
+		job, err := d.syncService.GetJob(subscription.LastJobID)
+		if err == nil && !job.Status.IsTerminal() {
+			return &discoveryResult{skipped: fmt.Sprintf("Previous discovery job %s was still in progress", job.ID)}
+		}
+	}
+
+	repoReq := subscription.RepoSyncRequest()
+	if repoReq.ConfigName != "" {
+		if d.configService == nil {
+			return &discoveryResult{err: fmt.Errorf("config '%s' is not available", repoReq.ConfigName)}
+		}
+		creds := repoReq.SourceRequest()
+		if err := d.configService.ApplyCredentials(UserIdentifier(subscription.Owner, subscription.OwnerEmail), repoReq.ConfigName, creds); err != nil {
+			return &discoveryResult{err: err}
+		}
+		repoReq.SourceUsername, repoReq.SourcePassword = creds.SourceUsername, creds.SourcePassword
+		repoReq.DestUsername, repoReq.DestPassword = creds.DestUsername, creds.DestPassword
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, listTagsTimeout)
+	defer cancel()
+
+	source, err := d.listTags(ctx, repoReq.SourceRepository, tlsVerifyOrDefault(repoReq.SrcTLSVerify),
+		repoReq.SourceUsername, repoReq.SourcePassword)
+	if err != nil {
+		return &discoveryResult{err: fmt.Errorf("failed to list tags of %s: %w", repoReq.SourceRepository, err)}
+	}
+	matched, err := filterTags(source, &repoReq.Tags)
+	if err != nil {
+		return &discoveryResult{err: err}
+	}
+	if len(matched) == 0 {
+		return &discoveryResult{repoReq: repoReq}
+	}
+
+	dest, err := d.listTags(ctx, repoReq.DestRepository, tlsVerifyOrDefault(repoReq.DestTLSVerify),
+		repoReq.DestUsername, repoReq.DestPassword)
+	if err != nil && !errors.Is(err, ErrImageNotFound) {
+		return &discoveryResult{err: fmt.Errorf("failed to list tags of %s: %w", repoReq.DestRepository, err)}
+	}
+	mirrored := make(map[string]bool, len(dest))
+	for _, tag := range dest {
+		mirrored[tag] = true
+	}
+	var missing []string
+	for _, tag := range matched {
+		if !mirrored[tag] {
+			missing = append(missing, tag)
+		}
+	}
+	return &discoveryResult{repoReq: repoReq, missing: missing}
+}
+
+// recordCheck records the outcome of a check and creates the job mirroring the missing tags.
+// Must be called with d.mu held.
+func (d *Discoverer) recordCheck(subscription *models.Subscription, result *discoveryResult) {
+	now := d.now()
+	subscriptionChecks.record(&subscription.Checks, now, result.err)
+	if result.err != nil {
+		d.logger.Error("[subscription %s] Check failed (%d in a row), next check at %s: %v",
+			subscription.ID, subscription.Failures, subscription.NextCheckAt.Format(time.RFC3339), result.err)
+		return
+	}
+
+	subscription.LastError = result.skipped
+	if result.skipped != "" {
+		d.logger.Info("[subscription %s] Check skipped: %s", subscription.ID, result.skipped)
+		return
+	}
+	if len(result.missing) == 0 {
+		d.logger.Debug("[subscription %s] No new tags in %s", subscription.ID, subscription.SourceRepository)
+		return
+	}
+
+	tags := result.missing
+	if len(tags) > maxDiscoveredTags {
+		tags = tags[:maxDiscoveredTags]
+	}
+	jobID, err := d.startJob(subscription, result.repoReq, tags)
+	if jobID == "" {
+		// The tags are still missing at the destination, so the next check finds them again
+		subscription.LastError = fmt.Sprintf("Found %d new tag(s) but the sync could not be started: %v", len(tags), err)
+		d.logger.Error("[subscription %s] Failed to start sync of %d new tag(s): %v", subscription.ID, len(tags), err)
+		return
+	}
+
+	// A created job is recorded even if it could not be started, so that its tags are
+	// not mirrored twice while it is in progress
+	subscription.LastJobID = jobID
+	discovery := models.TagDiscovery{DiscoveredAt: now, Tags: tags, JobID: jobID}
+	subscription.Discoveries = append([]models.TagDiscovery{discovery}, subscription.Discoveries...)
+	if len(subscription.Discoveries) > discoveryHistLimit {
+		subscription.Discoveries = subscription.Discoveries[:discoveryHistLimit]
+	}
+	if err != nil {
+		subscription.LastError = fmt.Sprintf("Found %d new tag(s) but job %s could not be started: %v", len(tags), jobID, err)
+		d.logger.Error("[subscription %s] Failed to start job %s for %d new tag(s): %v", subscription.ID, jobID, len(tags), err)
+		return
+	}
+	d.logger.Info("[subscription %s] Found %d new tag(s) in %s, started job %s",
+		subscription.ID, len(tags), subscription.SourceRepository, jobID)
+}
+
+// startJob creates and enqueues the batch job copying tags, on behalf of the
+// subscription's owner, and returns its ID. If a task cannot be queued, the job is
+// cancelled as a whole and its ID is returned with the error, so that the next checks
+// find its tags again once it has finished.
+func (d *Discoverer) startJob(subscription *models.Subscription, repoReq *models.RepoSyncRequest, tags []string) (string, error) {
+	repoReq.Name = subscription.Name
+	if repoReq.Name == "" {
+		repoReq.Name = "New tags of " + subscription.SourceRepository
+	}
+	jobReq := repoReq.JobRequest(tags)
+	reqs, err := jobReq.SyncRequests()
+	if err != nil {
+		return "", err
+	}
+	for _, req := range reqs {
+		req.Owner = subscription.Owner
+		req.OwnerEmail = subscription.OwnerEmail
+	}
+
+	job := &models.SyncJob{
+		Name:       jobReq.Name,
+		Owner:      subscription.Owner,
+		OwnerEmail: subscription.OwnerEmail,
+		Labels:     models.CopyLabels(jobReq.Labels),
+		Note:       jobReq.Note,
+	}
+	if err := d.syncService.CreateJob(job, reqs); err != nil {
+		return "", err
+	}
+	for i, taskID := range job.TaskIDs {
+		if _, err := d.syncService.EnqueueTask(taskID, reqs[i]); err != nil {
+			// Tasks that are not queued would stay pending forever
+			if _, cancelErr := d.syncService.CancelJob(job.ID, ""); cancelErr != nil {
+				d.logger.Error("[subscription %s] Failed to cancel job %s: %v", subscription.ID, job.ID, cancelErr)
+			}
+			return job.ID, fmt.Errorf("failed to enqueue task %s: %w", taskID, err)
+		}
+	}
+	return job.ID, nil
+}
+
+// listRepositoryTags lists the tags of a repository with skopeo list-tags.
+func (d *Discoverer) listRepositoryTags(ctx context.Context, repository string, tlsVerify bool, username, password string) ([]string, error) {
+	authFile, err := createAuthFile(repository, username, password, "", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth file: %w", err)
+	}
+	if authFile != "" {
+		defer func() {
+			if err := os.Remove(authFile); err != nil {
+				d.logger.Error("Failed to remove auth file: %v", err)
+			}
+		}()
+	}
+	return listTags(ctx, repository, tlsVerify, authFile)
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+// newTestDiscoverer returns a discoverer that lists the tags in the returned map, by
+// repository, and whose clock is set by the returned function.
+func newTestDiscoverer(t *testing.T) (*Discoverer, *syncService, map[string][]string, func(time.Time)) {
+	t.Helper()
+	discoverer, svc, setNow := newTestService(t, func(svc *syncService, dir string) (*Discoverer, *definitionStore[*models.Subscription], error) {
+		repo, err := repository.NewFileSubscriptionRepository(filepath.Join(dir, "subscriptions.json"))
+		if err != nil {
+			return nil, nil, err
+		}
+		discoverer := NewDiscoverer(svc, nil, repo, logger.New())
+		return discoverer, discoverer.definitionStore, nil
+	})
+	tags := make(map[string][]string)
+	discoverer.listTags = func(_ context.Context, repository string, _ bool, _, _ string) ([]string, error) {
+		if repository == "docker.io/library/limited" {
+			return nil, ErrRateLimited
+		}
+		if _, ok := tags[repository]; !ok {
+			return nil, ErrImageNotFound
+		}
+		return tags[repository], nil
+	}
+	return discoverer, svc, tags, setNow
+}
+
+// unqueuedSyncService is a sync service whose tasks cannot be queued.
+type unqueuedSyncService struct {
+	SyncService
+}
+
+func (unqueuedSyncService) EnqueueTask(string, *models.SyncRequest) (int, error) {
+	return 0, ErrShuttingDown
+}
+
+func newTestSubscription(source, semver string) *models.Subscription {
+	subscription := &models.Subscription{Enabled: true, Owner: "u1", Checks: models.Checks{Interval: "30m"}}
+	subscription.SourceRepository = source
+	subscription.DestRepository = "registry.example.com/mirror/nginx"
+	subscription.Tags.Semver = semver
+	return subscription
+}
+
+func TestDiscoverer_Create(t *testing.T) {
+	discoverer, _, _, _ := newTestDiscoverer(t)
+
+	invalid := []*models.Subscription{
+		newTestSubscription("docker.io/library/nginx", ">=1 <"),
+		newTestSubscription("docker.io/library/nginx", ""),
+	}
+	invalid[1].Interval = "1m"
+	for _, subscription := range invalid {
+		if err := discoverer.Create(subscription); !errors.Is(err, ErrInvalidSubscription) {
+			t.Errorf("Expected ErrInvalidSubscription for %q every %q, got %v", subscription.Tags.Semver, subscription.Interval, err)
+		}
+	}
+
+	subscription := newTestSubscription("docker.io/library/nginx", ">=1.26")
+	subscription.Interval = ""
+	if err := discoverer.Create(subscription); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if subscription.Interval != "1h" || subscription.NextCheckAt == nil {
+		t.Errorf("Expected default interval 1h and a first check, got %q at %v", subscription.Interval, subscription.NextCheckAt)
+	}
+}
+
+func TestDiscoverer_Discover(t *testing.T) {
+	discoverer, svc, tags, setNow := newTestDiscoverer(t)
+	source := "docker.io/library/nginx"
+	dest := "registry.example.com/mirror/nginx"
+	tags[source] = []string{"1.26.0", "1.27.0", "1.27.1-rc.1", "stable", "1.25.0"}
+	tags[dest] = []string{"1.26.0"}
+
+	subscription := newTestSubscription(source, ">=1.26")
+	if err := discoverer.Create(subscription); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Only matching tags missing at the destination are mirrored
+	discoverer.checkDue(context.Background())
+	first, _ := discoverer.Get(subscription.ID)
+	if len(first.Discoveries) != 1 || first.LastJobID == "" {
+		t.Fatalf("Expected one discovery with a job, got %d (error %q)", len(first.Discoveries), first.LastError)
+	}
+	if got := first.Discoveries[0].Tags; len(got) != 1 || got[0] != "1.27.0" {
+		t.Errorf("Expected tag 1.27.0 to be discovered, got %v", got)
+	}
+	job, err := svc.GetJob(first.LastJobID)
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	task, _ := svc.GetTask(job.TaskIDs[0])
+	if task.SourceImage != source+":1.27.0" || task.DestImage != dest+":1.27.0" || task.Owner != "u1" || job.Owner != "u1" {
+		t.Errorf("Expected copy of 1.27.0 owned by u1, got %s -> %s owned by %q", task.SourceImage, task.DestImage, task.Owner)
+	}
+
+	// While the job is in progress, the next check is skipped
+	tags[source] = append(tags[source], "1.28.0")
+	setNow(time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC))
+	discoverer.checkDue(context.Background())
+	skipped, _ := discoverer.Get(subscription.ID)
+	if len(skipped.Discoveries) != 1 || skipped.LastError == "" {
+		t.Errorf("Expected skipped check, got %d discoveries (error %q)", len(skipped.Discoveries), skipped.LastError)
+	}
+
+	// Once it has finished, new tags are discovered; tags that were not mirrored are retried
+	if _, err := svc.CancelJob(first.LastJobID, "u1"); err != nil {
+		t.Fatalf("CancelJob failed: %v", err)
+	}
+	setNow(time.Date(2025, 3, 14, 11, 0, 0, 0, time.UTC))
+	discoverer.checkDue(context.Background())
+	second, _ := discoverer.Get(subscription.ID)
+	if len(second.Discoveries) != 2 || second.LastJobID == first.LastJobID || second.LastError != "" {
+		t.Fatalf("Expected second discovery, got %d (error %q)", len(second.Discoveries), second.LastError)
+	}
+	if got := second.Discoveries[0].Tags; len(got) != 2 || got[0] != "1.28.0" || got[1] != "1.27.0" {
+		t.Errorf("Expected tags 1.28.0 and 1.27.0, highest first, got %v", got)
+	}
+	if want := time.Date(2025, 3, 14, 11, 30, 0, 0, time.UTC); !second.NextCheckAt.Equal(want) {
+		t.Errorf("Expected next check at %v, got %v", want, second.NextCheckAt)
+	}
+}
+
+func TestDiscoverer_Failure(t *testing.T) {
+	discoverer, _, tags, _ := newTestDiscoverer(t)
+	tags["docker.io/library/nginx"] = []string{"1.27.0"}
+
+	// A destination repository that does not exist yet has no tags
+	created := newTestSubscription("docker.io/library/nginx", "")
+	limited := newTestSubscription("docker.io/library/limited", "")
+	for _, subscription := range []*models.Subscription{created, limited} {
+		if err := discoverer.Create(subscription); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	discoverer.checkDue(context.Background())
+	if stored, _ := discoverer.Get(created.ID); len(stored.Discoveries) != 1 || stored.Failures != 0 {
+		t.Errorf("Expected discovery into a new repository, got %d (error %q)", len(stored.Discoveries), stored.LastError)
+	}
+	stored, _ := discoverer.Get(limited.ID)
+	if stored.Failures != 1 || !stored.RateLimited {
+		t.Errorf("Expected rate limited failure, got %d failures (error %q)", stored.Failures, stored.LastError)
+	}
+	if want := time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC); !stored.NextCheckAt.Equal(want) {
+		t.Errorf("Expected next check at %v, got %v", want, stored.NextCheckAt)
+	}
+}
+
+func TestDiscoverer_JobNotStarted(t *testing.T) {
+	discoverer, svc, tags, setNow := newTestDiscoverer(t)
+	tags["docker.io/library/nginx"] = []string{"1.27.0"}
+	discoverer.syncService = unqueuedSyncService{svc}
+
+	subscription := newTestSubscription("docker.io/library/nginx", "")
+	if err := discoverer.Create(subscription); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// The created job is recorded and cancelled, so that it does not stay pending
+	discoverer.checkDue(context.Background())
+	first, _ := discoverer.Get(subscription.ID)
+	if len(first.Discoveries) != 1 || first.LastJobID == "" || first.Discoveries[0].JobID != first.LastJobID {
+		t.Fatalf("Expected a discovery with the created job, got %d (error %q)", len(first.Discoveries), first.LastError)
+	}
+	if first.LastError == "" {
+		t.Error("Expected the enqueue error to be recorded")
+	}
+	job, err := svc.GetJob(first.LastJobID)
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if job.Status != models.StatusCancelled {
+		t.Errorf("Expected cancelled job, got %s", job.Status)
+	}
+
+	// Its tags are discovered again at the next check
+	discoverer.syncService = svc
+	setNow(time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC))
+	discoverer.checkDue(context.Background())
+	second, _ := discoverer.Get(subscription.ID)
+	if len(second.Discoveries) != 2 || second.LastJobID == first.LastJobID || second.LastError != "" {
+		t.Errorf("Expected second discovery, got %d (error %q)", len(second.Discoveries), second.LastError)
+	}
+}
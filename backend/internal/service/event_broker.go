@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"sync"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+// eventBufferSize is the number of events buffered per subscriber.
+const eventBufferSize = 64
+
+// EventBroker broadcasts task lifecycle events to all subscribers.
+// Publishing never blocks: a subscriber that falls more than eventBufferSize events
+// behind is disconnected (its channel is closed), so that its client reconnects and
+// reloads the task list instead of silently missing events.
+type EventBroker struct {
+	mu          sync.Mutex
+	subscribers map[*EventSubscription]struct{}
+}
+
+// EventSubscription receives the events published after it was created.
+type EventSubscription struct {
+	broker *EventBroker
+	events chan *models.TaskEvent
+}
+
+// NewEventBroker creates an event broker without subscribers.
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		subscribers: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber. Close must be called when it is no longer needed.
+func (b *EventBroker) Subscribe() *EventSubscription {
+	sub := &EventSubscription{
+		broker: b,
+		events: make(chan *models.TaskEvent, eventBufferSize),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Publish sends an event to all subscribers.
+func (b *EventBroker) Publish(event *models.TaskEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			// Subscriber is too slow; drop it rather than block the sync
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Events returns the channel events are delivered on. It is closed when the
+// subscription is closed or the subscriber fell too far behind.
+func (s *EventSubscription) Events() <-chan *models.TaskEvent {
+	return s.events
+}
+
+// Close unregisters the subscription. It is safe to call more than once.
+func (s *EventSubscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	if _, ok := s.broker.subscribers[s]; ok {
+		delete(s.broker.subscribers, s)
+		close(s.events)
+	}
+}
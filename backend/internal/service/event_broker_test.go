@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+func TestEventBroker_PublishSubscribe(t *testing.T) {
+	broker := NewEventBroker()
+	sub1 := broker.Subscribe()
+	sub2 := broker.Subscribe()
+	defer sub2.Close()
+
+	task := models.NewSyncTask("test-id", "src", "dest", "all")
+	broker.Publish(models.NewTaskEvent(models.EventCreated, task))
+
+	for _, sub := range []*EventSubscription{sub1, sub2} {
+		select {
+		case event := <-sub.Events():
+			if event.Type != models.EventCreated || event.TaskID != "test-id" {
+				t.Errorf("Expected created event for test-id, got %s for %s", event.Type, event.TaskID)
+			}
+		default:
+			t.Error("Expected event to be delivered to every subscriber")
+		}
+	}
+
+	// A closed subscription no longer receives events
+	sub1.Close()
+	sub1.Close()
+	broker.Publish(models.NewTaskEvent(models.EventStarted, task))
+	if _, ok := <-sub1.Events(); ok {
+		t.Error("Expected closed subscription channel")
+	}
+	if event := <-sub2.Events(); event.Type != models.EventStarted {
+		t.Errorf("Expected started event, got %s", event.Type)
+	}
+}
+
+func TestEventBroker_DropsSlowSubscriber(t *testing.T) {
+	broker := NewEventBroker()
+	sub := broker.Subscribe()
+	defer sub.Close()
+
+	task := models.NewSyncTask("test-id", "src", "dest", "all")
+	for i := 0; i < eventBufferSize+1; i++ {
+		broker.Publish(models.NewTaskEvent(models.EventProgress, task))
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	if received != eventBufferSize {
+		t.Errorf("Expected %d buffered events before disconnect, got %d", eventBufferSize, received)
+	}
+}
+
+func TestSyncService_PublishesLifecycleEvents(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+	sub := service.Events().Subscribe()
+	defer sub.Close()
+
+	req := &models.SyncRequest{
+		SourceImage: "docker.io/library/nginx:latest",
+		DestImage:   "registry.example.com/nginx:latest",
+		Owner:       "user-1",
+		OwnerEmail:  "alice@example.com",
+	}
+	taskID, err := service.CreateSyncTask(req)
+	if err != nil {
+		t.Fatalf("CreateSyncTask failed: %v", err)
+	}
+	if err := service.CancelTask(taskID, "alice@example.com"); err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+
+	for _, expected := range []models.TaskEventType{models.EventCreated, models.EventCancelled} {
+		event := <-sub.Events()
+		if event.Type != expected || event.TaskID != taskID {
+			t.Errorf("Expected %s event for %s, got %s for %s", expected, taskID, event.Type, event.TaskID)
+		}
+		if !event.OwnedBy("user-1") || !event.OwnedBy("alice@example.com") || event.OwnedBy("bob@example.com") {
+			t.Errorf("Expected event owned by user-1/alice@example.com, got %s/%s", event.Owner, event.OwnerEmail)
+		}
+	}
+}
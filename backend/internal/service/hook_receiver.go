@@ -0,0 +1,375 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/pkg/validator"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+const (
+	maxHookRules   = 32 // Rules per hook
+	hookEventLimit = 50 // Received tag pushes kept per hook
+)
+
+var (
+	// ErrInvalidHook is returned when a hook has malformed rules or source registry.
+	ErrInvalidHook = errors.New("invalid hook")
+
+	// ErrHookUnauthorized is returned when a notification has neither the secret nor a
+	// valid signature of the hook.
+	ErrHookUnauthorized = errors.New("hook authentication failed")
+
+	// ErrHookDisabled is returned when an authenticated notification is received by a disabled hook.
+	ErrHookDisabled = errors.New("hook is disabled")
+
+	// ErrInvalidNotification is returned when a notification is neither a Docker
+	// Distribution notification envelope nor a Harbor webhook payload.
+	ErrInvalidNotification = errors.New("invalid registry notification")
+)
+
+// HookReceiver stores registry hooks and turns the push notifications they receive
+// into sync tasks, according to the rules of each hook. Hooks are managed with the
+// methods of definitionStore: Create and Update return an error wrapping ErrInvalidHook
+// if a hook is malformed, and Get, Update, SetEnabled and Delete return
+// repository.ErrHookNotFound for unknown IDs. A hook created without a secret gets a
+// generated one; an updated hook keeps the pushes it received.
+type HookReceiver struct {
+	*definitionStore[*models.Hook]
+	syncService   SyncService
+	configService *ConfigService // Resolves the credentials of the saved config a hook references
+}
+
+// NewHookReceiver creates a receiver for the hooks in repo.
+func NewHookReceiver(syncService SyncService, configService *ConfigService, repo repository.HookRepository, logger logger.Logger) *HookReceiver {
+	return &HookReceiver{
+		definitionStore: newDefinitionStore(repo, definitionKind[*models.Hook]{
+			name:    "hook",
+			plural:  "hooks",
+			prepare: prepareHook,
+			describe: func(h *models.Hook) string {
+				return fmt.Sprintf("%d rules", len(h.Rules))
+			},
+		}, logger),
+		syncService:   syncService,
+		configService: configService,
+	}
+}
+
+// Deliver processes a notification received by a hook: every pushed tag matching a rule
+// of the hook is synced to the destination of the first matching rule, on behalf of the
+// owner of the hook. The notification is authenticated by the authorization header,
+// which must hold the secret of the hook (optionally as a bearer token), or by the
+// signature header, which must hold the hex HMAC-SHA256 of the body keyed with the
+// secret, prefixed with "sha256=".
+// Returns repository.ErrHookNotFound, ErrHookUnauthorized, ErrHookDisabled, or an error
+// wrapping ErrInvalidNotification if the body cannot be parsed.
+func (r *HookReceiver) Deliver(id string, body []byte, authorization, signature string) (*models.HookDeliveryResponse, error) {
+	hook, err := r.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !verifyHookRequest(hook.Secret, body, authorization, signature) {
+		r.logger.Info("[hook %s] Rejected notification with invalid credentials", id)
+		return nil, ErrHookUnauthorized
+	}
+	if !hook.Enabled {
+		return nil, ErrHookDisabled
+	}
+	pushes, err := parsePushEvents(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNotification, err)
+	}
+
+	now := r.now()
+	resp := &models.HookDeliveryResponse{Events: make([]models.HookEvent, 0, len(pushes))}
+	for _, push := range pushes {
+		event := r.syncPush(hook, push, now)
+		if event.TaskID != "" && event.Error == "" {
+			resp.Created++
+		}
+		resp.Events = append(resp.Events, event)
+	}
+	r.recordDelivery(id, now, resp.Events)
+	return resp, nil
+}
+
+// syncPush starts the sync of a pushed tag and returns what was done about it.
+func (r *HookReceiver) syncPush(hook *models.Hook, push pushEvent, now time.Time) models.HookEvent {
+	registry := hook.SourceRegistry
+	if registry == "" {
+		registry = push.registry
+	}
+	event := models.HookEvent{ReceivedAt: now, Image: push.repository + ":" + push.tag}
+	if registry == "" {
+		event.Error = "The registry of the pushed image is unknown; set sourceRegistry"
+		return event
+	}
+	event.Image = registry + "/" + event.Image
+
+	dest, ok := hookDestination(hook.Rules, push.repository, push.tag)
+	if !ok {
+		event.Error = "No rule matches"
+		r.logger.Debug("[hook %s] %s pushed, no rule matches", hook.ID, event.Image)
+		return event
+	}
+	event.DestImage = dest
+	if err := validator.ValidateImageName(event.Image); err != nil {
+		event.Error = fmt.Sprintf("Invalid source image: %v", err)
+		return event
+	}
+	if err := validator.ValidateImageName(dest); err != nil {
+		event.Error = fmt.Sprintf("Invalid destination image: %v", err)
+		return event
+	}
+
+	req, err := ownerRequest(r.configService, hook.SyncDefinition(event.Image, dest), hook.Owner, hook.OwnerEmail)
+	var taskID string
+	if err == nil {
+		req.HookID = hook.ID
+		taskID, err = startSync(r.syncService, req)
+	}
+	var duplicate *DuplicateTaskError
+	switch {
+	case errors.As(err, &duplicate):
+		event.TaskID = duplicate.TaskID
+		event.Error = "Identical sync is still in progress"
+		r.logger.Info("[hook %s] %s pushed, identical sync %s is in progress", hook.ID, event.Image, duplicate.TaskID)
+	case err != nil:
+		event.Error = fmt.Sprintf("The sync could not be started: %v", err)
+		r.logger.Error("[hook %s] %s pushed, failed to start sync: %v", hook.ID, event.Image, err)
+	default:
+		event.TaskID = taskID
+		r.logger.Info("[hook %s] %s pushed, started task %s to %s", hook.ID, event.Image, taskID, dest)
+	}
+	return event
+}
+
+// recordDelivery stores the received pushes with the hook, unless it has been deleted in the meantime.
+func (r *HookReceiver) recordDelivery(id string, at time.Time, events []models.HookEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hook, err := r.repo.Get(id)
+	if err != nil {
+		return
+	}
+	hook.LastDeliveryAt = &at
+	received := slices.Clone(events)
+	slices.Reverse(received)
+	hook.Events = append(received, hook.Events...)
+	if len(hook.Events) > hookEventLimit {
+		hook.Events = hook.Events[:hookEventLimit]
+	}
+	if err := r.repo.Save(hook); err != nil {
+		r.logger.Error("[hook %s] Failed to record notification: %v", id, err)
+	}
+}
+
+// prepareHook validates the rules and source registry of a hook and generates its
+// secret if it has none.
+func prepareHook(hook, _ *models.Hook, _ time.Time) error {
+	if len(hook.Rules) == 0 || len(hook.Rules) > maxHookRules {
+		return fmt.Errorf("%w: a hook must have between 1 and %d rules", ErrInvalidHook, maxHookRules)
+	}
+	for i, rule := range hook.Rules {
+		if rule.Destination == "" {
+			return fmt.Errorf("%w: rule %d has no destination", ErrInvalidHook, i+1)
+		}
+		if _, _, err := compileHookRule(&rule); err != nil {
+			return fmt.Errorf("%w: rule %d: %v", ErrInvalidHook, i+1, err)
+		}
+	}
+	if registry := hook.SourceRegistry; registry != "" && models.RegistryHost(registry+"/image") != registry {
+		return fmt.Errorf("%w: sourceRegistry must be a registry host such as harbor.example.com", ErrInvalidHook)
+	}
+	if hook.Secret == "" {
+		secret, err := generateHookSecret()
+		if err != nil {
+			return fmt.Errorf("failed to generate secret: %w", err)
+		}
+		hook.Secret = secret
+	}
+	return nil
+}
+
+// compileHookRule compiles the repository and tag patterns of a rule, which must match
+// the whole repository and tag. Empty patterns match anything.
+func compileHookRule(rule *models.HookRule) (repository, tag *regexp.Regexp, err error) {
+	if repository, err = compileWholeMatch(rule.Repository); err != nil {
+		return nil, nil, fmt.Errorf("invalid repository pattern: %v", err)
+	}
+	if tag, err = compileWholeMatch(rule.Tag); err != nil {
+		return nil, nil, fmt.Errorf("invalid tag pattern: %v", err)
+	}
+	return repository, tag, nil
+}
+
+// compileWholeMatch compiles a pattern that must match a whole string (anything if empty).
+func compileWholeMatch(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		pattern = ".*"
+	}
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// hookDestination returns the destination of a pushed tag according to the first
+// matching rule, and whether any rule matches.
+func hookDestination(rules []models.HookRule, repository, tag string) (string, bool) {
+	for _, rule := range rules {
+		repoPattern, tagPattern, err := compileHookRule(&rule)
+		if err != nil || !repoPattern.MatchString(repository) || !tagPattern.MatchString(tag) {
+			continue
+		}
+		return ExpandHookDestination(rule.Destination, repository, tag), true
+	}
+	return "", false
+}
+
+// ExpandHookDestination replaces the placeholders in the destination of a hook rule:
+// {repository} by the pushed repository, e.g. "library/nginx", {name} by its last
+// component, e.g. "nginx", and {tag} by the pushed tag.
+func ExpandHookDestination(destination, repository, tag string) string {
+	return strings.NewReplacer(
+		"{repository}", repository,
+		"{name}", path.Base(repository),
+		"{tag}", tag,
+	).Replace(destination)
+}
+
+// verifyHookRequest reports whether a notification carries the secret of its hook,
+// in the authorization header or as the HMAC-SHA256 signature of the body.
+func verifyHookRequest(secret string, body []byte, authorization, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	if signature != "" {
+		sum, ok := strings.CutPrefix(signature, "sha256=")
+		if !ok {
+			return false
+		}
+		got, err := hex.DecodeString(sum)
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+
+	token := authorization
+	if scheme, credentials, ok := strings.Cut(authorization, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		token = credentials
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+// generateHookSecret generates a random secret for a hook.
+func generateHookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// pushEvent is a tag pushed to a registry, as reported by a notification.
+type pushEvent struct {
+	registry   string // Registry host as seen by the registry (empty if unknown)
+	repository string // Repository path, e.g. "library/nginx"
+	tag        string
+}
+
+// registryNotification holds the fields of the notifications of Docker Distribution
+// and Harbor that are needed to find the pushed tags.
+type registryNotification struct {
+	// Docker Distribution notification envelope
+	Events []struct {
+		Action string `json:"action"`
+		Target struct {
+			Repository string `json:"repository"`
+			Tag        string `json:"tag"`
+			URL        string `json:"url"`
+		} `json:"target"`
+		Request struct {
+			Host string `json:"host"`
+		} `json:"request"`
+	} `json:"events"`
+
+	// Harbor webhook payload
+	Type      string `json:"type"`
+	EventData struct {
+		Resources []struct {
+			Tag         string `json:"tag"`
+			ResourceURL string `json:"resource_url"`
+		} `json:"resources"`
+		Repository struct {
+			RepoFullName string `json:"repo_full_name"`
+		} `json:"repository"`
+	} `json:"event_data"`
+}
+
+// parsePushEvents returns the tags pushed according to a Docker Distribution notification
+// envelope or a Harbor webhook payload. Other events, such as pulls, deletions and pushes
+// of layers or of manifests by digest, are ignored.
+func parsePushEvents(body []byte) ([]pushEvent, error) {
+	var notification registryNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, err
+	}
+
+	var pushes []pushEvent
+	switch {
+	case notification.Events != nil:
+		for _, event := range notification.Events {
+			target := event.Target
+			if event.Action != "push" || target.Tag == "" || target.Repository == "" {
+				continue
+			}
+			registry := event.Request.Host
+			if registry == "" {
+				if u, err := url.Parse(target.URL); err == nil {
+					registry = u.Host
+				}
+			}
+			pushes = append(pushes, pushEvent{registry: registry, repository: target.Repository, tag: target.Tag})
+		}
+	case notification.Type != "":
+		// Harbor 2 reports PUSH_ARTIFACT, Harbor 1 pushImage
+		if notification.Type != "PUSH_ARTIFACT" && notification.Type != "pushImage" {
+			return nil, nil
+		}
+		repository := notification.EventData.Repository.RepoFullName
+		for _, resource := range notification.EventData.Resources {
+			if resource.Tag == "" || repository == "" {
+				continue
+			}
+			var registry string
+			if host, _, ok := strings.Cut(resource.ResourceURL, "/"); ok {
+				registry = host
+			}
+			pushes = append(pushes, pushEvent{registry: registry, repository: repository, tag: resource.Tag})
+		}
+	default:
+		return nil, errors.New("expected a Docker Distribution or Harbor notification")
+	}
+	return pushes, nil
+}
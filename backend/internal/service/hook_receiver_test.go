@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+const testHookSecret = "s3cr3t-0123456789"
+
+// newTestHookReceiver returns a receiver with one enabled hook mirroring the pushed
+// repositories below library/ to registry.example.com/mirror.
+func newTestHookReceiver(t *testing.T) (*HookReceiver, *syncService, *models.Hook) {
+	t.Helper()
+	receiver, svc, _ := newTestService(t, func(svc *syncService, dir string) (*HookReceiver, *definitionStore[*models.Hook], error) {
+		repo, err := repository.NewFileHookRepository(filepath.Join(dir, "hooks.json"))
+		if err != nil {
+			return nil, nil, err
+		}
+		receiver := NewHookReceiver(svc, nil, repo, logger.New())
+		return receiver, receiver.definitionStore, nil
+	})
+
+	hook := &models.Hook{Enabled: true, Secret: testHookSecret, Owner: "u1"}
+	hook.Rules = []models.HookRule{
+		{Repository: "library/.*", Tag: `v?\d+(\.\d+)*`, Destination: "registry.example.com/mirror/{name}:{tag}"},
+		{Repository: "library/.*", Destination: "registry.example.com/dev/{repository}:{tag}"},
+	}
+	if err := receiver.Create(hook); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	return receiver, svc, hook
+}
+
+func TestHookReceiver_Create(t *testing.T) {
+	receiver, _, _ := newTestHookReceiver(t)
+
+	invalid := []*models.Hook{
+		{},
+		{HookDefinition: models.HookDefinition{Rules: []models.HookRule{{Repository: "library/.*"}}}},
+		{HookDefinition: models.HookDefinition{Rules: []models.HookRule{{Tag: "(", Destination: "registry.example.com/{name}"}}}},
+		{HookDefinition: models.HookDefinition{SourceRegistry: "harbor", Rules: []models.HookRule{{Destination: "registry.example.com/{name}"}}}},
+	}
+	for i, hook := range invalid {
+		if err := receiver.Create(hook); !errors.Is(err, ErrInvalidHook) {
+			t.Errorf("Hook %d: expected ErrInvalidHook, got %v", i, err)
+		}
+	}
+
+	hook := &models.Hook{HookDefinition: models.HookDefinition{Rules: []models.HookRule{{Destination: "registry.example.com/{name}:{tag}"}}}}
+	if err := receiver.Create(hook); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(hook.Secret) < 32 {
+		t.Errorf("Expected a generated secret, got %q", hook.Secret)
+	}
+}
+
+func TestHookReceiver_DeliverDistribution(t *testing.T) {
+	receiver, svc, hook := newTestHookReceiver(t)
+
+	// A manifest pushed by tag, a layer, a pull and a push outside library/
+	body := []byte(`{"events": [
+		{"action": "push", "target": {"mediaType": "application/vnd.oci.image.manifest.v1+json", "repository": "library/nginx", "tag": "1.27.1",
+			"url": "https://internal:5000/v2/library/nginx/manifests/sha256:aaa"}, "request": {"host": "registry.local:5000"}},
+		{"action": "push", "target": {"mediaType": "application/octet-stream", "repository": "library/nginx"}},
+		{"action": "pull", "target": {"repository": "library/nginx", "tag": "1.27.1"}},
+		{"action": "push", "target": {"repository": "team/app", "tag": "dev", "url": "https://registry.local:5000/v2/team/app/manifests/dev"}}
+	]}`)
+
+	resp, err := receiver.Deliver(hook.ID, body, "Bearer "+testHookSecret, "")
+	if err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+	if len(resp.Events) != 2 || resp.Created != 1 {
+		t.Fatalf("Expected 2 tag pushes and 1 task, got %d and %d", len(resp.Events), resp.Created)
+	}
+	event := resp.Events[0]
+	task, err := svc.GetTask(event.TaskID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if task.SourceImage != "registry.local:5000/library/nginx:1.27.1" || task.DestImage != "registry.example.com/mirror/nginx:1.27.1" {
+		t.Errorf("Expected copy to the mirror, got %s -> %s", task.SourceImage, task.DestImage)
+	}
+	if task.HookID != hook.ID || task.Owner != "u1" {
+		t.Errorf("Expected task of the hook owned by u1, got hook %q owned by %q", task.HookID, task.Owner)
+	}
+	if resp.Events[1].Error == "" || resp.Events[1].Image != "registry.local:5000/team/app:dev" {
+		t.Errorf("Expected unmatched push of registry.local:5000/team/app:dev, got %+v", resp.Events[1])
+	}
+
+	// A repeated notification finds the identical task in progress
+	resp, err = receiver.Deliver(hook.ID, body, testHookSecret, "")
+	if err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+	if resp.Created != 0 || resp.Events[0].TaskID != task.ID {
+		t.Errorf("Expected duplicate of task %s, got %+v", task.ID, resp.Events[0])
+	}
+
+	stored, _ := receiver.Get(hook.ID)
+	if len(stored.Events) != 4 || stored.LastDeliveryAt == nil || stored.Events[3].TaskID != task.ID {
+		t.Errorf("Expected 4 recorded pushes, oldest last, got %d", len(stored.Events))
+	}
+}
+
+func TestHookReceiver_DeliverHarbor(t *testing.T) {
+	receiver, svc, hook := newTestHookReceiver(t)
+
+	body := []byte(`{"type": "PUSH_ARTIFACT", "occur_at": 1710410400, "operator": "admin",
+		"event_data": {"resources": [{"digest": "sha256:aaa", "tag": "latest", "resource_url": "harbor.example.com/library/redis:latest"}],
+		"repository": {"name": "redis", "namespace": "library", "repo_full_name": "library/redis", "repo_type": "public"}}}`)
+	mac := hmac.New(sha256.New, []byte(testHookSecret))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	resp, err := receiver.Deliver(hook.ID, body, "", signature)
+	if err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+	if resp.Created != 1 {
+		t.Fatalf("Expected 1 task, got %+v", resp.Events)
+	}
+	task, _ := svc.GetTask(resp.Events[0].TaskID)
+	if task.SourceImage != "harbor.example.com/library/redis:latest" || task.DestImage != "registry.example.com/dev/library/redis:latest" {
+		t.Errorf("Expected copy by the second rule, got %s -> %s", task.SourceImage, task.DestImage)
+	}
+
+	// Other Harbor events are ignored
+	resp, err = receiver.Deliver(hook.ID, []byte(`{"type": "DELETE_ARTIFACT"}`), testHookSecret, "")
+	if err != nil || len(resp.Events) != 0 {
+		t.Errorf("Expected ignored event, got %v, %v", resp, err)
+	}
+}
+
+func TestHookReceiver_DeliverRejected(t *testing.T) {
+	receiver, _, hook := newTestHookReceiver(t)
+	body := []byte(`{"events": []}`)
+
+	tests := []struct {
+		name          string
+		authorization string
+		signature     string
+		wantErr       error
+	}{
+		{"no credentials", "", "", ErrHookUnauthorized},
+		{"wrong secret", "Bearer wrong", "", ErrHookUnauthorized},
+		{"wrong signature", testHookSecret, "sha256=00", ErrHookUnauthorized},
+		{"valid secret", testHookSecret, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := receiver.Deliver(hook.ID, body, tt.authorization, tt.signature); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := receiver.Deliver(hook.ID, []byte(`{"foo": 1}`), testHookSecret, ""); !errors.Is(err, ErrInvalidNotification) {
+		t.Errorf("Expected ErrInvalidNotification, got %v", err)
+	}
+	if _, err := receiver.Update(hook.ID, func(hook *models.Hook) error {
+		hook.Enabled = false
+		return nil
+	}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := receiver.Deliver(hook.ID, body, testHookSecret, ""); !errors.Is(err, ErrHookDisabled) {
+		t.Errorf("Expected ErrHookDisabled, got %v", err)
+	}
+	if _, err := receiver.Deliver("missing", body, testHookSecret, ""); !errors.Is(err, repository.ErrHookNotFound) {
+		t.Errorf("Expected ErrHookNotFound, got %v", err)
+	}
+}
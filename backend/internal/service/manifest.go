@@ -0,0 +1,304 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrImageNotFound is returned when an inspected image or tag does not exist.
+	ErrImageNotFound = errors.New("image not found")
+
+	// ErrRateLimited is returned when the registry rejects an inspection with HTTP 429.
+	ErrRateLimited = errors.New("rate limited by registry")
+)
+
+// manifestPlatform is the platform of an image manifest in a manifest list or OCI index.
+type manifestPlatform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// String returns the platform in os/arch[/variant] form, as used for SyncRequest.Architecture.
+func (p *manifestPlatform) String() string {
+	if p.Variant != "" {
+		return fmt.Sprintf("%s/%s/%s", p.OS, p.Architecture, p.Variant)
+	}
+	return fmt.Sprintf("%s/%s", p.OS, p.Architecture)
+}
+
+// manifestDescriptor references a manifest, config or layer blob by digest.
+type manifestDescriptor struct {
+	MediaType string            `json:"mediaType"`
+	Digest    string            `json:"digest"`
+	Size      int64             `json:"size"`
+	Platform  *manifestPlatform `json:"platform,omitempty"`
+}
+
+// rawManifest holds the fields of an image manifest or manifest list (Docker v2 or OCI)
+// that are needed to compare and plan copies.
+type rawManifest struct {
+	MediaType string               `json:"mediaType"`
+	Manifests []manifestDescriptor `json:"manifests"` // Set for manifest lists / OCI indexes
+	Config    *manifestDescriptor  `json:"config"`    // Set for image manifests
+	Layers    []manifestDescriptor `json:"layers"`    // Set for image manifests
+}
+
+// IsIndex reports whether the manifest is a multi-platform manifest list or OCI index.
+func (m *rawManifest) IsIndex() bool {
+	return len(m.Manifests) > 0 ||
+		strings.Contains(m.MediaType, "manifest.list") ||
+		strings.Contains(m.MediaType, "image.index")
+}
+
+// FindPlatform returns the index entry for a platform in os/arch[/variant] form.
+// A platform without variant matches the first entry with the same os and architecture.
+func (m *rawManifest) FindPlatform(platform string) (*manifestDescriptor, bool) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 {
+		return nil, false
+	}
+	for i := range m.Manifests {
+		p := m.Manifests[i].Platform
+		if p == nil || p.OS != parts[0] || p.Architecture != parts[1] {
+			continue
+		}
+		if len(parts) > 2 && p.Variant != parts[2] {
+			continue
+		}
+		return &m.Manifests[i], true
+	}
+	return nil, false
+}
+
+// parseManifest decodes a raw manifest as returned by skopeo inspect --raw.
+func parseManifest(raw []byte) (*rawManifest, error) {
+	var m rawManifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// manifestDigest returns the content digest of a raw manifest, which is what registries
+// report as the digest of the tag it was pushed to.
+func manifestDigest(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// inspectRawManifest fetches the raw manifest of an image with skopeo inspect --raw.
+// authFile may be empty if no credentials are needed.
+// Returns ErrImageNotFound if the repository or tag does not exist.
+func inspectRawManifest(ctx context.Context, image string, tlsVerify bool, authFile string) ([]byte, error) {
+	return runSkopeoInspect(ctx, image, tlsVerify, authFile, "--raw")
+}
+
+// inspectRawConfig fetches the raw image config blob of a single-platform image.
+func inspectRawConfig(ctx context.Context, image string, tlsVerify bool, authFile string) ([]byte, error) {
+	return runSkopeoInspect(ctx, image, tlsVerify, authFile, "--config", "--raw")
+}
+
+// runSkopeoInspect runs skopeo inspect with the given flags and returns its output.
+func runSkopeoInspect(ctx context.Context, image string, tlsVerify bool, authFile string, flags ...string) ([]byte, error) {
+	args := append([]string{"inspect"}, flags...)
+	args = append(args, fmt.Sprintf("--tls-verify=%v", tlsVerify), fmt.Sprintf("docker://%s", image))
+	cmd := exec.CommandContext(ctx, "skopeo", args...)
+	if authFile != "" {
+		cmd.Env = append(os.Environ(), fmt.Sprintf("REGISTRY_AUTH_FILE=%s", authFile))
+	}
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, skopeoError(err, stderr.String())
+	}
+	return output, nil
+}
+
+// skopeoError converts a failed skopeo run into ErrImageNotFound or an error wrapping
+// ErrRateLimited where its stderr says so, and otherwise adds stderr to err.
+func skopeoError(err error, stderr string) error {
+	msg := strings.TrimSpace(stderr)
+	lower := strings.ToLower(msg)
+	if strings.Contains(lower, "manifest unknown") || strings.Contains(lower, "name unknown") ||
+		strings.Contains(lower, "not found") {
+		return ErrImageNotFound
+	}
+	if strings.Contains(lower, "toomanyrequests") || strings.Contains(lower, "too many requests") {
+		return fmt.Errorf("%w: %s", ErrRateLimited, msg)
+	}
+	if msg != "" {
+		return fmt.Errorf("%w: %s", err, msg)
+	}
+	return err
+}
+
+// resolveManifestDigest returns the digest that a copy of image for the given
+// architecture ("all" or os/arch[/variant]) produces at the destination.
+// Returns ErrImageNotFound if the image does not exist.
+func resolveManifestDigest(ctx context.Context, image, architecture string, tlsVerify bool, authFile string) (string, error) {
+	raw, err := inspectRawManifest(ctx, image, tlsVerify, authFile)
+	if err != nil {
+		return "", err
+	}
+	return copiedDigest(raw, architecture)
+}
+
+// copiedDigest returns the digest of the manifest a copy for the given architecture
+// selects from raw: the digest of the whole index for "all", otherwise the digest of
+// the platform's image manifest.
+func copiedDigest(raw []byte, architecture string) (string, error) {
+	if architecture == "" || architecture == "all" {
+		return manifestDigest(raw), nil
+	}
+
+	m, err := parseManifest(raw)
+	if err != nil {
+		return "", err
+	}
+	if !m.IsIndex() {
+		// A single-platform image is copied as is
+		return manifestDigest(raw), nil
+	}
+	desc, ok := m.FindPlatform(architecture)
+	if !ok {
+		return "", fmt.Errorf("platform %s not found", architecture)
+	}
+	return desc.Digest, nil
+}
+
+// trimIndex returns a copy of the manifest list raw that only references the given
+// platforms, together with their entries in list order. The kept entries are spliced
+// into the original bytes, so all other fields, their order and formatting are left
+// unchanged and the trimmed list references the exact image manifests of the original
+// one. Returns an error if raw is not a list or lacks a platform.
+func trimIndex(raw []byte, platforms []string) ([]byte, []manifestDescriptor, error) {
+	m, err := parseManifest(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !m.IsIndex() {
+		return nil, nil, errors.New("source is a single-platform image, not a manifest list")
+	}
+
+	spans, err := manifestEntrySpans(raw)
+	if err != nil || len(spans) != len(m.Manifests) {
+		return nil, nil, fmt.Errorf("failed to parse manifest list entries: %v", err)
+	}
+
+	keep := make([]bool, len(m.Manifests))
+	for _, platform := range platforms {
+		desc, ok := m.FindPlatform(platform)
+		if !ok {
+			return nil, nil, fmt.Errorf("platform %s not found in the source manifest list", platform)
+		}
+		for i := range m.Manifests {
+			if &m.Manifests[i] == desc {
+				keep[i] = true
+			}
+		}
+	}
+
+	// Kept entries are joined with the separator between the first two original entries
+	separator := []byte(",")
+	if len(spans) > 1 {
+		separator = raw[spans[0][1]:spans[1][0]]
+	}
+	trimmed := slices.Clone(raw[:spans[0][0]])
+	var descriptors []manifestDescriptor
+	for i, span := range spans {
+		if !keep[i] {
+			continue
+		}
+		if len(descriptors) > 0 {
+			trimmed = append(trimmed, separator...)
+		}
+		trimmed = append(trimmed, raw[span[0]:span[1]]...)
+		descriptors = append(descriptors, m.Manifests[i])
+	}
+	trimmed = append(trimmed, raw[spans[len(spans)-1][1]:]...)
+	return trimmed, descriptors, nil
+}
+
+// manifestEntrySpans returns the start and end offsets in raw of the entries of the
+// top-level manifests array of a manifest list.
+func manifestEntrySpans(raw []byte) ([][2]int64, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("manifest is not a JSON object")
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if key != "manifests" {
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return nil, errors.New("manifests is not an array")
+		}
+		var spans [][2]int64
+		for dec.More() {
+			var entry json.RawMessage
+			if err := dec.Decode(&entry); err != nil {
+				return nil, err
+			}
+			end := dec.InputOffset()
+			spans = append(spans, [2]int64{end - int64(len(entry)), end})
+		}
+		return spans, nil
+	}
+	return nil, errors.New("manifest has no manifests array")
+}
+
+// resolveTrimmedDigest returns the digest of the manifest list that a copy of the given
+// platforms of image produces at the destination, see trimIndex.
+// Returns ErrImageNotFound if the image does not exist.
+func resolveTrimmedDigest(ctx context.Context, image string, platforms []string, tlsVerify bool, authFile string) (string, error) {
+	raw, err := inspectRawManifest(ctx, image, tlsVerify, authFile)
+	if err != nil {
+		return "", err
+	}
+	trimmed, _, err := trimIndex(raw, platforms)
+	if err != nil {
+		return "", err
+	}
+	return manifestDigest(trimmed), nil
+}
+
+// imageWithDigest returns a reference to the manifest with the given digest in the
+// repository of image, replacing any tag or digest of the original reference.
+func imageWithDigest(image, digest string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	// A colon after the last slash separates the tag (a colon before it is a registry port)
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image + "@" + digest
+}
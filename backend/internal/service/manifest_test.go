@@ -0,0 +1,195 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+const testIndex = `{
+	"schemaVersion": 2,
+	"mediaType": "application/vnd.oci.image.index.v1+json",
+	"manifests": [
+		{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:amd64", "size": 100,
+		 "platform": {"os": "linux", "architecture": "amd64"}},
+		{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:armv6", "size": 100,
+		 "platform": {"os": "linux", "architecture": "arm", "variant": "v6"}},
+		{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:armv7", "size": 100,
+		 "platform": {"os": "linux", "architecture": "arm", "variant": "v7"}}
+	]
+}`
+
+func TestParseManifest_Index(t *testing.T) {
+	m, err := parseManifest([]byte(testIndex))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !m.IsIndex() {
+		t.Fatal("Expected manifest to be an index")
+	}
+
+	testCases := []struct {
+		platform string
+		digest   string
+		found    bool
+	}{
+		{"linux/amd64", "sha256:amd64", true},
+		{"linux/arm/v7", "sha256:armv7", true},
+		{"linux/arm", "sha256:armv6", true},
+		{"linux/arm64", "", false},
+		{"amd64", "", false},
+	}
+
+	for _, tc := range testCases {
+		desc, ok := m.FindPlatform(tc.platform)
+		if ok != tc.found {
+			t.Errorf("%s: expected found=%v, got %v", tc.platform, tc.found, ok)
+			continue
+		}
+		if ok && desc.Digest != tc.digest {
+			t.Errorf("%s: expected digest %s, got %s", tc.platform, tc.digest, desc.Digest)
+		}
+	}
+}
+
+func TestParseManifest_Image(t *testing.T) {
+	raw := `{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+		"config": {"digest": "sha256:config", "size": 10},
+		"layers": [{"digest": "sha256:layer1", "size": 20}, {"digest": "sha256:layer2", "size": 30}]
+	}`
+
+	m, err := parseManifest([]byte(raw))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if m.IsIndex() {
+		t.Error("Expected image manifest not to be an index")
+	}
+	if m.Config == nil || len(m.Layers) != 2 {
+		t.Errorf("Expected config and 2 layers, got %+v", m)
+	}
+}
+
+func TestManifestDigest(t *testing.T) {
+	// sha256 of the empty string
+	expected := "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if got := manifestDigest([]byte{}); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestCopiedDigest(t *testing.T) {
+	raw := []byte(testIndex)
+
+	digest, err := copiedDigest(raw, "all")
+	if err != nil || digest != manifestDigest(raw) {
+		t.Errorf("Expected index digest for all, got %s (%v)", digest, err)
+	}
+
+	digest, err = copiedDigest(raw, "linux/arm/v7")
+	if err != nil || digest != "sha256:armv7" {
+		t.Errorf("Expected platform digest sha256:armv7, got %s (%v)", digest, err)
+	}
+
+	if _, err := copiedDigest(raw, "linux/s390x"); err == nil {
+		t.Error("Expected error for platform missing from the index")
+	}
+}
+
+func TestTrimIndex(t *testing.T) {
+	raw := []byte(`{"schemaVersion": 2, "mediaType": "application/vnd.oci.image.index.v1+json",
+		"annotations": {"org.opencontainers.image.revision": "abc"},
+		"manifests": [
+			{"digest": "sha256:amd64", "size": 100, "platform": {"os": "linux", "architecture": "amd64"}},
+			{"digest": "sha256:armv7", "size": 100, "platform": {"os": "linux", "architecture": "arm", "variant": "v7"}},
+			{"digest": "sha256:arm64", "size": 100, "platform": {"os": "linux", "architecture": "arm64"},
+			 "annotations": {"com.example.note": "kept"}}
+		]}`)
+
+	trimmed, descriptors, err := trimIndex(raw, []string{"linux/arm64", "linux/amd64"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(descriptors) != 2 || descriptors[0].Digest != "sha256:amd64" || descriptors[1].Digest != "sha256:arm64" {
+		t.Fatalf("Expected amd64 and arm64 in list order, got %+v", descriptors)
+	}
+
+	m, err := parseManifest(trimmed)
+	if err != nil || len(m.Manifests) != 2 || m.MediaType != "application/vnd.oci.image.index.v1+json" {
+		t.Fatalf("Expected trimmed index with 2 manifests, got %s (%v)", trimmed, err)
+	}
+	for _, kept := range []string{`"org.opencontainers.image.revision": "abc"`, `"com.example.note": "kept"`, `"schemaVersion": 2`} {
+		if !strings.Contains(string(trimmed), kept) {
+			t.Errorf("Expected %s to be kept, got %s", kept, trimmed)
+		}
+	}
+
+	if _, _, err := trimIndex(raw, []string{"linux/s390x"}); err == nil {
+		t.Error("Expected error for platform missing from the index")
+	}
+	if _, _, err := trimIndex([]byte(`{"schemaVersion": 2, "config": {"digest": "sha256:c"}}`), []string{"linux/amd64"}); err == nil {
+		t.Error("Expected error for a single-platform image")
+	}
+}
+
+func TestTrimIndex_KeepsBytes(t *testing.T) {
+	raw := []byte(`{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.index.v1+json",
+  "manifests": [
+    {"digest": "sha256:amd64", "size": 100, "platform": {"os": "linux", "architecture": "amd64"}},
+    {"digest": "sha256:arm64", "size": 100, "platform": {"os": "linux", "architecture": "arm64"},
+     "annotations": {"com.example.note": "<b>R&D</b>"}}
+  ],
+  "annotations": {"org.opencontainers.image.description": "a <b> & c"}
+}`)
+
+	// Keeping every platform must not change the list, or its digest would differ
+	trimmed, _, err := trimIndex(raw, []string{"linux/amd64", "linux/arm64"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(trimmed) != string(raw) {
+		t.Errorf("Expected the list to be unchanged, got %s", trimmed)
+	}
+
+	trimmed, _, err = trimIndex(raw, []string{"linux/arm64"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.index.v1+json",
+  "manifests": [
+    {"digest": "sha256:arm64", "size": 100, "platform": {"os": "linux", "architecture": "arm64"},
+     "annotations": {"com.example.note": "<b>R&D</b>"}}
+  ],
+  "annotations": {"org.opencontainers.image.description": "a <b> & c"}
+}`
+	if string(trimmed) != want {
+		t.Errorf("Expected key order, formatting and <, > and & to be kept, got %s", trimmed)
+	}
+}
+
+func TestImageWithDigest(t *testing.T) {
+	testCases := []struct {
+		image    string
+		expected string
+	}{
+		{"nginx:latest", "nginx@sha256:abc"},
+		{"docker.io/library/nginx", "docker.io/library/nginx@sha256:abc"},
+		{"registry.example.com:5000/team/app:1.0", "registry.example.com:5000/team/app@sha256:abc"},
+		{"registry.example.com:5000/team/app", "registry.example.com:5000/team/app@sha256:abc"},
+		{"registry.example.com/app@sha256:old", "registry.example.com/app@sha256:abc"},
+	}
+
+	for _, tc := range testCases {
+		if got := imageWithDigest(tc.image, "sha256:abc"); got != tc.expected {
+			t.Errorf("imageWithDigest(%s): expected %s, got %s", tc.image, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/semver"
+)
+
+// listTagsTimeout bounds listing the tags of a source repository.
+const listTagsTimeout = 2 * time.Minute
+
+// ErrInvalidTagFilter is returned when a tag filter has a malformed pattern or constraint.
+var ErrInvalidTagFilter = errors.New("invalid tag filter")
+
+// PreviewRepoSync lists the tags of the source repository and returns those the
+// request's tag filter selects, without copying anything.
+// Returns an error wrapping ErrInvalidTagFilter for a malformed filter, and one
+// wrapping ErrImageNotFound if the source repository does not exist.
+func (s *syncService) PreviewRepoSync(ctx context.Context, req *models.RepoSyncRequest) (*models.RepoSyncPreview, error) {
+	// Check the filter before contacting the registry
+	if _, err := filterTags(nil, &req.Tags); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, listTagsTimeout)
+	defer cancel()
+
+	authFile, err := createAuthFile(req.SourceRepository, req.SourceUsername, req.SourcePassword, "", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth file: %w", err)
+	}
+	if authFile != "" {
+		defer func() {
+			if err := os.Remove(authFile); err != nil {
+				s.logger.Error("Failed to remove auth file: %v", err)
+			}
+		}()
+	}
+
+	tags, err := listTags(ctx, req.SourceRepository, tlsVerifyOrDefault(req.SrcTLSVerify), authFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of %s: %w", req.SourceRepository, err)
+	}
+	matched, err := filterTags(tags, &req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Repository %s has %d tag(s), %d matched", req.SourceRepository, len(tags), len(matched))
+	return &models.RepoSyncPreview{
+		SourceRepository: req.SourceRepository,
+		DestRepository:   req.DestRepository,
+		TotalTags:        len(tags),
+		Tags:             matched,
+	}, nil
+}
+
+// listTags returns the tags of a repository with skopeo list-tags.
+// Returns ErrImageNotFound if the repository does not exist.
+func listTags(ctx context.Context, repository string, tlsVerify bool, authFile string) ([]string, error) {
+	args := []string{"list-tags", fmt.Sprintf("--tls-verify=%v", tlsVerify), fmt.Sprintf("docker://%s", repository)}
+	cmd := exec.CommandContext(ctx, "skopeo", args...)
+	if authFile != "" {
+		cmd.Env = append(os.Environ(), fmt.Sprintf("REGISTRY_AUTH_FILE=%s", authFile))
+	}
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, skopeoError(err, stderr.String())
+	}
+
+	var result struct {
+		Tags []string `json:"Tags"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse tag list: %w", err)
+	}
+	return result.Tags, nil
+}
+
+// filterTags applies a tag filter. Include and exclude patterns are unanchored regular
+// expressions (use ^ and $ to match whole tags). When selecting by version, tags that
+// do not parse as versions and, unless requested, prereleases are skipped, and the
+// result is ordered by version, highest first; otherwise it is sorted by name.
+// Returns an error wrapping ErrInvalidTagFilter for a malformed pattern or constraint.
+func filterTags(tags []string, filter *models.TagFilter) ([]string, error) {
+	include, err := compilePatterns(filter.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compilePatterns(filter.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	var constraint *semver.Constraint
+	if filter.Semver != "" {
+		if constraint, err = semver.ParseConstraint(filter.Semver); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTagFilter, err)
+		}
+	}
+	if filter.Latest < 0 {
+		return nil, fmt.Errorf("%w: latest must not be negative", ErrInvalidTagFilter)
+	}
+
+	var matched []string
+	var versions []*semver.Version
+	for _, tag := range tags {
+		if len(include) > 0 && !matchAny(include, tag) {
+			continue
+		}
+		if matchAny(exclude, tag) {
+			continue
+		}
+		if !filter.UsesSemver() {
+			matched = append(matched, tag)
+			continue
+		}
+		v, err := semver.Parse(tag)
+		if err != nil || (v.Prerelease != "" && !filter.Prerelease) {
+			continue
+		}
+		if constraint != nil && !constraint.Match(v) {
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	if !filter.UsesSemver() {
+		sort.Strings(matched)
+		if matched == nil {
+			matched = []string{}
+		}
+		return matched, nil
+	}
+
+	// Highest version first; equal versions such as 1.2 and 1.2.0 keep a stable order by tag
+	sort.Slice(versions, func(i, j int) bool {
+		if c := versions[i].Compare(versions[j]); c != 0 {
+			return c > 0
+		}
+		return versions[i].Original < versions[j].Original
+	})
+	if filter.Latest > 0 && len(versions) > filter.Latest {
+		versions = versions[:filter.Latest]
+	}
+	matched = make([]string, len(versions))
+	for i, v := range versions {
+		matched[i] = v.Original
+	}
+	return matched, nil
+}
+
+// compilePatterns compiles tag filter patterns.
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: pattern %q: %v", ErrInvalidTagFilter, pattern, err)
+		}
+		compiled[i] = re
+	}
+	return compiled, nil
+}
+
+// matchAny reports whether tag matches any of the patterns.
+func matchAny(patterns []*regexp.Regexp, tag string) bool {
+	for _, re := range patterns {
+		if re.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+func TestFilterTags(t *testing.T) {
+	tags := []string{
+		"latest", "alpine", "1.23.4", "1.24", "1.24.0", "1.25.3", "1.25.3-alpine",
+		"v1.26.0", "1.27.0-rc.1", "2.0.0", "stable-perl",
+	}
+
+	tests := []struct {
+		name   string
+		filter models.TagFilter
+		want   []string
+	}{
+		{
+			name:   "no filter returns all tags by name",
+			filter: models.TagFilter{},
+			want: []string{
+				"1.23.4", "1.24", "1.24.0", "1.25.3", "1.25.3-alpine", "1.27.0-rc.1",
+				"2.0.0", "alpine", "latest", "stable-perl", "v1.26.0",
+			},
+		},
+		{
+			name:   "include and exclude",
+			filter: models.TagFilter{Include: []string{"alpine", "^latest$"}, Exclude: []string{`^\d`}},
+			want:   []string{"alpine", "latest"},
+		},
+		{
+			name:   "semver range skips non-versions and prereleases",
+			filter: models.TagFilter{Semver: ">=1.24 <2"},
+			want:   []string{"v1.26.0", "1.25.3", "1.24", "1.24.0"},
+		},
+		{
+			name:   "semver with prereleases",
+			filter: models.TagFilter{Semver: "^1.26", Prerelease: true},
+			want:   []string{"1.27.0-rc.1", "v1.26.0"},
+		},
+		{
+			name:   "latest N",
+			filter: models.TagFilter{Latest: 2},
+			want:   []string{"2.0.0", "v1.26.0"},
+		},
+		{
+			name:   "latest N within constraint after exclude",
+			filter: models.TagFilter{Exclude: []string{"^v"}, Semver: "1", Latest: 3},
+			want:   []string{"1.25.3", "1.24", "1.24.0"},
+		},
+		{
+			name:   "nothing matches",
+			filter: models.TagFilter{Include: []string{"^nightly"}},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterTags(tags, &tt.filter)
+			if err != nil {
+				t.Fatalf("filterTags failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFilterTags_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter models.TagFilter
+	}{
+		{"invalid include", models.TagFilter{Include: []string{"("}}},
+		{"invalid exclude", models.TagFilter{Exclude: []string{"[a-"}}},
+		{"invalid constraint", models.TagFilter{Semver: ">=one"}},
+		{"negative latest", models.TagFilter{Latest: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := filterTags([]string{"1.0.0"}, &tt.filter); !errors.Is(err, ErrInvalidTagFilter) {
+				t.Errorf("Expected ErrInvalidTagFilter, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/cron"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+// schedulerMaxSleep bounds how long the scheduler sleeps between checks, so that it
+// follows clock changes and starts queued runs soon after the previous run finished.
+const schedulerMaxSleep = time.Minute
+
+// ErrInvalidSchedule is returned when a schedule has a malformed cron expression,
+// time zone or overlap policy.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Scheduler stores schedules and creates a sync task whenever one of them is due.
+// Schedules are managed with the methods of definitionStore: Create and Update return
+// an error wrapping ErrInvalidSchedule if a schedule is malformed, and Get, Update,
+// SetEnabled and Delete return repository.ErrScheduleNotFound for unknown IDs. The next
+// run is recomputed whenever a schedule changes; its run history is kept.
+type Scheduler struct {
+	*definitionStore[*models.Schedule]
+	syncService   SyncService
+	configService *ConfigService // Resolves the credentials of the saved config a schedule references
+}
+
+// NewScheduler creates a scheduler for the schedules in repo.
+func NewScheduler(syncService SyncService, configService *ConfigService, repo repository.ScheduleRepository, logger logger.Logger) *Scheduler {
+	return &Scheduler{
+		definitionStore: newDefinitionStore(repo, definitionKind[*models.Schedule]{
+			name:    "schedule",
+			plural:  "schedules",
+			prepare: prepareSchedule,
+			describe: func(s *models.Schedule) string {
+				return fmt.Sprintf("%q (%s %s)", s.Cron, s.SourceImage, s.DestImage)
+			},
+		}, logger),
+		syncService:   syncService,
+		configService: configService,
+	}
+}
+
+// Run starts due schedules until ctx is done. Runs missed while the server was down
+// are not made up for; the schedules continue with their next regular run.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.skipMissedRuns()
+	s.run(ctx, func(context.Context) time.Time { return s.runDue() })
+}
+
+// prepareSchedule validates a schedule and sets its next run time.
+func prepareSchedule(schedule, _ *models.Schedule, now time.Time) error {
+	if schedule.Overlap == "" {
+		schedule.Overlap = models.OverlapSkip
+	}
+	if schedule.Overlap != models.OverlapSkip && schedule.Overlap != models.OverlapQueue {
+		return fmt.Errorf("%w: unknown overlap policy %q (expected skip or queue)", ErrInvalidSchedule, schedule.Overlap)
+	}
+
+	next, err := nextRun(schedule, now)
+	if err != nil {
+		return err
+	}
+	if next == nil && schedule.Enabled {
+		return fmt.Errorf("%w: cron expression %q never matches", ErrInvalidSchedule, schedule.Cron)
+	}
+	schedule.NextRunAt = next
+	if !schedule.Enabled {
+		schedule.RunQueued = false
+	}
+	return nil
+}
+
+// nextRun returns the first run time of an enabled schedule after t, or nil if the
+// schedule is disabled or never runs again.
+// Returns an error wrapping ErrInvalidSchedule for a malformed cron expression or time zone.
+func nextRun(schedule *models.Schedule, t time.Time) (*time.Time, error) {
+	expr, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	loc := time.Local
+	if schedule.Timezone != "" {
+		if loc, err = time.LoadLocation(schedule.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, schedule.Timezone)
+		}
+	}
+	if !schedule.Enabled {
+		return nil, nil
+	}
+	next := expr.Next(t.In(loc))
+	if next.IsZero() {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// skipMissedRuns moves the next run of schedules that were due while the server was
+// down to their next regular run time.
+func (s *Scheduler) skipMissedRuns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.repo.List("")
+	if err != nil {
+		s.logger.Error("Failed to list schedules: %v", err)
+		return
+	}
+	now := s.now()
+	for _, schedule := range schedules {
+		if !schedule.Enabled || schedule.NextRunAt == nil || !schedule.NextRunAt.Before(now) {
+			continue
+		}
+		missed := *schedule.NextRunAt
+		next, err := nextRun(schedule, now)
+		if err != nil {
+			s.logger.Error("[schedule %s] %v", schedule.ID, err)
+			continue
+		}
+		schedule.NextRunAt = next
+		if err := s.repo.Save(schedule); err != nil {
+			s.logger.Error("[schedule %s] Failed to save schedule: %v", schedule.ID, err)
+			continue
+		}
+		s.logger.Info("[schedule %s] Skipped run missed at %s while the server was down", schedule.ID, missed.Format(time.RFC3339))
+	}
+}
+
+// runDue runs all schedules that are due or have a queued run, and returns when
+// Run should check again.
+func (s *Scheduler) runDue() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	wakeAt := now.Add(schedulerMaxSleep)
+
+	schedules, err := s.repo.List("")
+	if err != nil {
+		s.logger.Error("Failed to list schedules: %v", err)
+		return wakeAt
+	}
+	for _, schedule := range schedules {
+		if !schedule.Enabled {
+			continue
+		}
+		due := schedule.NextRunAt != nil && !schedule.NextRunAt.After(now)
+		if due || schedule.RunQueued {
+			s.runSchedule(schedule, due, now)
+			if err := s.repo.Save(schedule); err != nil {
+				s.logger.Error("[schedule %s] Failed to save schedule: %v", schedule.ID, err)
+			}
+		}
+		// A schedule still due after its run failed is retried after schedulerMaxSleep
+		if schedule.NextRunAt != nil && schedule.NextRunAt.After(now) && schedule.NextRunAt.Before(wakeAt) {
+			wakeAt = *schedule.NextRunAt
+		}
+	}
+	return wakeAt
+}
+
+// runSchedule performs one run of a schedule, if due is set, or its queued run, and
+// records the outcome and the next run time in the schedule.
+// Must be called with s.mu held.
+func (s *Scheduler) runSchedule(schedule *models.Schedule, due bool, now time.Time) {
+	if due {
+		next, err := nextRun(schedule, now)
+		if err != nil {
+			// The schedule stays due, so the run is retried on the next check
+			s.recordRun(schedule, now, models.ScheduleRunFailed, err.Error())
+			s.logger.Error("[schedule %s] Run failed: %v", schedule.ID, err)
+			return
+		}
+		schedule.NextRunAt = next
+	}
+
+	if previous := s.activeRun(schedule); previous != "" {
+		if !due {
+			return
+		}
+		if schedule.Overlap == models.OverlapQueue {
+			schedule.RunQueued = true
+			s.logger.Info("[schedule %s] Previous run %s is still in progress, run queued", schedule.ID, previous)
+			return
+		}
+		s.recordRun(schedule, now, models.ScheduleRunSkipped, fmt.Sprintf("Previous run %s was still in progress", previous))
+		s.logger.Info("[schedule %s] Previous run %s is still in progress, run skipped", schedule.ID, previous)
+		return
+	}
+
+	schedule.RunQueued = false
+	taskID, err := s.startRun(schedule)
+	var duplicate *DuplicateTaskError
+	switch {
+	case errors.As(err, &duplicate):
+		s.recordRun(schedule, now, models.ScheduleRunSkipped, fmt.Sprintf("Identical sync %s was still in progress", duplicate.TaskID))
+		s.logger.Info("[schedule %s] Identical sync %s is in progress, run skipped", schedule.ID, duplicate.TaskID)
+	case err != nil:
+		s.recordRun(schedule, now, models.ScheduleRunFailed, err.Error())
+		s.logger.Error("[schedule %s] Run failed: %v", schedule.ID, err)
+	default:
+		schedule.LastTaskID = taskID
+		s.recordRun(schedule, now, models.ScheduleRunStarted, "")
+		s.logger.Info("[schedule %s] Run started task %s", schedule.ID, taskID)
+	}
+}
+
+// activeRun returns the ID of the task of the previous run if it is still pending or
+// running, or "" otherwise.
+func (s *Scheduler) activeRun(schedule *models.Schedule) string {
+	if schedule.LastTaskID == "" {
+		return ""
+	}
+	task, err := s.syncService.GetTask(schedule.LastTaskID)
+	if err != nil || task.Status.IsTerminal() {
+		return ""
+	}
+	return task.ID
+}
+
+// startRun creates and enqueues the sync task of a run, on behalf of the schedule's owner.
+func (s *Scheduler) startRun(schedule *models.Schedule) (string, error) {
+	req, err := ownerRequest(s.configService, &schedule.SyncDefinition, schedule.Owner, schedule.OwnerEmail)
+	if err != nil {
+		return "", err
+	}
+	req.ScheduleID = schedule.ID
+	return startSync(s.syncService, req)
+}
+
+// ownerRequest returns the sync request of a stored definition on behalf of its owner,
+// with the credentials of the owner's saved config the definition references.
+func ownerRequest(configService *ConfigService, def *models.SyncDefinition, owner, ownerEmail string) (*models.SyncRequest, error) {
+	req := def.Request()
+	req.Owner = owner
+	req.OwnerEmail = ownerEmail
+	if req.ConfigName != "" {
+		if configService == nil {
+			return nil, fmt.Errorf("config '%s' is not available", req.ConfigName)
+		}
+		if err := configService.ApplyCredentials(UserIdentifier(owner, ownerEmail), req.ConfigName, req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// startSync creates and enqueues a sync task and returns its ID.
+func startSync(syncService SyncService, req *models.SyncRequest) (string, error) {
+	taskID, err := syncService.CreateSyncTask(req)
+	if err != nil {
+		return "", err
+	}
+	if _, err := syncService.EnqueueTask(taskID, req); err != nil {
+		return "", fmt.Errorf("failed to enqueue task %s: %w", taskID, err)
+	}
+	return taskID, nil
+}
+
+// recordRun records the outcome of a run.
+func (s *Scheduler) recordRun(schedule *models.Schedule, at time.Time, status, message string) {
+	schedule.LastRunAt = &at
+	schedule.LastRunStatus = status
+	schedule.LastError = message
+}
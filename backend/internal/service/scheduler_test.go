@@ -0,0 +1,231 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+// newTestScheduler returns a scheduler whose clock is set by the returned function.
+func newTestScheduler(t *testing.T) (*Scheduler, *syncService, func(time.Time)) {
+	t.Helper()
+	return newTestService(t, func(svc *syncService, dir string) (*Scheduler, *definitionStore[*models.Schedule], error) {
+		repo, err := repository.NewFileScheduleRepository(filepath.Join(dir, "schedules.json"))
+		if err != nil {
+			return nil, nil, err
+		}
+		scheduler := NewScheduler(svc, nil, repo, logger.New())
+		return scheduler, scheduler.definitionStore, nil
+	})
+}
+
+func newTestSchedule(cron string, overlap models.OverlapPolicy) *models.Schedule {
+	schedule := &models.Schedule{Cron: cron, Timezone: "Asia/Shanghai", Enabled: true, Overlap: overlap, Owner: "u1"}
+	schedule.SourceImage = "docker.io/bitnami/redis:7"
+	schedule.DestImage = "registry.example.com/redis:7"
+	return schedule
+}
+
+func TestScheduler_Create(t *testing.T) {
+	scheduler, _, _ := newTestScheduler(t)
+
+	invalid := []*models.Schedule{
+		newTestSchedule("0 25 * * *", ""),
+		newTestSchedule("0 0 30 2 *", ""),
+		newTestSchedule("0 2 * * *", "replace"),
+	}
+	invalid[0].Timezone = ""
+	badZone := newTestSchedule("0 2 * * *", "")
+	badZone.Timezone = "Mars/Olympus"
+	invalid = append(invalid, badZone)
+	for _, schedule := range invalid {
+		if err := scheduler.Create(schedule); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("Expected ErrInvalidSchedule for %q in %q (overlap %q), got %v",
+				schedule.Cron, schedule.Timezone, schedule.Overlap, err)
+		}
+	}
+
+	schedule := newTestSchedule("0 2 * * *", "")
+	if err := scheduler.Create(schedule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// 10:00 UTC is 18:00 in Shanghai, so the next 02:00 there is 18:00 UTC the same day
+	if want := time.Date(2025, 3, 14, 18, 0, 0, 0, time.UTC); schedule.NextRunAt == nil || !schedule.NextRunAt.Equal(want) {
+		t.Errorf("Expected next run at %v, got %v", want, schedule.NextRunAt)
+	}
+	if schedule.Overlap != models.OverlapSkip {
+		t.Errorf("Expected default overlap policy skip, got %q", schedule.Overlap)
+	}
+
+	disabled, err := scheduler.Update(schedule.ID, func(s *models.Schedule) error {
+		s.Enabled = false
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if disabled.NextRunAt != nil {
+		t.Errorf("Expected no next run for disabled schedule, got %v", disabled.NextRunAt)
+	}
+	if _, err := scheduler.Update("missing", func(*models.Schedule) error { return nil }); !errors.Is(err, repository.ErrScheduleNotFound) {
+		t.Errorf("Expected ErrScheduleNotFound, got %v", err)
+	}
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	scheduler, svc, setNow := newTestScheduler(t)
+
+	schedule := newTestSchedule("0 2 * * *", models.OverlapSkip)
+	if err := scheduler.Create(schedule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Not due yet
+	scheduler.runDue()
+	if stored, _ := scheduler.Get(schedule.ID); stored.LastRunAt != nil {
+		t.Fatalf("Expected no run before the due time, got one at %v", stored.LastRunAt)
+	}
+
+	setNow(time.Date(2025, 3, 14, 18, 0, 5, 0, time.UTC))
+	if wakeAt := scheduler.runDue(); !wakeAt.Equal(time.Date(2025, 3, 14, 18, 1, 5, 0, time.UTC)) {
+		t.Errorf("Expected to check again after a minute, got %v", wakeAt)
+	}
+	first, _ := scheduler.Get(schedule.ID)
+	if first.LastRunStatus != models.ScheduleRunStarted || first.LastTaskID == "" {
+		t.Fatalf("Expected started run, got %q (task %q, error %q)", first.LastRunStatus, first.LastTaskID, first.LastError)
+	}
+	if want := time.Date(2025, 3, 15, 18, 0, 0, 0, time.UTC); !first.NextRunAt.Equal(want) {
+		t.Errorf("Expected next run at %v, got %v", want, first.NextRunAt)
+	}
+	task, _ := svc.GetTask(first.LastTaskID)
+	if task.ScheduleID != schedule.ID || task.Owner != "u1" || task.Status != models.StatusPending {
+		t.Errorf("Expected pending task of the schedule owned by u1, got schedule=%q owner=%q status=%s",
+			task.ScheduleID, task.Owner, task.Status)
+	}
+
+	// The previous run is still pending the next day, so the run is skipped
+	setNow(time.Date(2025, 3, 15, 18, 0, 0, 0, time.UTC))
+	scheduler.runDue()
+	second, _ := scheduler.Get(schedule.ID)
+	if second.LastRunStatus != models.ScheduleRunSkipped || second.LastTaskID != first.LastTaskID {
+		t.Errorf("Expected skipped run keeping task %s, got %q (task %s)", first.LastTaskID, second.LastRunStatus, second.LastTaskID)
+	}
+
+	// Once the previous run has finished, the next one starts a new task
+	if err := svc.CancelTask(first.LastTaskID, "u1"); err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+	setNow(time.Date(2025, 3, 16, 18, 0, 0, 0, time.UTC))
+	scheduler.runDue()
+	third, _ := scheduler.Get(schedule.ID)
+	if third.LastRunStatus != models.ScheduleRunStarted || third.LastTaskID == first.LastTaskID {
+		t.Errorf("Expected new started run, got %q (task %s)", third.LastRunStatus, third.LastTaskID)
+	}
+}
+
+func TestScheduler_OverlapQueue(t *testing.T) {
+	scheduler, svc, setNow := newTestScheduler(t)
+
+	schedule := newTestSchedule("*/10 * * * *", models.OverlapQueue)
+	if err := scheduler.Create(schedule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	setNow(time.Date(2025, 3, 14, 10, 10, 0, 0, time.UTC))
+	scheduler.runDue()
+	first, _ := scheduler.Get(schedule.ID)
+
+	setNow(time.Date(2025, 3, 14, 10, 20, 0, 0, time.UTC))
+	scheduler.runDue()
+	queued, _ := scheduler.Get(schedule.ID)
+	if !queued.RunQueued || queued.LastTaskID != first.LastTaskID {
+		t.Fatalf("Expected queued run behind task %s, got queued=%v task=%s", first.LastTaskID, queued.RunQueued, queued.LastTaskID)
+	}
+
+	// The queued run starts as soon as the previous one has finished, before the next due time
+	if err := svc.CancelTask(first.LastTaskID, "u1"); err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+	setNow(time.Date(2025, 3, 14, 10, 21, 0, 0, time.UTC))
+	scheduler.runDue()
+	started, _ := scheduler.Get(schedule.ID)
+	if started.RunQueued || started.LastRunStatus != models.ScheduleRunStarted || started.LastTaskID == first.LastTaskID {
+		t.Errorf("Expected queued run to start, got queued=%v status=%q", started.RunQueued, started.LastRunStatus)
+	}
+	if want := time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC); !started.NextRunAt.Equal(want) {
+		t.Errorf("Expected next run at %v, got %v", want, started.NextRunAt)
+	}
+}
+
+func TestScheduler_RunFailure(t *testing.T) {
+	scheduler, _, setNow := newTestScheduler(t)
+
+	schedule := newTestSchedule("0 * * * *", "")
+	schedule.ConfigName = "prod"
+	if err := scheduler.Create(schedule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	setNow(time.Date(2025, 3, 14, 11, 0, 0, 0, time.UTC))
+	scheduler.runDue()
+	stored, _ := scheduler.Get(schedule.ID)
+	if stored.LastRunStatus != models.ScheduleRunFailed || stored.LastError == "" || stored.LastTaskID != "" {
+		t.Errorf("Expected failed run with error, got %q (error %q)", stored.LastRunStatus, stored.LastError)
+	}
+}
+
+func TestScheduler_NextRunFailure(t *testing.T) {
+	scheduler, _, setNow := newTestScheduler(t)
+
+	schedule := newTestSchedule("0 2 * * *", "")
+	if err := scheduler.Create(schedule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// The time zone of a stored schedule is no longer known, e.g. after a tzdata change
+	stored, _ := scheduler.repo.Get(schedule.ID)
+	stored.Timezone = "Mars/Olympus"
+	if err := scheduler.repo.Save(stored); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	setNow(time.Date(2025, 3, 14, 18, 0, 0, 0, time.UTC))
+	if wakeAt := scheduler.runDue(); !wakeAt.Equal(time.Date(2025, 3, 14, 18, 1, 0, 0, time.UTC)) {
+		t.Errorf("Expected to retry after a minute, got %v", wakeAt)
+	}
+	failed, _ := scheduler.Get(schedule.ID)
+	if failed.LastRunStatus != models.ScheduleRunFailed || !strings.Contains(failed.LastError, "unknown time zone") || failed.LastTaskID != "" {
+		t.Errorf("Expected failed run with the time zone error, got %q (error %q, task %q)", failed.LastRunStatus, failed.LastError, failed.LastTaskID)
+	}
+	if failed.NextRunAt == nil || !failed.NextRunAt.Equal(*schedule.NextRunAt) {
+		t.Errorf("Expected next run to stay at %v, got %v", schedule.NextRunAt, failed.NextRunAt)
+	}
+}
+
+func TestScheduler_SkipMissedRuns(t *testing.T) {
+	scheduler, _, setNow := newTestScheduler(t)
+
+	schedule := newTestSchedule("0 2 * * *", "")
+	if err := scheduler.Create(schedule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// The server was down over two due times
+	setNow(time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC))
+	scheduler.skipMissedRuns()
+	stored, _ := scheduler.Get(schedule.ID)
+	if stored.LastRunAt != nil {
+		t.Errorf("Expected missed runs not to run, got run at %v", stored.LastRunAt)
+	}
+	if want := time.Date(2025, 3, 16, 18, 0, 0, 0, time.UTC); !stored.NextRunAt.Equal(want) {
+		t.Errorf("Expected next run at %v, got %v", want, stored.NextRunAt)
+	}
+}
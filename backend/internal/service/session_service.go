@@ -11,6 +11,9 @@ import (
 	"time"
 )
 
+// AdminGroup is the OIDC group whose members can see and manage the tasks of all users.
+const AdminGroup = "ADMIN"
+
 // SessionInfo stores information about a user session.
 type SessionInfo struct {
 	UserID   string
@@ -19,6 +22,16 @@ type SessionInfo struct {
 	ExpireAt time.Time
 }
 
+// IsAdmin reports whether the user is a member of AdminGroup.
+func (s *SessionInfo) IsAdmin() bool {
+	for _, group := range s.Groups {
+		if group == AdminGroup {
+			return true
+		}
+	}
+	return false
+}
+
 // SessionService manages user sessions.
 type SessionService struct {
 	sessions map[string]*SessionInfo
@@ -0,0 +1,334 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+// fanOutDestinations returns all destinations of a request with additional destinations,
+// DestImage first. Destinations without TLS settings inherit DestTLSVerify.
+func fanOutDestinations(req *models.SyncRequest) []models.SyncDestination {
+	destinations := []models.SyncDestination{{
+		Image:     req.DestImage,
+		Username:  req.DestUsername,
+		Password:  req.DestPassword,
+		TLSVerify: req.DestTLSVerify,
+	}}
+	for _, dest := range req.Destinations {
+		if dest.TLSVerify == nil {
+			dest.TLSVerify = req.DestTLSVerify
+		}
+		destinations = append(destinations, dest)
+	}
+	return destinations
+}
+
+// destinationResults returns the pending results of destinations, without credentials.
+func destinationResults(destinations []models.SyncDestination) []models.DestinationResult {
+	results := make([]models.DestinationResult, len(destinations))
+	for i, dest := range destinations {
+		results[i] = models.DestinationResult{
+			Image:     dest.Image,
+			TLSVerify: tlsVerifyOrDefault(dest.TLSVerify),
+			Auth:      dest.Username != "" && dest.Password != "",
+			Status:    models.StatusPending,
+		}
+	}
+	return results
+}
+
+// sameDestinations reports whether task copies to the additional destinations of req,
+// in the same order, and handles failed destinations as req does.
+func sameDestinations(task *models.SyncTask, req *models.SyncRequest) bool {
+	if len(req.Destinations) == 0 {
+		return len(task.Destinations) == 0
+	}
+	destinations := fanOutDestinations(req)
+	if len(task.Destinations) != len(destinations) || task.AllowPartial != req.AllowPartial {
+		return false
+	}
+	for i, dest := range destinations {
+		if task.Destinations[i].Image != dest.Image {
+			return false
+		}
+	}
+	return true
+}
+
+// executeFanOut runs a running task with additional destinations. Destinations that
+// already have the source digest are skipped unless the request forces the copy. A single
+// outdated destination is copied to directly; otherwise the source is pulled once into a
+// local directory and pushed from there to every outdated destination in order. A subset
+// of platforms is staged the same way, one directory per platform, and every destination
+// then gets the trimmed manifest list, see copyPlatforms.
+// The result of every destination is recorded in task.Destinations.
+func (s *syncService) executeFanOut(ctx context.Context, task *models.SyncTask, req *models.SyncRequest) error {
+	destinations := fanOutDestinations(req)
+	task.Destinations = destinationResults(destinations)
+
+	// Every destination gets its own auth file, as destinations may use different
+	// accounts of the same registry; the source pull only needs the source credentials
+	authFiles := make([]string, len(destinations)+1)
+	defer func() {
+		for _, authFile := range authFiles {
+			if authFile == "" {
+				continue
+			}
+			if err := os.Remove(authFile); err != nil {
+				s.logger.Error("[%s] Failed to remove auth file: %v", task.ID, err)
+			}
+		}
+	}()
+	for i, dest := range destinations {
+		authFile, err := createAuthFile(req.SourceImage, req.SourceUsername, req.SourcePassword, dest.Image, dest.Username, dest.Password)
+		if err != nil {
+			return s.handleTaskError(task, "Failed to create auth file", err)
+		}
+		authFiles[i] = authFile
+	}
+	srcAuthFile, err := createAuthFile(req.SourceImage, req.SourceUsername, req.SourcePassword, "", "", "")
+	if err != nil {
+		return s.handleTaskError(task, "Failed to create auth file", err)
+	}
+	authFiles[len(destinations)] = srcAuthFile
+
+	if req.SourceUsername != "" && req.SourcePassword != "" {
+		task.AddLog("Using source credentials")
+	}
+	subset := len(task.Architectures) > 0
+	if subset {
+		task.AddLog(fmt.Sprintf("Copying platforms: %s", strings.Join(task.Architectures, ", ")))
+	} else if task.Architecture == "all" {
+		task.AddLog("Copying all architectures")
+	} else if task.Architecture != "" {
+		task.AddLog(fmt.Sprintf("Copying architecture: %s", task.Architecture))
+	}
+
+	outdated := make([]int, 0, len(destinations))
+	for i := range destinations {
+		outdated = append(outdated, i)
+	}
+	if !req.Force {
+		outdated = s.outdatedDestinations(ctx, task, destinations, tlsVerifyOrDefault(req.SrcTLSVerify), srcAuthFile, authFiles)
+		if ctx.Err() != nil {
+			s.finishFanOut(ctx, task)
+			return nil
+		}
+		if len(outdated) == 0 {
+			s.finishSkipped(task)
+			return nil
+		}
+	}
+
+	s.logger.Info("[%s] Starting sync: %s -> %d destinations", task.ID, req.SourceImage, len(outdated))
+	retryTimes := retryTimesOrDefault(req.RetryTimes)
+	srcTLSVerify := tlsVerifyOrDefault(req.SrcTLSVerify)
+	source, architecture := fmt.Sprintf("docker://%s", task.SourceImage), task.Architecture
+	var platforms *platformSet
+	if subset {
+		platforms, err = s.sourcePlatforms(ctx, task, req, srcAuthFile)
+		if err != nil {
+			if ctx.Err() == nil {
+				for _, i := range outdated {
+					s.recordDestination(task, i, err)
+				}
+			}
+			s.finishFanOut(ctx, task)
+			return nil
+		}
+	}
+
+	if len(outdated) > 1 {
+		stageDir, err := os.MkdirTemp("", "skopeo-stage-*")
+		if err != nil {
+			return s.handleTaskError(task, "Failed to create staging directory", err)
+		}
+		defer os.RemoveAll(stageDir)
+
+		task.AddLog(fmt.Sprintf("Pulling %s once for %d destinations", task.SourceImage, len(outdated)))
+		if subset {
+			platforms, err = s.stagePlatforms(ctx, task, platforms, retryTimes, stageDir, srcAuthFile)
+		} else {
+			args := copyArgs(architecture, retryTimes, srcTLSVerify, false, source, fmt.Sprintf("dir:%s", stageDir))
+			err = s.runSkopeo(ctx, task, args, srcAuthFile)
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				for _, i := range outdated {
+					s.recordDestination(task, i, fmt.Errorf("pulling source failed: %w", err))
+				}
+			}
+			s.finishFanOut(ctx, task)
+			return nil
+		}
+
+		// The staged image already has the selected platform, or all of them
+		source, srcTLSVerify = fmt.Sprintf("dir:%s", stageDir), false
+		if architecture != "all" {
+			architecture = ""
+		}
+	}
+
+	for n, i := range outdated {
+		if ctx.Err() != nil {
+			break
+		}
+		result := &task.Destinations[i]
+		result.Status = models.StatusRunning
+		if err := s.repo.Update(task); err != nil {
+			s.logger.Error("[%s] Failed to update task: %v", task.ID, err)
+		}
+
+		task.AddLog(fmt.Sprintf("Copying to %s (%d/%d)", result.Image, n+1, len(outdated)))
+		if destinations[i].Username != "" && destinations[i].Password != "" {
+			task.AddLog("Using destination credentials")
+		}
+		var err error
+		if subset {
+			err = s.pushPlatforms(ctx, task, platforms, retryTimes, result.Image, result.TLSVerify, authFiles[i])
+		} else {
+			args := copyArgs(architecture, retryTimes, srcTLSVerify, result.TLSVerify, source, fmt.Sprintf("docker://%s", result.Image))
+			err = s.runSkopeo(ctx, task, args, authFiles[i])
+		}
+		if err != nil && ctx.Err() != nil {
+			break
+		}
+		s.recordDestination(task, i, err)
+	}
+
+	s.finishFanOut(ctx, task)
+	return nil
+}
+
+// runSkopeo runs skopeo with args until it exits, see startSkopeo.
+func (s *syncService) runSkopeo(ctx context.Context, task *models.SyncTask, args []string, authFile string) error {
+	wait, err := s.startSkopeo(ctx, task, args, authFile)
+	if err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+	return wait()
+}
+
+// outdatedDestinations compares the digest of the source with that of every destination
+// and returns the indexes of the destinations to copy to; the others are recorded as
+// skipped. As for a single destination, a digest that cannot be resolved counts as outdated.
+func (s *syncService) outdatedDestinations(ctx context.Context, task *models.SyncTask, destinations []models.SyncDestination, srcTLSVerify bool, srcAuthFile string, authFiles []string) []int {
+	var outdated []int
+	task.AddLog("Comparing source and destination digests")
+
+	srcDigest, err := copiedSourceDigest(ctx, task, srcTLSVerify, srcAuthFile)
+	if err != nil {
+		task.AddLog(fmt.Sprintf("Digest check skipped: cannot resolve source digest: %v", err))
+		for i := range destinations {
+			outdated = append(outdated, i)
+		}
+		return outdated
+	}
+	task.AddLog(fmt.Sprintf("Source digest: %s", srcDigest))
+
+	for i, dest := range destinations {
+		destDigest, err := copiedDestDigest(ctx, task, dest.Image, tlsVerifyOrDefault(dest.TLSVerify), authFiles[i])
+		switch {
+		case errors.Is(err, ErrImageNotFound):
+			task.AddLog(fmt.Sprintf("Destination %s does not exist yet", dest.Image))
+		case err != nil:
+			task.AddLog(fmt.Sprintf("Digest check of %s skipped: %v", dest.Image, err))
+		case destDigest == srcDigest:
+			task.AddLog(fmt.Sprintf("Destination %s already has the same digest, skipping", dest.Image))
+			task.Destinations[i].Status = models.StatusSkipped
+			continue
+		default:
+			task.AddLog(fmt.Sprintf("Destination %s has digest %s", dest.Image, destDigest))
+		}
+		outdated = append(outdated, i)
+	}
+	return outdated
+}
+
+// recordDestination records the result of the copy to the i-th destination of a task.
+func (s *syncService) recordDestination(task *models.SyncTask, i int, err error) {
+	result := &task.Destinations[i]
+	if err != nil {
+		result.Status = models.StatusFailed
+		result.Error = err.Error()
+		task.AddLog(fmt.Sprintf("Copy to %s failed: %v", result.Image, err))
+	} else {
+		result.Status = models.StatusCompleted
+		task.AddLog(fmt.Sprintf("Copy to %s completed", result.Image))
+	}
+	if updateErr := s.repo.Update(task); updateErr != nil {
+		s.logger.Error("[%s] Failed to update task: %v", task.ID, updateErr)
+	}
+}
+
+// finishFanOut records the final status of a task with additional destinations.
+// If ctx was stopped before every destination finished, the unfinished destinations get
+// the status finishSync gives the task. Otherwise the task completed if no destination
+// failed, and failed if any did, unless AllowPartial is set and at least one destination
+// was synced, which makes the task partial.
+func (s *syncService) finishFanOut(ctx context.Context, task *models.SyncTask) {
+	unfinished := slices.ContainsFunc(task.Destinations, func(result models.DestinationResult) bool {
+		return !result.Status.IsTerminal()
+	})
+	if ctx.Err() != nil && unfinished {
+		status := models.StatusCancelled
+		if ctx.Err() == context.DeadlineExceeded {
+			status = models.StatusFailed
+		} else if context.Cause(ctx) == ErrShuttingDown {
+			status = models.StatusInterrupted
+		}
+		for i := range task.Destinations {
+			if !task.Destinations[i].Status.IsTerminal() {
+				task.Destinations[i].Status = status
+			}
+		}
+		s.finishSync(ctx, task, ctx.Err())
+		return
+	}
+
+	synced := 0
+	var failures []string
+	for _, result := range task.Destinations {
+		switch result.Status {
+		case models.StatusCompleted, models.StatusSkipped:
+			synced++
+		case models.StatusFailed:
+			failures = append(failures, fmt.Sprintf("%s: %s", result.Image, result.Error))
+		}
+	}
+
+	if len(failures) == 0 {
+		s.finishSync(ctx, task, nil)
+		return
+	}
+	err := fmt.Errorf("%d of %d destinations failed: %s", len(failures), len(task.Destinations), strings.Join(failures, "; "))
+	if !task.AllowPartial || synced == 0 {
+		s.finishSync(ctx, task, err)
+		return
+	}
+
+	endTime := time.Now()
+	task.AddLog(fmt.Sprintf("Sync partially completed at %s: %v", endTime.Format(time.RFC3339), err))
+	s.logger.Info("[%s] Sync partially completed: %v", task.ID, err)
+
+	task.EndTime = &endTime
+	task.Output = strings.Join(task.GetLogLines(), "\n")
+	task.Status = models.StatusPartial
+	task.Message = fmt.Sprintf("Synced to %d of %d destinations", synced, len(task.Destinations))
+	task.ErrorOutput = err.Error()
+
+	if updateErr := s.repo.Update(task); updateErr != nil {
+		s.logger.Error("[%s] Failed to update task status: %v", task.ID, updateErr)
+	}
+	s.notifyFinished(task)
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+// fakeSkopeo puts a skopeo script first in PATH that records its arguments in the file
+// "calls" of the returned directory, one call per line. Inspections print the file
+// "manifest.json" of the directory, and copies to destinations containing "unreachable" fail.
+func fakeSkopeo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	script := `#!/bin/sh
+echo "$@" >> "` + filepath.Join(dir, "calls") + `"
+if [ "$1" = inspect ]; then exec cat "` + filepath.Join(dir, "manifest.json") + `"; fi
+for arg; do last="$arg"; done
+case "$last" in
+*unreachable*) echo "dial tcp: connection refused" >&2; exit 1 ;;
+esac
+echo "Writing manifest to image destination"
+`
+	if err := os.WriteFile(filepath.Join(dir, "skopeo"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake skopeo: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func fanOutRequest(allowPartial bool) *models.SyncRequest {
+	return &models.SyncRequest{
+		SourceImage: "docker.io/library/nginx:1.27",
+		DestImage:   "registry.example.com/nginx:1.27",
+		Destinations: []models.SyncDestination{
+			{Image: "dr.example.com/nginx:1.27", Username: "dr", Password: "secret"},
+			{Image: "unreachable.example.com/nginx:1.27"},
+		},
+		AllowPartial: allowPartial,
+		Force:        true,
+	}
+}
+
+func TestCreateSyncTask_FanOutDuplicates(t *testing.T) {
+	service := NewSyncService(repository.NewInMemoryTaskRepository(), repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	taskID, err := service.CreateSyncTask(fanOutRequest(true))
+	if err != nil {
+		t.Fatalf("CreateSyncTask failed: %v", err)
+	}
+	var duplicate *DuplicateTaskError
+	if _, err := service.CreateSyncTask(fanOutRequest(true)); !errors.As(err, &duplicate) || duplicate.TaskID != taskID {
+		t.Errorf("Expected the same fan-out to be a duplicate of %s, got %v", taskID, err)
+	}
+
+	// The primary destination alone does not make a fan-out a duplicate
+	fewer := fanOutRequest(true)
+	fewer.Destinations = fewer.Destinations[:1]
+	strict := fanOutRequest(false)
+	single := fanOutRequest(true)
+	single.Destinations = nil
+	for name, req := range map[string]*models.SyncRequest{"fewer destinations": fewer, "allowPartial unset": strict, "no additional destinations": single} {
+		if _, err := service.CreateSyncTask(req); err != nil {
+			t.Errorf("Expected a new task for a request with %s, got %v", name, err)
+		}
+	}
+}
+
+func TestExecuteSync_FanOutPartial(t *testing.T) {
+	dir := fakeSkopeo(t)
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	req := fanOutRequest(true)
+	taskID, err := service.CreateSyncTask(req)
+	if err != nil {
+		t.Fatalf("CreateSyncTask failed: %v", err)
+	}
+	task, _ := repo.Get(taskID)
+	if len(task.Destinations) != 3 || !task.Destinations[1].Auth || task.Destinations[2].Auth {
+		t.Fatalf("Expected 3 pending destinations, got %+v", task.Destinations)
+	}
+
+	if err := service.ExecuteSync(taskID, req); err != nil {
+		t.Fatalf("ExecuteSync failed: %v", err)
+	}
+
+	if task.Status != models.StatusPartial || task.Message != "Synced to 2 of 3 destinations" {
+		t.Errorf("Expected partial status, got %s: %s", task.Status, task.Message)
+	}
+	want := []models.SyncStatus{models.StatusCompleted, models.StatusCompleted, models.StatusFailed}
+	for i, result := range task.Destinations {
+		if result.Status != want[i] {
+			t.Errorf("Expected destination %s to be %s, got %s", result.Image, want[i], result.Status)
+		}
+	}
+	if !strings.Contains(task.Destinations[2].Error, "exit status 1") {
+		t.Errorf("Expected the error of the failed copy, got %q", task.Destinations[2].Error)
+	}
+
+	// The source is pulled once and pushed to every destination from the staging directory
+	data, err := os.ReadFile(filepath.Join(dir, "calls"))
+	if err != nil {
+		t.Fatalf("Failed to read skopeo calls: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 || strings.Count(string(data), "docker://docker.io/library/nginx:1.27") != 1 {
+		t.Fatalf("Expected one pull and three pushes, got %q", lines)
+	}
+	for _, line := range lines[1:] {
+		if !strings.Contains(line, "copy --retry-times 3 --src-tls-verify=false") || !strings.Contains(line, "--all dir:") {
+			t.Errorf("Expected push of all platforms from the staging directory, got %q", line)
+		}
+	}
+}
+
+func TestExecuteSync_FanOutArchitectures(t *testing.T) {
+	dir := fakeSkopeo(t)
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(testIndex), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	req := fanOutRequest(true)
+	req.Destinations = req.Destinations[:1]
+	req.Architectures = []string{"linux/arm/v7", "linux/amd64"}
+	taskID, _ := service.CreateSyncTask(req)
+	if err := service.ExecuteSync(taskID, req); err != nil {
+		t.Fatalf("ExecuteSync failed: %v", err)
+	}
+	task, _ := repo.Get(taskID)
+	if task.Status != models.StatusCompleted {
+		t.Fatalf("Expected status completed, got %s: %s", task.Status, task.ErrorOutput)
+	}
+
+	// Every platform is pulled once, then pushed with the trimmed list to both destinations
+	data, _ := os.ReadFile(filepath.Join(dir, "calls"))
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 9 || strings.Count(string(data), "docker://docker.io/library/nginx") != 3 {
+		t.Fatalf("Expected one inspection, two pulls and six pushes, got %q", lines)
+	}
+	for i, platform := range []string{"amd64", "armv7"} {
+		if pull := "docker://docker.io/library/nginx@sha256:" + platform + " dir:"; !strings.Contains(lines[1+i], pull) {
+			t.Errorf("Expected pull of platform %s to the staging directory, got %q", platform, lines[1+i])
+		}
+	}
+	for i, dest := range []string{"registry.example.com/nginx", "dr.example.com/nginx"} {
+		want := []string{
+			"--preserve-digests dir:",
+			"--preserve-digests dir:",
+			"--multi-arch=index-only --preserve-digests dir:",
+		}
+		for j, line := range lines[3+3*i : 6+3*i] {
+			if !strings.Contains(line, want[j]) || !strings.Contains(line, "docker://"+dest) {
+				t.Errorf("Expected push %d to %s to contain %q, got %q", j+1, dest, want[j], line)
+			}
+		}
+	}
+}
+
+func TestExecuteSync_FanOutFailed(t *testing.T) {
+	fakeSkopeo(t)
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	req := fanOutRequest(false)
+	taskID, _ := service.CreateSyncTask(req)
+	if err := service.ExecuteSync(taskID, req); err != nil {
+		t.Fatalf("ExecuteSync failed: %v", err)
+	}
+
+	task, _ := repo.Get(taskID)
+	if task.Status != models.StatusFailed {
+		t.Errorf("Expected status failed, got %s", task.Status)
+	}
+	if !strings.HasPrefix(task.ErrorOutput, "1 of 3 destinations failed: unreachable.example.com/nginx:1.27") {
+		t.Errorf("Expected the failed destination in the error, got %q", task.ErrorOutput)
+	}
+}
+
+func TestRetryTask_FanOutCredentials(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	parentID, _ := service.CreateSyncTask(fanOutRequest(true))
+	parent, _ := repo.Get(parentID)
+	parent.Status = models.StatusPartial
+	repo.Update(parent)
+
+	req, complete := parent.Request()
+	if complete {
+		t.Error("Expected request with destination credentials to be incomplete")
+	}
+	if _, err := service.RetryTask(parentID, req); !errors.Is(err, ErrCredentialsRequired) {
+		t.Errorf("Expected ErrCredentialsRequired without credentials, got %v", err)
+	}
+
+	req.Destinations[0].Username, req.Destinations[0].Password = "dr", "secret"
+	taskID, err := service.RetryTask(parentID, req)
+	if err != nil {
+		t.Fatalf("RetryTask failed: %v", err)
+	}
+	task, _ := repo.Get(taskID)
+	if len(task.Destinations) != 3 || !task.AllowPartial {
+		t.Errorf("Expected retry to keep the destinations, got %+v", task.Destinations)
+	}
+}
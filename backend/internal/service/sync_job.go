@@ -0,0 +1,219 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidJob is returned when the items of a batch job conflict with each other.
+var ErrInvalidJob = errors.New("invalid job")
+
+// CreateJob creates a batch job with one pending task per request, in request order.
+// The requests must have been validated and carry the owner.
+// The ID, creation time and task IDs of job are set; callers queue the tasks with EnqueueTask.
+// Returns an error wrapping ErrInvalidJob if two items have the same destination, a
+// *DuplicateTaskError if the sync of an item is already pending or running, and
+// ErrShuttingDown if the server is shutting down. If any task or the job cannot be
+// created, the tasks already created for it are deleted again.
+func (s *syncService) CreateJob(job *models.SyncJob, reqs []*models.SyncRequest) error {
+	dests := make(map[string]bool, len(reqs))
+	for _, req := range reqs {
+		if dests[req.DestImage] {
+			return fmt.Errorf("%w: destination %s appears more than once", ErrInvalidJob, req.DestImage)
+		}
+		dests[req.DestImage] = true
+	}
+
+	job.ID = uuid.New().String()
+	job.CreatedAt = time.Now()
+	job.TaskIDs = make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		req.JobID = job.ID
+		taskID, err := s.createTask(req, "")
+		if err != nil {
+			s.discardTasks(job.TaskIDs)
+			return err
+		}
+		job.TaskIDs = append(job.TaskIDs, taskID)
+	}
+
+	if err := s.jobs.Create(job); err != nil {
+		s.discardTasks(job.TaskIDs)
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	s.logger.Info("[%s] Job created with %d task(s)", job.ID, len(job.TaskIDs))
+	return nil
+}
+
+// discardTasks deletes the pending tasks created for a job that could not be created.
+func (s *syncService) discardTasks(ids []string) {
+	for _, id := range ids {
+		if err := s.repo.Delete(id); err != nil {
+			s.logger.Error("[%s] Failed to delete task of failed job: %v", id, err)
+		}
+	}
+}
+
+// GetJob returns a job with its aggregated status and the latest attempt of every item.
+// Returns repository.ErrJobNotFound if the job does not exist.
+func (s *syncService) GetJob(id string) (*models.JobSummary, error) {
+	job, err := s.jobs.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.LatestJobTasks(job)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := summarizeJob(job, tasks)
+	summary.Tasks = make([]*models.TaskSummary, len(tasks))
+	for i, task := range tasks {
+		summary.Tasks[i] = task.Summary()
+	}
+	return summary, nil
+}
+
+// ListJobs retrieves a page of jobs, newest first, with their aggregated status.
+func (s *syncService) ListJobs(req *models.JobListRequest) (*models.JobListResponse, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	jobs, total, err := s.jobs.Query(req.Owner, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	summaries := make([]*models.JobSummary, len(jobs))
+	for i, job := range jobs {
+		tasks, err := s.LatestJobTasks(job)
+		if err != nil {
+			return nil, err
+		}
+		summaries[i] = summarizeJob(job, tasks)
+	}
+
+	return &models.JobListResponse{
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		Jobs:     summaries,
+	}, nil
+}
+
+// CancelJob cancels all pending and running tasks of a job and returns how many were cancelled.
+// Returns repository.ErrJobNotFound if the job does not exist.
+func (s *syncService) CancelJob(id, cancelledBy string) (int, error) {
+	job, err := s.jobs.Get(id)
+	if err != nil {
+		return 0, err
+	}
+	tasks, err := s.LatestJobTasks(job)
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, task := range tasks {
+		if task.Status.IsTerminal() {
+			continue
+		}
+		// The task may have finished in the meantime
+		if err := s.CancelTask(task.ID, cancelledBy); err != nil {
+			if errors.Is(err, ErrTaskFinished) || errors.Is(err, repository.ErrTaskNotFound) {
+				continue
+			}
+			return cancelled, err
+		}
+		cancelled++
+	}
+	s.logger.Info("[%s] Job cancelled by %s (%d task(s))", id, displayUser(cancelledBy), cancelled)
+	return cancelled, nil
+}
+
+// LatestJobTasks returns the latest attempt of every item of a job in request order:
+// starting from the item's first task, it follows the most recent retry at each step.
+// Items whose first task has been deleted are left out.
+func (s *syncService) LatestJobTasks(job *models.SyncJob) ([]*models.SyncTask, error) {
+	tasks, _, err := s.repo.Query(&repository.TaskQuery{JobID: job.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job tasks: %w", err)
+	}
+
+	byID := make(map[string]*models.SyncTask, len(tasks))
+	latestChild := make(map[string]*models.SyncTask, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+		if task.ParentTaskID == "" {
+			continue
+		}
+		if child, ok := latestChild[task.ParentTaskID]; !ok || task.StartTime.After(child.StartTime) {
+			latestChild[task.ParentTaskID] = task
+		}
+	}
+
+	latest := make([]*models.SyncTask, 0, len(job.TaskIDs))
+	for _, id := range job.TaskIDs {
+		task, ok := byID[id]
+		if !ok {
+			continue
+		}
+		for {
+			child, ok := latestChild[task.ID]
+			if !ok {
+				break
+			}
+			task = child
+		}
+		latest = append(latest, task)
+	}
+	return latest, nil
+}
+
+// summarizeJob aggregates the statuses of the latest tasks of a job.
+func summarizeJob(job *models.SyncJob, tasks []*models.SyncTask) *models.JobSummary {
+	summary := &models.JobSummary{SyncJob: job}
+	for _, task := range tasks {
+		summary.Counts.Add(task.Status)
+	}
+	summary.Status = summary.Counts.Status()
+	return summary
+}
+
+// pruneJob deletes a job once all of its tasks have been deleted.
+func (s *syncService) pruneJob(id string) {
+	if id == "" {
+		return
+	}
+	_, remaining, err := s.repo.Query(&repository.TaskQuery{JobID: id, Limit: 1})
+	if err != nil {
+		s.logger.Error("[%s] Failed to count job tasks: %v", id, err)
+		return
+	}
+	if remaining > 0 {
+		return
+	}
+	if err := s.jobs.Delete(id); err != nil && !errors.Is(err, repository.ErrJobNotFound) {
+		s.logger.Error("[%s] Failed to delete job: %v", id, err)
+		return
+	}
+	s.logger.Info("[%s] Job deleted with its last task", id)
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+func newTestJob(t *testing.T, service SyncService, owner string, dests ...string) *models.SyncJob {
+	t.Helper()
+	reqs := make([]*models.SyncRequest, len(dests))
+	for i, dest := range dests {
+		reqs[i] = &models.SyncRequest{SourceImage: "docker.io/library/nginx:latest", DestImage: dest, Owner: owner}
+	}
+	job := &models.SyncJob{Name: "mirrors", Owner: owner}
+	if err := service.CreateJob(job, reqs); err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	return job
+}
+
+func TestCreateJob(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	job := newTestJob(t, service, "u1", "registry.example.com/nginx:1", "registry.example.com/nginx:2")
+	if job.ID == "" || len(job.TaskIDs) != 2 {
+		t.Fatalf("Expected job ID and 2 task IDs, got %q and %v", job.ID, job.TaskIDs)
+	}
+	for _, id := range job.TaskIDs {
+		task, _ := repo.Get(id)
+		if task.JobID != job.ID || task.Owner != "u1" || task.Status != models.StatusPending {
+			t.Errorf("Expected pending task of job %s owned by u1, got job=%s owner=%s status=%s",
+				job.ID, task.JobID, task.Owner, task.Status)
+		}
+	}
+
+	summary, err := service.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if summary.Status != models.StatusPending || summary.Counts.Pending != 2 || len(summary.Tasks) != 2 {
+		t.Errorf("Expected 2 pending tasks, got status=%s counts=%+v", summary.Status, summary.Counts)
+	}
+	if summary.Tasks[0].DestImage != "registry.example.com/nginx:1" {
+		t.Errorf("Expected tasks in request order, got %s first", summary.Tasks[0].DestImage)
+	}
+
+	reqs := []*models.SyncRequest{
+		{SourceImage: "docker.io/library/nginx:1", DestImage: "registry.example.com/nginx:latest"},
+		{SourceImage: "docker.io/library/nginx:2", DestImage: "registry.example.com/nginx:latest"},
+	}
+	if err := service.CreateJob(&models.SyncJob{}, reqs); !errors.Is(err, ErrInvalidJob) {
+		t.Errorf("Expected ErrInvalidJob for duplicate destinations, got %v", err)
+	}
+
+	if _, err := service.GetJob("missing"); err != repository.ErrJobNotFound {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestCreateJobDiscardsTasks(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	inFlightID, _ := service.CreateSyncTask(&models.SyncRequest{
+		SourceImage: "docker.io/library/nginx:latest",
+		DestImage:   "registry.example.com/nginx:2",
+		Owner:       "u1",
+	})
+
+	// The second item is already being synced, so the task of the first must not remain
+	reqs := []*models.SyncRequest{
+		{SourceImage: "docker.io/library/nginx:latest", DestImage: "registry.example.com/nginx:1", Owner: "u1"},
+		{SourceImage: "docker.io/library/nginx:latest", DestImage: "registry.example.com/nginx:2", Owner: "u1"},
+	}
+	var duplicate *DuplicateTaskError
+	if err := service.CreateJob(&models.SyncJob{Owner: "u1"}, reqs); !errors.As(err, &duplicate) || duplicate.TaskID != inFlightID {
+		t.Fatalf("Expected duplicate of task %s, got %v", inFlightID, err)
+	}
+
+	tasks, total, _ := repo.Query(&repository.TaskQuery{})
+	if total != 1 || tasks[0].ID != inFlightID {
+		t.Errorf("Expected only the in-flight task to remain, got %d tasks", total)
+	}
+}
+
+func TestJobRetry(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	job := newTestJob(t, service, "", "registry.example.com/nginx:1", "registry.example.com/nginx:2")
+	failed, _ := repo.Get(job.TaskIDs[0])
+	failed.Status = models.StatusFailed
+	completed, _ := repo.Get(job.TaskIDs[1])
+	completed.Status = models.StatusCompleted
+
+	summary, _ := service.GetJob(job.ID)
+	if summary.Status != models.StatusFailed || summary.Counts.Failed != 1 || summary.Counts.Succeeded != 1 {
+		t.Errorf("Expected failed job with 1 failed and 1 succeeded task, got %s %+v", summary.Status, summary.Counts)
+	}
+
+	// A retry stays in the job and replaces the failed task
+	req, _ := failed.Request()
+	retryID, err := service.RetryTask(failed.ID, req)
+	if err != nil {
+		t.Fatalf("RetryTask failed: %v", err)
+	}
+	summary, _ = service.GetJob(job.ID)
+	if summary.Tasks[0].ID != retryID || summary.Counts.Total != 2 || summary.Status != models.StatusRunning {
+		t.Errorf("Expected retry %s to replace the failed task, got %s (status %s)", retryID, summary.Tasks[0].ID, summary.Status)
+	}
+
+	retry, _ := repo.Get(retryID)
+	retry.Status = models.StatusCompleted
+	if summary, _ = service.GetJob(job.ID); summary.Status != models.StatusCompleted {
+		t.Errorf("Expected completed job after a successful retry, got %s", summary.Status)
+	}
+
+	// A clone does not belong to the job
+	req, _ = completed.Request()
+	req.DestImage = "registry.example.com/nginx:3"
+	cloneID, _ := service.CloneTask(completed.ID, req)
+	if clone, _ := repo.Get(cloneID); clone.JobID != "" {
+		t.Errorf("Expected clone outside the job, got job %s", clone.JobID)
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	job := newTestJob(t, service, "", "registry.example.com/nginx:1", "registry.example.com/nginx:2", "registry.example.com/nginx:3")
+	completed, _ := repo.Get(job.TaskIDs[2])
+	completed.Status = models.StatusCompleted
+
+	cancelled, err := service.CancelJob(job.ID, "alice@example.com")
+	if err != nil {
+		t.Fatalf("CancelJob failed: %v", err)
+	}
+	if cancelled != 2 {
+		t.Errorf("Expected 2 cancelled tasks, got %d", cancelled)
+	}
+
+	summary, _ := service.GetJob(job.ID)
+	if summary.Status != models.StatusCancelled || summary.Counts.Cancelled != 2 {
+		t.Errorf("Expected cancelled job, got %s %+v", summary.Status, summary.Counts)
+	}
+	if summary.Tasks[0].Status != models.StatusCancelled {
+		t.Errorf("Expected first task cancelled, got %s", summary.Tasks[0].Status)
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	newTestJob(t, service, "u1", "registry.example.com/nginx:1")
+	job := newTestJob(t, service, "u2", "registry.example.com/nginx:2", "registry.example.com/nginx:3")
+
+	resp, err := service.ListJobs(&models.JobListRequest{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("ListJobs failed: %v", err)
+	}
+	if resp.Total != 2 || len(resp.Jobs) != 2 {
+		t.Fatalf("Expected 2 jobs, got total=%d", resp.Total)
+	}
+	if resp.Jobs[0].Tasks != nil {
+		t.Error("Expected job list without task summaries")
+	}
+
+	resp, _ = service.ListJobs(&models.JobListRequest{Page: 1, PageSize: 10, Owner: "u2"})
+	if resp.Total != 1 || resp.Jobs[0].Counts.Total != 2 {
+		t.Errorf("Expected one job of u2 with 2 tasks, got total=%d", resp.Total)
+	}
+
+	tasks, _ := service.ListTasks(&models.TaskListRequest{JobID: job.ID})
+	if tasks.Total != 2 {
+		t.Errorf("Expected 2 tasks of job %s, got %d", job.ID, tasks.Total)
+	}
+}
+
+func TestDeleteJobTasks(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	jobs := repository.NewInMemoryJobRepository()
+	service := NewSyncService(repo, jobs, logger.New(), 600, 3, time.Hour)
+
+	job := newTestJob(t, service, "", "registry.example.com/nginx:1", "registry.example.com/nginx:2")
+	service.CancelJob(job.ID, "")
+
+	if err := service.DeleteTask(job.TaskIDs[0]); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+	summary, err := service.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("Expected job to remain while it has tasks, got %v", err)
+	}
+	if summary.Counts.Total != 1 {
+		t.Errorf("Expected deleted task to be left out, got %d tasks", summary.Counts.Total)
+	}
+
+	// Deleting the last task deletes the job
+	if _, err := service.DeleteTasks("", "", time.Time{}); err != nil {
+		t.Fatalf("DeleteTasks failed: %v", err)
+	}
+	if _, err := jobs.Get(job.ID); err != repository.ErrJobNotFound {
+		t.Errorf("Expected job to be deleted with its last task, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+// planTimeout bounds all registry lookups made for one sync plan.
+const planTimeout = 2 * time.Minute
+
+// platformManifest is an image manifest selected for a copy, with its platform.
+type platformManifest struct {
+	platform string
+	digest   string
+	manifest *rawManifest
+}
+
+// blobs returns the config and layer blobs referenced by the manifest.
+func (p *platformManifest) blobs() []manifestDescriptor {
+	var blobs []manifestDescriptor
+	if p.manifest.Config != nil {
+		blobs = append(blobs, *p.manifest.Config)
+	}
+	return append(blobs, p.manifest.Layers...)
+}
+
+// PlanSync reports what a sync request would do without writing to the destination:
+// the source digest, the platforms and blobs that would be copied, which blobs the
+// destination tag already references, and whether an existing tag would be overwritten.
+// Returns an error wrapping ErrImageNotFound if the source image does not exist.
+func (s *syncService) PlanSync(ctx context.Context, req *models.SyncRequest) (*models.SyncPlan, error) {
+	ctx, cancel := context.WithTimeout(ctx, planTimeout)
+	defer cancel()
+
+	architecture := req.Architecture
+	if architecture == "" {
+		architecture = "all"
+	}
+	srcTLSVerify := tlsVerifyOrDefault(req.SrcTLSVerify)
+	destTLSVerify := tlsVerifyOrDefault(req.DestTLSVerify)
+
+	authFile, err := createAuthFile(
+		req.SourceImage, req.SourceUsername, req.SourcePassword,
+		req.DestImage, req.DestUsername, req.DestPassword,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth file: %w", err)
+	}
+	if authFile != "" {
+		defer func() {
+			if err := os.Remove(authFile); err != nil {
+				s.logger.Error("Failed to remove auth file: %v", err)
+			}
+		}()
+	}
+
+	plan := &models.SyncPlan{
+		SourceImage:  req.SourceImage,
+		DestImage:    req.DestImage,
+		Architecture: architecture,
+		Platforms:    []*models.PlatformPlan{},
+		Blobs:        []*models.BlobPlan{},
+	}
+
+	// Source: the manifest that would be copied and the images it selects
+	srcRaw, err := inspectRawManifest(ctx, req.SourceImage, srcTLSVerify, authFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect source image: %w", err)
+	}
+	if plan.SourceDigest, err = copiedDigest(srcRaw, architecture); err != nil {
+		return nil, fmt.Errorf("source image: %w", err)
+	}
+	platforms, err := selectPlatformManifests(ctx, req.SourceImage, srcRaw, architecture, srcTLSVerify, authFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect source image: %w", err)
+	}
+
+	// Destination: the current tag, if any, and the blobs it references
+	destBlobs := make(map[string]bool)
+	destRaw, err := inspectRawManifest(ctx, req.DestImage, destTLSVerify, authFile)
+	switch {
+	case errors.Is(err, ErrImageNotFound):
+		// Nothing to compare with; every blob has to be uploaded
+	case err != nil:
+		return nil, fmt.Errorf("failed to inspect destination image: %w", err)
+	default:
+		plan.DestExists = true
+		// The destination may lack the requested platform, which simply means it differs
+		plan.DestDigest, _ = copiedDigest(destRaw, architecture)
+		plan.UpToDate = plan.DestDigest == plan.SourceDigest
+		plan.Overwrite = !plan.UpToDate
+
+		existing, err := selectPlatformManifests(ctx, req.DestImage, destRaw, "all", destTLSVerify, authFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect destination image: %w", err)
+		}
+		for _, pm := range existing {
+			for _, blob := range pm.blobs() {
+				destBlobs[blob.Digest] = true
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, pm := range platforms {
+		platformPlan := &models.PlatformPlan{Platform: pm.platform, Digest: pm.digest}
+		for _, blob := range pm.blobs() {
+			platformPlan.Blobs++
+			platformPlan.Bytes += blob.Size
+
+			// Platforms often share layers; count each blob once
+			if seen[blob.Digest] {
+				continue
+			}
+			seen[blob.Digest] = true
+			exists := destBlobs[blob.Digest]
+			plan.Blobs = append(plan.Blobs, &models.BlobPlan{Digest: blob.Digest, Size: blob.Size, ExistsAtDest: exists})
+			plan.TotalBytes += blob.Size
+			if exists {
+				plan.ExistingBytes += blob.Size
+			}
+		}
+		plan.Platforms = append(plan.Platforms, platformPlan)
+	}
+	plan.TransferBytes = plan.TotalBytes - plan.ExistingBytes
+
+	s.logger.Info("Planned sync %s -> %s: %d platform(s), %d bytes to transfer, overwrite: %v",
+		req.SourceImage, req.DestImage, len(plan.Platforms), plan.TransferBytes, plan.Overwrite)
+	return plan, nil
+}
+
+// selectPlatformManifests returns the image manifests a copy of image for the given
+// architecture would include, fetching the per-platform manifests of an index.
+func selectPlatformManifests(ctx context.Context, image string, raw []byte, architecture string, tlsVerify bool, authFile string) ([]*platformManifest, error) {
+	m, err := parseManifest(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.IsIndex() {
+		return []*platformManifest{{
+			platform: imagePlatform(ctx, image, tlsVerify, authFile),
+			digest:   manifestDigest(raw),
+			manifest: m,
+		}}, nil
+	}
+
+	descriptors := m.Manifests
+	if architecture != "all" {
+		desc, ok := m.FindPlatform(architecture)
+		if !ok {
+			return nil, fmt.Errorf("platform %s not found", architecture)
+		}
+		descriptors = []manifestDescriptor{*desc}
+	}
+
+	selected := make([]*platformManifest, 0, len(descriptors))
+	for _, desc := range descriptors {
+		instanceRaw, err := inspectRawManifest(ctx, imageWithDigest(image, desc.Digest), tlsVerify, authFile)
+		if err != nil {
+			return nil, fmt.Errorf("manifest %s: %w", desc.Digest, err)
+		}
+		instance, err := parseManifest(instanceRaw)
+		if err != nil {
+			return nil, err
+		}
+		platform := "unknown"
+		if desc.Platform != nil {
+			platform = desc.Platform.String()
+		}
+		selected = append(selected, &platformManifest{platform: platform, digest: desc.Digest, manifest: instance})
+	}
+	return selected, nil
+}
+
+// imagePlatform returns the platform of a single-platform image from its config,
+// or "unknown" if it cannot be determined.
+func imagePlatform(ctx context.Context, image string, tlsVerify bool, authFile string) string {
+	raw, err := inspectRawConfig(ctx, image, tlsVerify, authFile)
+	if err != nil {
+		return "unknown"
+	}
+	var platform manifestPlatform
+	if err := json.Unmarshal(raw, &platform); err != nil || platform.OS == "" {
+		return "unknown"
+	}
+	return platform.String()
+}
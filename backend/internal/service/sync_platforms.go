@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+// dirTransportVersion is the version file skopeo expects in a dir: transport directory.
+const dirTransportVersion = "Directory Transport Version: 1.1\n"
+
+// platformSet is a trimmed manifest list (see trimIndex) and where the per-platform
+// images it references are copied from.
+type platformSet struct {
+	index       []byte               // Trimmed manifest list
+	descriptors []manifestDescriptor // Platforms of index, in list order
+	sources     []string             // Source of the image of every platform
+	tlsVerify   bool                 // TLS verification of the sources
+}
+
+// copyPlatforms copies the platforms in task.Architectures of the source manifest list
+// to dest as a new manifest list holding only those platforms. Skopeo copies either a
+// whole list or a single image, so the image of every platform is copied by digest with
+// --preserve-digests, and the trimmed list (see trimIndex) is then pushed on its own
+// with --multi-arch=index-only. The destination list thus references exactly the
+// per-platform manifests of the source. authFile holds the credentials of the source
+// and dest.
+func (s *syncService) copyPlatforms(ctx context.Context, task *models.SyncTask, req *models.SyncRequest, dest string, destTLSVerify bool, authFile string) error {
+	set, err := s.sourcePlatforms(ctx, task, req, authFile)
+	if err != nil {
+		return err
+	}
+	return s.pushPlatforms(ctx, task, set, retryTimesOrDefault(req.RetryTimes), dest, destTLSVerify, authFile)
+}
+
+// sourcePlatforms inspects the source manifest list and returns the platforms in
+// task.Architectures, copied from the source registry by digest.
+func (s *syncService) sourcePlatforms(ctx context.Context, task *models.SyncTask, req *models.SyncRequest, authFile string) (*platformSet, error) {
+	srcTLSVerify := tlsVerifyOrDefault(req.SrcTLSVerify)
+	raw, err := inspectRawManifest(ctx, task.SourceImage, srcTLSVerify, authFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect source: %w", err)
+	}
+	trimmed, descriptors, err := trimIndex(raw, task.Architectures)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &platformSet{index: trimmed, descriptors: descriptors, tlsVerify: srcTLSVerify}
+	for _, desc := range descriptors {
+		set.sources = append(set.sources, fmt.Sprintf("docker://%s", imageWithDigest(task.SourceImage, desc.Digest)))
+	}
+	return set, nil
+}
+
+// stagePlatforms pulls the image of every platform of set into its own directory under
+// stageDir and returns the set copied from there, so that several destinations are
+// pushed to without pulling the source again.
+func (s *syncService) stagePlatforms(ctx context.Context, task *models.SyncTask, set *platformSet, retryTimes int, stageDir, authFile string) (*platformSet, error) {
+	staged := &platformSet{index: set.index, descriptors: set.descriptors}
+	for i, desc := range set.descriptors {
+		dir := filepath.Join(stageDir, strconv.Itoa(i))
+		if err := os.Mkdir(dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create staging directory: %w", err)
+		}
+		task.AddLog(fmt.Sprintf("Pulling platform %s (%d/%d)", desc.Platform, i+1, len(set.descriptors)))
+		args := copyArgs("", retryTimes, set.tlsVerify, false, set.sources[i], fmt.Sprintf("dir:%s", dir), "--preserve-digests")
+		if err := s.runSkopeo(ctx, task, args, authFile); err != nil {
+			return nil, fmt.Errorf("pulling platform %s failed: %w", desc.Platform, err)
+		}
+		staged.sources = append(staged.sources, fmt.Sprintf("dir:%s", dir))
+	}
+	return staged, nil
+}
+
+// pushPlatforms copies the image of every platform of set to dest by digest, then pushes
+// the trimmed list on its own, see copyPlatforms.
+func (s *syncService) pushPlatforms(ctx context.Context, task *models.SyncTask, set *platformSet, retryTimes int, dest string, destTLSVerify bool, authFile string) error {
+	for i, desc := range set.descriptors {
+		task.AddLog(fmt.Sprintf("Copying platform %s (%d/%d)", desc.Platform, i+1, len(set.descriptors)))
+		args := copyArgs("", retryTimes, set.tlsVerify, destTLSVerify,
+			set.sources[i], fmt.Sprintf("docker://%s", imageWithDigest(dest, desc.Digest)),
+			"--preserve-digests")
+		if err := s.runSkopeo(ctx, task, args, authFile); err != nil {
+			return fmt.Errorf("copying platform %s failed: %w", desc.Platform, err)
+		}
+	}
+
+	indexDir, err := os.MkdirTemp("", "skopeo-index-*")
+	if err != nil {
+		return fmt.Errorf("failed to create manifest list directory: %w", err)
+	}
+	defer os.RemoveAll(indexDir)
+	if err := os.WriteFile(filepath.Join(indexDir, "version"), []byte(dirTransportVersion), 0600); err != nil {
+		return fmt.Errorf("failed to write manifest list: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(indexDir, "manifest.json"), set.index, 0600); err != nil {
+		return fmt.Errorf("failed to write manifest list: %w", err)
+	}
+
+	task.AddLog(fmt.Sprintf("Writing manifest list of %s (%s)", strings.Join(task.Architectures, ", "), manifestDigest(set.index)))
+	args := copyArgs("", retryTimes, false, destTLSVerify,
+		fmt.Sprintf("dir:%s", indexDir), fmt.Sprintf("docker://%s", dest),
+		"--multi-arch=index-only", "--preserve-digests")
+	if err := s.runSkopeo(ctx, task, args, authFile); err != nil {
+		return fmt.Errorf("writing manifest list failed: %w", err)
+	}
+	return nil
+}
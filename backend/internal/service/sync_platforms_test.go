@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+func TestExecuteSync_Architectures(t *testing.T) {
+	dir := fakeSkopeo(t)
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(testIndex), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	req := &models.SyncRequest{
+		SourceImage:   "docker.io/library/nginx:1.27",
+		DestImage:     "registry.example.com:5000/nginx:1.27",
+		Architectures: []string{"linux/arm/v7", "linux/amd64"},
+		Force:         true,
+	}
+	taskID, err := service.CreateSyncTask(req)
+	if err != nil {
+		t.Fatalf("CreateSyncTask failed: %v", err)
+	}
+	task, _ := repo.Get(taskID)
+	if task.Architecture != "linux/arm/v7,linux/amd64" {
+		t.Errorf("Expected comma-separated architecture, got %s", task.Architecture)
+	}
+
+	if err := service.ExecuteSync(taskID, req); err != nil {
+		t.Fatalf("ExecuteSync failed: %v", err)
+	}
+	if task.Status != models.StatusCompleted {
+		t.Fatalf("Expected status completed, got %s: %s", task.Status, task.ErrorOutput)
+	}
+
+	// Both platforms are copied by digest in list order, then the trimmed list on its own
+	data, _ := os.ReadFile(filepath.Join(dir, "calls"))
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []string{
+		"inspect --raw",
+		"--preserve-digests docker://docker.io/library/nginx@sha256:amd64 docker://registry.example.com:5000/nginx@sha256:amd64",
+		"--preserve-digests docker://docker.io/library/nginx@sha256:armv7 docker://registry.example.com:5000/nginx@sha256:armv7",
+		"--multi-arch=index-only --preserve-digests dir:",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d skopeo calls, got %q", len(want), lines)
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, want[i]) {
+			t.Errorf("Expected call %d to contain %q, got %q", i+1, want[i], line)
+		}
+	}
+	if strings.Contains(string(data), "--all") || strings.Contains(string(data), "--override-arch") {
+		t.Errorf("Expected no whole-list or single-platform copy, got %q", lines)
+	}
+
+	// A destination holding the trimmed list is up to date; the fake serves it for both
+	// images, and trimming it again yields the same list
+	trimmed, _, _ := trimIndex([]byte(testIndex), req.Architectures)
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), trimmed, 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	req.Force = false
+	taskID, _ = service.CreateSyncTask(req)
+	if err := service.ExecuteSync(taskID, req); err != nil {
+		t.Fatalf("ExecuteSync failed: %v", err)
+	}
+	task, _ = repo.Get(taskID)
+	if task.Status != models.StatusSkipped {
+		t.Errorf("Expected status skipped, got %s: %s", task.Status, task.ErrorOutput)
+	}
+}
@@ -9,79 +9,392 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/lazycatapps/image-sync/internal/models"
 	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/pkg/timeutil"
+	"github.com/lazycatapps/image-sync/internal/pkg/validator"
 	"github.com/lazycatapps/image-sync/internal/repository"
 
 	"github.com/google/uuid"
 )
 
+// killGracePeriod is how long a cancelled skopeo process may take to exit after
+// SIGTERM before it is killed with SIGKILL.
+const killGracePeriod = 10 * time.Second
+
+var (
+	// ErrTaskFinished is returned when an operation requires an active task
+	// but the task has already reached a terminal status.
+	ErrTaskFinished = errors.New("task already finished")
+
+	// ErrShuttingDown is returned when a new sync is requested while the server is shutting down.
+	// It is also used as the cancellation cause of syncs interrupted by the shutdown.
+	ErrShuttingDown = errors.New("server is shutting down")
+
+	// ErrTaskActive is returned when an operation requires a finished task
+	// but the task is still pending or running.
+	ErrTaskActive = errors.New("task is still pending or running")
+
+	// ErrInvalidQuery is returned when task list parameters cannot be used,
+	// such as a malformed duration or a cursor created for a different sort order.
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrInvalidMetadata is returned when the labels or note of a task update are invalid.
+	ErrInvalidMetadata = errors.New("invalid labels or note")
+
+	// ErrDuplicateTask is wrapped by DuplicateTaskError, returned when a sync request
+	// is attached to an existing task instead of creating a new one.
+	ErrDuplicateTask = errors.New("duplicate sync request")
+
+	// ErrIdempotencyKeyReused is returned when an Idempotency-Key is repeated with a
+	// request for different images or architecture.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different request")
+
+	// ErrTaskNotRetryable is returned when retrying a task that has not failed, been cancelled, been interrupted or partially completed.
+	ErrTaskNotRetryable = errors.New("only failed, cancelled, interrupted or partial tasks can be retried")
+
+	// ErrCredentialsRequired is returned when a task derived from one that used registry
+	// credentials is created without supplying them again.
+	ErrCredentialsRequired = errors.New("registry credentials required")
+)
+
 // SyncService defines the interface for image synchronization operations.
 type SyncService interface {
 	CreateSyncTask(req *models.SyncRequest) (string, error)
+	RetryTask(id string, req *models.SyncRequest) (string, error)
+	CloneTask(id string, req *models.SyncRequest) (string, error)
+	PlanSync(ctx context.Context, req *models.SyncRequest) (*models.SyncPlan, error)
+	PreviewRepoSync(ctx context.Context, req *models.RepoSyncRequest) (*models.RepoSyncPreview, error)
 	GetTask(id string) (*models.SyncTask, error)
 	ExecuteSync(taskID string, req *models.SyncRequest) error
+	EnqueueTask(taskID string, req *models.SyncRequest) (int, error)
+	CancelTask(id, cancelledBy string) error
+	RecoverTasks(resume bool) (int, error)
+	Shutdown(ctx context.Context) error
 	ListTasks(req *models.TaskListRequest) (*models.TaskListResponse, error)
+	TaskStats(req *models.TaskStatsRequest) (*models.TaskStats, error)
+	UpdateTask(id string, req *models.TaskUpdateRequest) (*models.SyncTask, error)
+	CreateJob(job *models.SyncJob, reqs []*models.SyncRequest) error
+	GetJob(id string) (*models.JobSummary, error)
+	ListJobs(req *models.JobListRequest) (*models.JobListResponse, error)
+	CancelJob(id, cancelledBy string) (int, error)
+	LatestJobTasks(job *models.SyncJob) ([]*models.SyncTask, error)
+	DeleteTask(id string) error
+	DeleteTasks(status models.SyncStatus, owner string, endedBefore time.Time) (int, error)
+	PruneTasks(maxAge time.Duration, maxCount int) (int, error)
+	Events() *EventBroker
 }
 
 // syncService implements the SyncService interface.
 type syncService struct {
 	repo    repository.TaskRepository
+	jobs    repository.JobRepository
 	logger  logger.Logger
 	timeout int // Sync operation timeout in seconds
+
+	idempotencyTTL time.Duration // How long an Idempotency-Key maps to the task it created
+	createMu       sync.Mutex    // Serializes duplicate detection and creation of tasks in createTask
+
+	queue        *taskQueue                         // FIFO queue limiting the number of concurrent syncs
+	events       *EventBroker                       // Broadcasts task lifecycle events
+	mu           sync.Mutex                         // Guards running and shuttingDown
+	running      map[string]context.CancelCauseFunc // Cancel functions of running skopeo processes, keyed by task ID
+	shuttingDown bool                               // Set by Shutdown; no new syncs are accepted afterwards
+}
+
+// Reasons reported by DuplicateTaskError.
+const (
+	DuplicateIdempotencyKey = "idempotency-key" // The request repeated an Idempotency-Key
+	DuplicateInFlight       = "in-flight"       // A task for the same images and architecture is pending or running
+)
+
+// DuplicateTaskError is returned when no task was created because the request is a
+// repeat of an existing task, see CreateSyncTask. It wraps ErrDuplicateTask.
+type DuplicateTaskError struct {
+	TaskID string            // ID of the existing task
+	Status models.SyncStatus // Status of the existing task when the request was received
+	Reason string            // DuplicateIdempotencyKey or DuplicateInFlight
+}
+
+func (e *DuplicateTaskError) Error() string {
+	return fmt.Sprintf("%v: task %s (%s)", ErrDuplicateTask, e.TaskID, e.Reason)
+}
+
+// Unwrap returns ErrDuplicateTask.
+func (e *DuplicateTaskError) Unwrap() error {
+	return ErrDuplicateTask
 }
 
 // NewSyncService creates a new SyncService instance.
-func NewSyncService(repo repository.TaskRepository, logger logger.Logger, timeout int) SyncService {
-	return &syncService{
-		repo:    repo,
-		logger:  logger,
-		timeout: timeout,
+// maxConcurrent limits how many skopeo processes run at the same time; further tasks wait in a FIFO queue.
+// idempotencyTTL is how long an Idempotency-Key maps to the task it created (0 disables idempotency keys).
+func NewSyncService(repo repository.TaskRepository, jobs repository.JobRepository, logger logger.Logger, timeout, maxConcurrent int, idempotencyTTL time.Duration) SyncService {
+	s := &syncService{
+		repo:           repo,
+		jobs:           jobs,
+		logger:         logger,
+		timeout:        timeout,
+		idempotencyTTL: idempotencyTTL,
+		events:         NewEventBroker(),
+		running:        make(map[string]context.CancelCauseFunc),
 	}
+	s.queue = newTaskQueue(maxConcurrent, func(taskID string, req *models.SyncRequest) {
+		if err := s.ExecuteSync(taskID, req); err != nil {
+			s.logger.Error("[%s] Sync execution failed: %v", taskID, err)
+		}
+	})
+	return s
 }
 
 // CreateSyncTask creates a new sync task record in the repository.
 // It generates a unique task ID and initializes the task with pending status.
+//
+// No task is created for a repeated request, so that clients retrying on timeouts do
+// not start the same copy twice; a *DuplicateTaskError with the existing task is returned instead:
+//   - if req has an IdempotencyKey that created a task of the same owner within the TTL;
+//   - if a task of the same owner for the same source, destinations and architecture
+//     is still pending or running. Additional destinations must match in order, and
+//     AllowPartial must match as well.
+//
+// Returns ErrIdempotencyKeyReused if the key created a task for different images or
+// architecture, and ErrShuttingDown if the server is shutting down.
 func (s *syncService) CreateSyncTask(req *models.SyncRequest) (string, error) {
-	taskID := uuid.New().String()
+	return s.createTask(req, "")
+}
 
-	// Default to "all" architectures if not specified
-	architecture := req.Architecture
-	if architecture == "" {
-		architecture = "all"
+// RetryTask creates a new task that re-runs a failed, cancelled, interrupted or partial task.
+// req is the original request, usually reconstructed with SyncTask.Request, completed
+// with the credentials the original task used.
+// Returns ErrTaskNotRetryable if the task finished successfully or is still active,
+// ErrCredentialsRequired if credentials the original task used are missing, and a
+// *DuplicateTaskError if the same sync is already pending or running.
+func (s *syncService) RetryTask(id string, req *models.SyncRequest) (string, error) {
+	parent, err := s.repo.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if !parent.Status.IsRetryable() {
+		return "", ErrTaskNotRetryable
+	}
+	return s.createChildTask(parent, req)
+}
+
+// CloneTask creates a new task from an existing task of any status, with req being
+// the original request modified by the caller (e.g. a different destination tag).
+// Unlike a retry, the clone does not belong to the job of the original task.
+// Returns ErrCredentialsRequired if credentials the original task used for an
+// unchanged registry are missing, and a *DuplicateTaskError if the same sync is
+// already pending or running.
+func (s *syncService) CloneTask(id string, req *models.SyncRequest) (string, error) {
+	parent, err := s.repo.Get(id)
+	if err != nil {
+		return "", err
+	}
+	req.JobID = ""
+	return s.createChildTask(parent, req)
+}
+
+// createChildTask creates a task linked to parent. Credentials are never stored with
+// a task, so a request for a registry that needed them must carry them again.
+func (s *syncService) createChildTask(parent *models.SyncTask, req *models.SyncRequest) (string, error) {
+	if parent.SourceAuth && req.SourceUsername == "" &&
+		extractRegistry(req.SourceImage) == extractRegistry(parent.SourceImage) {
+		return "", fmt.Errorf("%w for source registry %s", ErrCredentialsRequired, extractRegistry(req.SourceImage))
+	}
+	if parent.DestAuth && req.DestUsername == "" &&
+		extractRegistry(req.DestImage) == extractRegistry(parent.DestImage) {
+		return "", fmt.Errorf("%w for destination registry %s", ErrCredentialsRequired, extractRegistry(req.DestImage))
+	}
+	for _, dest := range req.Destinations {
+		if dest.Username != "" {
+			continue
+		}
+		for _, result := range parent.Destinations[min(1, len(parent.Destinations)):] {
+			if result.Auth && extractRegistry(dest.Image) == extractRegistry(result.Image) {
+				return "", fmt.Errorf("%w for destination registry %s", ErrCredentialsRequired, extractRegistry(dest.Image))
+			}
+		}
 	}
+	return s.createTask(req, parent.ID)
+}
+
+// createTask stores a new pending task for req, optionally linked to a parent task.
+// Every task is created here, so the duplicate checks described at CreateSyncTask
+// apply to retries, clones and job items as well.
+func (s *syncService) createTask(req *models.SyncRequest, parentID string) (string, error) {
+	s.mu.Lock()
+	shuttingDown := s.shuttingDown
+	s.mu.Unlock()
+	if shuttingDown {
+		return "", ErrShuttingDown
+	}
+
+	architecture := taskArchitecture(req)
+
+	// Detection and creation must be atomic, or parallel retries could all create a task
+	s.createMu.Lock()
+	defer s.createMu.Unlock()
+
+	if req.IdempotencyKey != "" && s.idempotencyTTL > 0 {
+		tasks, _, err := s.repo.Query(&repository.TaskQuery{
+			Owner:          req.Owner,
+			IdempotencyKey: req.IdempotencyKey,
+			StartedAfter:   time.Now().Add(-s.idempotencyTTL),
+			Limit:          1,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to look up idempotency key: %w", err)
+		}
+		if len(tasks) > 0 {
+			task := tasks[0]
+			if task.SourceImage != req.SourceImage || task.DestImage != req.DestImage || task.Architecture != architecture {
+				return "", ErrIdempotencyKeyReused
+			}
+			s.logger.Info("[%s] Request with repeated idempotency key attached to task", task.ID)
+			return "", &DuplicateTaskError{TaskID: task.ID, Status: task.Status, Reason: DuplicateIdempotencyKey}
+		}
+	}
+
+	// Images cannot contain glob characters, so the filters match substrings; compare exactly below
+	tasks, _, err := s.repo.Query(&repository.TaskQuery{
+		Owner:        req.Owner,
+		Active:       true,
+		Source:       req.SourceImage,
+		Dest:         req.DestImage,
+		Architecture: architecture,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up in-flight tasks: %w", err)
+	}
+	for _, task := range tasks {
+		if task.SourceImage == req.SourceImage && task.DestImage == req.DestImage && sameDestinations(task, req) {
+			s.logger.Info("[%s] Duplicate request attached to in-flight task", task.ID)
+			return "", &DuplicateTaskError{TaskID: task.ID, Status: task.Status, Reason: DuplicateInFlight}
+		}
+	}
+
+	taskID := uuid.New().String()
 
 	task := models.NewSyncTask(taskID, req.SourceImage, req.DestImage, architecture)
+	task.Architectures = slices.Clone(req.Architectures)
+	// Record the non-secret options so that the request can be reconstructed later
+	task.RetryTimes = retryTimesOrDefault(req.RetryTimes)
+	task.SrcTLSVerify = tlsVerifyOrDefault(req.SrcTLSVerify)
+	task.DestTLSVerify = tlsVerifyOrDefault(req.DestTLSVerify)
+	task.SourceAuth = req.SourceUsername != "" && req.SourcePassword != ""
+	task.DestAuth = req.DestUsername != "" && req.DestPassword != ""
+	task.Force = req.Force
+	if len(req.Destinations) > 0 {
+		task.Destinations = destinationResults(fanOutDestinations(req))
+		task.AllowPartial = req.AllowPartial
+	}
+	task.ConfigName = req.ConfigName
+	task.ParentTaskID = parentID
+	task.Owner = req.Owner
+	task.OwnerEmail = req.OwnerEmail
+	task.Labels = models.CopyLabels(req.Labels)
+	task.Note = req.Note
+	task.IdempotencyKey = req.IdempotencyKey
+	task.JobID = req.JobID
+	task.ScheduleID = req.ScheduleID
+	task.WatchID = req.WatchID
+	task.HookID = req.HookID
 
 	if err := s.repo.Create(task); err != nil {
 		return "", fmt.Errorf("failed to create task: %w", err)
 	}
+	s.publish(models.EventCreated, task)
 
 	return taskID, nil
 }
 
+// taskArchitecture returns the architecture recorded for the task of req: the
+// comma-separated platforms of Architectures, or Architecture defaulting to "all".
+func taskArchitecture(req *models.SyncRequest) string {
+	if len(req.Architectures) > 0 {
+		return strings.Join(req.Architectures, ",")
+	}
+	if req.Architecture == "" {
+		return "all"
+	}
+	return req.Architecture
+}
+
 // GetTask retrieves a task by ID from the repository.
 func (s *syncService) GetTask(id string) (*models.SyncTask, error) {
 	return s.repo.Get(id)
 }
 
+// EnqueueTask places a pending task in the FIFO queue. The task is started as soon as
+// fewer than maxConcurrent syncs are running.
+// Returns the task's queue position, or 0 if it was started immediately.
+// Returns ErrShuttingDown if the server is shutting down.
+func (s *syncService) EnqueueTask(taskID string, req *models.SyncRequest) (int, error) {
+	task, err := s.repo.Get(taskID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get task: %w", err)
+	}
+	if task.Status != models.StatusPending {
+		return 0, fmt.Errorf("task is %s, only pending tasks can be queued", task.Status)
+	}
+
+	// Hold the lock while enqueueing so that Shutdown cannot drain the queue in between
+	s.mu.Lock()
+	if s.shuttingDown {
+		s.mu.Unlock()
+		return 0, ErrShuttingDown
+	}
+	position := s.queue.Enqueue(task, req)
+	s.mu.Unlock()
+	if position > 0 {
+		task.AddLog(fmt.Sprintf("Task queued at position %d", position))
+		s.logger.Info("[%s] Task queued at position %d (%d running)", taskID, position, s.queue.Running())
+	}
+	return position, nil
+}
+
 // ExecuteSync executes the image synchronization operation using skopeo.
 // It builds the skopeo command, executes it with timeout, captures output, and updates task status.
-// This method runs asynchronously and should be called in a goroutine.
+// This method blocks until the sync finishes; it is normally invoked by the task queue.
 func (s *syncService) ExecuteSync(taskID string, req *models.SyncRequest) error {
 	task, err := s.repo.Get(taskID)
 	if err != nil {
 		return fmt.Errorf("failed to get task: %w", err)
 	}
 
+	// Create context with timeout; its cancel function is registered so that
+	// CancelTask and Shutdown can stop the skopeo process.
+	ctx, cancelTimeout := context.WithTimeout(context.Background(), time.Duration(s.timeout)*time.Second)
+	defer cancelTimeout()
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// The task may have been cancelled while it was still pending
+	s.mu.Lock()
+	if task.Status == models.StatusCancelled {
+		s.mu.Unlock()
+		s.logger.Info("[%s] Task was cancelled before it started", taskID)
+		return nil
+	}
+	s.running[taskID] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, taskID)
+		s.mu.Unlock()
+	}()
+
 	// Update task status to running
 	task.Status = models.StatusRunning
 	task.Message = "Syncing image..."
@@ -90,6 +403,12 @@ func (s *syncService) ExecuteSync(taskID string, req *models.SyncRequest) error
 	}
 
 	task.AddLog(fmt.Sprintf("Task started at %s", time.Now().Format(time.RFC3339)))
+	s.publish(models.EventStarted, task)
+
+	// Syncs with additional destinations pull the source once and copy it to all of them
+	if len(req.Destinations) > 0 {
+		return s.executeFanOut(ctx, task, req)
+	}
 
 	// Create temporary auth file if credentials are provided
 	authFile, err := createAuthFile(
@@ -108,20 +427,57 @@ func (s *syncService) ExecuteSync(taskID string, req *models.SyncRequest) error
 		}()
 	}
 
+	// Skip the copy if the destination already holds the same content
+	if !req.Force {
+		upToDate := s.destinationUpToDate(ctx, task, req, authFile)
+		if ctx.Err() != nil {
+			s.finishSync(ctx, task, ctx.Err())
+			return nil
+		}
+		if upToDate {
+			s.finishSkipped(task)
+			return nil
+		}
+	}
+
+	// Skopeo cannot copy a subset of platforms in one run
+	if len(task.Architectures) > 0 {
+		s.logger.Info("[%s] Starting sync: %s -> %s (%s)", taskID, req.SourceImage, req.DestImage, task.Architecture)
+		s.finishSync(ctx, task, s.copyPlatforms(ctx, task, req, task.DestImage, tlsVerifyOrDefault(req.DestTLSVerify), authFile))
+		return nil
+	}
+
 	// Build skopeo command arguments
 	args := s.buildSkopeoArgs(task, req)
 
-	// Log sanitized command (credentials masked)
-	sanitizedCmd := sanitizeCommand(args)
 	s.logger.Info("[%s] Starting sync: %s -> %s", taskID, req.SourceImage, req.DestImage)
-	task.AddLog(fmt.Sprintf("Executing: %s", sanitizedCmd))
+	wait, err := s.startSkopeo(ctx, task, args, authFile)
+	if err != nil {
+		// A cancellation just before the start makes it fail with ctx's error
+		if ctx.Err() != nil {
+			s.finishSync(ctx, task, err)
+			return nil
+		}
+		return s.handleTaskError(task, "Failed to start command", err)
+	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.timeout)*time.Second)
-	defer cancel()
+	s.finishSync(ctx, task, wait())
+	return nil
+}
+
+// startSkopeo starts skopeo with args, logging the sanitized command, and returns the
+// function that waits for it to exit. The output of skopeo is added to the task log.
+// On cancellation or timeout of ctx skopeo receives SIGTERM first and is killed if it
+// has not exited after killGracePeriod.
+func (s *syncService) startSkopeo(ctx context.Context, task *models.SyncTask, args []string, authFile string) (func() error, error) {
+	// Log sanitized command (credentials masked)
+	task.AddLog(fmt.Sprintf("Executing: %s", sanitizeCommand(args)))
 
-	// Execute skopeo command
 	cmd := exec.CommandContext(ctx, "skopeo", args...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = killGracePeriod
 
 	// Set REGISTRY_AUTH_FILE environment variable if auth file exists
 	if authFile != "" {
@@ -130,16 +486,16 @@ func (s *syncService) ExecuteSync(taskID string, req *models.SyncRequest) error
 
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
-		return s.handleTaskError(task, "Failed to create stdout pipe", err)
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
 	stderrPipe, err := cmd.StderrPipe()
 	if err != nil {
-		return s.handleTaskError(task, "Failed to create stderr pipe", err)
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
-		return s.handleTaskError(task, "Failed to start command", err)
+		return nil, err
 	}
 
 	// Read command output in parallel goroutines
@@ -149,34 +505,68 @@ func (s *syncService) ExecuteSync(taskID string, req *models.SyncRequest) error
 	go s.readOutput(task, stdoutPipe, &outputWg)
 	go s.readOutput(task, stderrPipe, &outputWg)
 
-	// Wait for command to complete
-	err = cmd.Wait()
+	return func() error {
+		// Wait for command to complete
+		err := cmd.Wait()
 
-	// Check if timeout occurred
-	if ctx.Err() == context.DeadlineExceeded {
-		task.AddLog(fmt.Sprintf("Timeout exceeded (%ds)", s.timeout))
-		s.logger.Error("[%s] Sync timeout after %ds", taskID, s.timeout)
-		err = fmt.Errorf("command timeout after %ds", s.timeout)
-	}
+		// Wait for output goroutines to finish (with timeout)
+		done := make(chan struct{})
+		go func() {
+			outputWg.Wait()
+			close(done)
+		}()
 
-	// Wait for output goroutines to finish (with timeout)
-	done := make(chan struct{})
-	go func() {
-		outputWg.Wait()
-		close(done)
-	}()
+		select {
+		case <-done:
+			// Output reading completed
+		case <-time.After(5 * time.Second):
+			s.logger.Error("[%s] WARNING: Output reading timed out", task.ID)
+		}
+		return err
+	}, nil
+}
+
+// finishSync records the final status of a sync. The result of the copy comes first: a
+// copy that succeeded (err is nil) completed even if ctx was stopped after it finished.
+// Otherwise a timeout, cancellation or shutdown interruption of ctx takes precedence
+// over err, as stopping skopeo makes it fail. All log streams of the task are closed.
+func (s *syncService) finishSync(ctx context.Context, task *models.SyncTask, err error) {
+	taskID := task.ID
+
+	if err == nil && ctx.Err() != nil {
+		// The cancellation arrived after the copy had already succeeded
+		s.mu.Lock()
+		if task.CancelledAt != nil {
+			task.AddLog("Cancellation ignored, the copy had already finished")
+			task.CancelledBy, task.CancelledAt = "", nil
+		}
+		s.mu.Unlock()
+	}
 
-	select {
-	case <-done:
-		// Output reading completed
-	case <-time.After(5 * time.Second):
-		s.logger.Error("[%s] WARNING: Output reading timed out", taskID)
+	// Check if timeout, cancellation or a shutdown interruption stopped the copy
+	cancelled, interrupted := false, false
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			task.AddLog(fmt.Sprintf("Timeout exceeded (%ds)", s.timeout))
+			s.logger.Error("[%s] Sync timeout after %ds", taskID, s.timeout)
+			err = fmt.Errorf("command timeout after %ds", s.timeout)
+		} else if context.Cause(ctx) == ErrShuttingDown {
+			interrupted = true
+		} else if ctx.Err() == context.Canceled {
+			cancelled = true
+		}
 	}
 
 	endTime := time.Now()
 
 	// Finalize task based on result
-	if err != nil {
+	if interrupted {
+		task.AddLog(fmt.Sprintf("Sync interrupted by server shutdown at %s", endTime.Format(time.RFC3339)))
+		s.logger.Info("[%s] Sync interrupted by shutdown", taskID)
+	} else if cancelled {
+		task.AddLog(fmt.Sprintf("Sync cancelled at %s", endTime.Format(time.RFC3339)))
+		s.logger.Info("[%s] Sync cancelled", taskID)
+	} else if err != nil {
 		task.AddLog(fmt.Sprintf("Sync failed: %v", err))
 		s.logger.Error("[%s] Sync failed: %v", taskID, err)
 	} else {
@@ -184,14 +574,18 @@ func (s *syncService) ExecuteSync(taskID string, req *models.SyncRequest) error
 		s.logger.Info("[%s] Sync completed successfully", taskID)
 	}
 
-	// Close all log listeners (SSE connections)
-	task.CloseAllLogListeners()
-
 	// Update task with final status
 	task.EndTime = &endTime
 	task.Output = strings.Join(task.GetLogLines(), "\n")
 
-	if err != nil {
+	if interrupted {
+		task.Status = models.StatusInterrupted
+		task.Message = "Interrupted by server shutdown"
+		task.Resumable = true
+	} else if cancelled {
+		task.Status = models.StatusCancelled
+		task.Message = "Sync cancelled"
+	} else if err != nil {
 		task.Status = models.StatusFailed
 		task.Message = "Sync failed"
 		task.ErrorOutput = err.Error()
@@ -203,35 +597,277 @@ func (s *syncService) ExecuteSync(taskID string, req *models.SyncRequest) error
 	if updateErr := s.repo.Update(task); updateErr != nil {
 		s.logger.Error("[%s] Failed to update task status: %v", taskID, updateErr)
 	}
+	// Wake log streams so they report the final status and end
+	s.notifyFinished(task)
+}
+
+// CancelTask stops a pending or running task.
+// A pending task is marked as cancelled immediately. For a running task the skopeo
+// process is terminated (SIGTERM, then SIGKILL after killGracePeriod) and ExecuteSync
+// records the cancelled status once the process has exited.
+// Returns ErrTaskFinished if the task has already reached a terminal status.
+func (s *syncService) CancelTask(id, cancelledBy string) error {
+	task, err := s.repo.Get(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task.Status.IsTerminal() {
+		return ErrTaskFinished
+	}
+
+	now := time.Now()
+	task.CancelledBy = cancelledBy
+	task.CancelledAt = &now
+
+	if cancel, ok := s.running[id]; ok {
+		task.Message = "Cancelling..."
+		task.AddLog(fmt.Sprintf("Cancellation requested by %s", displayUser(cancelledBy)))
+		s.logger.Info("[%s] Cancellation requested by %s", id, displayUser(cancelledBy))
+		cancel(nil)
+		return nil
+	}
+
+	// Task has not started yet: take it out of the queue and finalize it right away
+	s.queue.Remove(id)
+	task.AddLog(fmt.Sprintf("Task cancelled by %s before it started", displayUser(cancelledBy)))
+	task.Status = models.StatusCancelled
+	task.Message = "Sync cancelled"
+	task.EndTime = &now
+	task.Output = strings.Join(task.GetLogLines(), "\n")
+	if err := s.repo.Update(task); err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	s.notifyFinished(task)
 
+	s.logger.Info("[%s] Pending task cancelled by %s", id, displayUser(cancelledBy))
 	return nil
 }
 
-// buildSkopeoArgs constructs the skopeo command arguments based on the sync request.
-// It handles TLS verification, credentials, architecture selection, and image addresses.
-func (s *syncService) buildSkopeoArgs(task *models.SyncTask, req *models.SyncRequest) []string {
-	args := []string{"copy"}
+// RecoverTasks handles tasks that a previous process left behind. Tasks still pending
+// or running can no longer make progress and are marked as interrupted with an
+// explanatory log line; together with tasks interrupted by a graceful shutdown they
+// are considered for resuming.
+// If resume is true, those whose request can be reconstructed without credentials
+// (credentials are never stored) are queued again.
+// Must be called once at startup, before new tasks are accepted.
+// Returns the number of interrupted tasks found.
+func (s *syncService) RecoverTasks(resume bool) (int, error) {
+	var stale []*models.SyncTask
+	for _, status := range []models.SyncStatus{models.StatusRunning, models.StatusPending} {
+		tasks, _, err := s.repo.Query(&repository.TaskQuery{Status: status, SortOrder: "asc"})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list %s tasks: %w", status, err)
+		}
+		stale = append(stale, tasks...)
+	}
 
-	// Add retry mechanism for network failures
-	retryTimes := 3
-	if req.RetryTimes != nil {
-		retryTimes = *req.RetryTimes
+	for _, queried := range stale {
+		task, err := s.loadTask(queried.ID)
+		if err != nil {
+			continue
+		}
+		s.interruptTask(task, fmt.Sprintf("Task interrupted by a server restart while %s", task.Status), "Interrupted by server restart")
 	}
-	args = append(args, "--retry-times", fmt.Sprintf("%d", retryTimes))
 
-	// Add TLS verification flags
-	srcTLSVerify := true
-	if req.SrcTLSVerify != nil {
-		srcTLSVerify = *req.SrcTLSVerify
+	interrupted, _, err := s.repo.Query(&repository.TaskQuery{Status: models.StatusInterrupted, SortOrder: "asc"})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list interrupted tasks: %w", err)
 	}
-	destTLSVerify := true
-	if req.DestTLSVerify != nil {
-		destTLSVerify = *req.DestTLSVerify
+
+	recovered := 0
+	for _, queried := range interrupted {
+		if !queried.Resumable {
+			continue
+		}
+		task, err := s.loadTask(queried.ID)
+		if err != nil {
+			continue
+		}
+		recovered++
+		task.Resumable = false
+
+		if !resume {
+			if err := s.repo.Update(task); err != nil {
+				s.logger.Error("[%s] Failed to update task: %v", task.ID, err)
+			}
+			continue
+		}
+		req, complete := task.Request()
+		if !complete {
+			task.AddLog("Not resumed automatically: the request used registry credentials, which are not stored")
+			if err := s.repo.Update(task); err != nil {
+				s.logger.Error("[%s] Failed to update task: %v", task.ID, err)
+			}
+			continue
+		}
+		task.AddLog("Resuming automatically (--resume-interrupted)")
+		task.Status = models.StatusPending
+		task.Message = "Task created"
+		task.EndTime = nil
+		task.Output = ""
+		if err := s.repo.Update(task); err != nil {
+			s.logger.Error("[%s] Failed to reset task for resume: %v", task.ID, err)
+			continue
+		}
+		if _, err := s.EnqueueTask(task.ID, req); err != nil {
+			s.logger.Error("[%s] Failed to resume task: %v", task.ID, err)
+		}
 	}
 
-	args = append(args, fmt.Sprintf("--src-tls-verify=%v", srcTLSVerify))
-	args = append(args, fmt.Sprintf("--dest-tls-verify=%v", destTLSVerify))
+	return recovered, nil
+}
+
+// loadTask loads a task found by a query with its logs, which queries may leave out,
+// so that the lines added to it are stored after the existing ones.
+func (s *syncService) loadTask(id string) (*models.SyncTask, error) {
+	task, err := s.repo.Get(id)
+	if err != nil {
+		s.logger.Error("[%s] Failed to load task: %v", id, err)
+		return nil, err
+	}
+	return task, nil
+}
+
+// Shutdown stops accepting new syncs and drains the service.
+// Tasks still waiting in the queue are marked as interrupted right away. Running syncs
+// may finish until ctx is done; the remaining ones are then terminated like a
+// cancellation and recorded as interrupted. Log streams are closed as tasks finish.
+// Returns ctx.Err() if syncs had to be interrupted.
+func (s *syncService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shuttingDown = true
+	s.mu.Unlock()
+
+	for _, task := range s.queue.Drain() {
+		s.interruptTask(task, "Task interrupted by server shutdown before it started", "Interrupted by server shutdown")
+	}
 
+	running := s.queue.Running()
+	if running == 0 {
+		return nil
+	}
+	s.logger.Info("Waiting for %d running sync(s) to finish", running)
+	err := s.queue.Wait(ctx)
+	if err == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	for taskID, cancel := range s.running {
+		s.logger.Info("[%s] Interrupting sync for shutdown", taskID)
+		cancel(ErrShuttingDown)
+	}
+	s.mu.Unlock()
+
+	// skopeo is killed after killGracePeriod at the latest; allow a little extra for the final update
+	waitCtx, cancel := context.WithTimeout(context.Background(), killGracePeriod+5*time.Second)
+	defer cancel()
+	if waitErr := s.queue.Wait(waitCtx); waitErr != nil {
+		s.logger.Error("Interrupted syncs did not exit in time: %v", waitErr)
+	}
+	return err
+}
+
+// interruptTask finalizes a task that did not run to completion because the server stopped.
+// The task is flagged as resumable so that the next RecoverTasks can queue it again.
+func (s *syncService) interruptTask(task *models.SyncTask, logLine, message string) {
+	now := time.Now()
+	task.AddLog(fmt.Sprintf("%s (%s)", logLine, now.Format(time.RFC3339)))
+	task.Status = models.StatusInterrupted
+	task.Message = message
+	task.Resumable = true
+	task.QueuePosition = 0
+	task.EndTime = &now
+	task.Output = strings.Join(task.GetLogLines(), "\n")
+	if err := s.repo.Update(task); err != nil {
+		s.logger.Error("[%s] Failed to mark task as interrupted: %v", task.ID, err)
+		return
+	}
+	s.notifyFinished(task)
+	s.logger.Info("[%s] Marked as interrupted (%s -> %s)", task.ID, task.SourceImage, task.DestImage)
+}
+
+// displayUser returns a printable name for a user identifier, which is empty
+// when OIDC authentication is disabled.
+func displayUser(user string) string {
+	if user == "" {
+		return "anonymous"
+	}
+	return user
+}
+
+// destinationUpToDate reports whether the destination already holds the manifest a copy
+// would produce, by comparing the source and destination digests for the task's
+// architecture. Any failure to resolve a digest is logged and treated as "not up to date",
+// so the copy proceeds as usual.
+func (s *syncService) destinationUpToDate(ctx context.Context, task *models.SyncTask, req *models.SyncRequest, authFile string) bool {
+	task.AddLog("Comparing source and destination digests")
+
+	srcDigest, err := copiedSourceDigest(ctx, task, tlsVerifyOrDefault(req.SrcTLSVerify), authFile)
+	if err != nil {
+		task.AddLog(fmt.Sprintf("Digest check skipped: cannot resolve source digest: %v", err))
+		return false
+	}
+	task.AddLog(fmt.Sprintf("Source digest: %s", srcDigest))
+
+	destDigest, err := copiedDestDigest(ctx, task, task.DestImage, tlsVerifyOrDefault(req.DestTLSVerify), authFile)
+	if errors.Is(err, ErrImageNotFound) {
+		task.AddLog("Destination image does not exist yet")
+		return false
+	}
+	if err != nil {
+		task.AddLog(fmt.Sprintf("Digest check skipped: cannot resolve destination digest: %v", err))
+		return false
+	}
+	task.AddLog(fmt.Sprintf("Destination digest: %s", destDigest))
+
+	return srcDigest == destDigest
+}
+
+// copiedSourceDigest returns the digest a copy of the task's source produces at its
+// destinations: that of the trimmed manifest list for a subset of platforms, see
+// resolveTrimmedDigest, and otherwise as for resolveManifestDigest.
+func copiedSourceDigest(ctx context.Context, task *models.SyncTask, tlsVerify bool, authFile string) (string, error) {
+	if len(task.Architectures) > 0 {
+		return resolveTrimmedDigest(ctx, task.SourceImage, task.Architectures, tlsVerify, authFile)
+	}
+	return resolveManifestDigest(ctx, task.SourceImage, task.Architecture, tlsVerify, authFile)
+}
+
+// copiedDestDigest returns the digest of a destination of the task to compare with
+// copiedSourceDigest. For a subset of platforms that is the digest of the whole manifest.
+func copiedDestDigest(ctx context.Context, task *models.SyncTask, image string, tlsVerify bool, authFile string) (string, error) {
+	architecture := task.Architecture
+	if len(task.Architectures) > 0 {
+		architecture = "all"
+	}
+	return resolveManifestDigest(ctx, image, architecture, tlsVerify, authFile)
+}
+
+// finishSkipped finalizes a task whose copy was skipped because the destination is up to date.
+func (s *syncService) finishSkipped(task *models.SyncTask) {
+	endTime := time.Now()
+	task.AddLog("Destination already has the same digest, skipping copy (set force to copy anyway)")
+	s.logger.Info("[%s] Sync skipped, destination is up to date", task.ID)
+
+	task.EndTime = &endTime
+	task.Output = strings.Join(task.GetLogLines(), "\n")
+	task.Status = models.StatusSkipped
+	task.Message = "Destination already up to date"
+
+	if err := s.repo.Update(task); err != nil {
+		s.logger.Error("[%s] Failed to update task status: %v", task.ID, err)
+	}
+	s.notifyFinished(task)
+}
+
+// buildSkopeoArgs constructs the skopeo command arguments based on the sync request.
+// It handles TLS verification, credentials, architecture selection, and image addresses.
+func (s *syncService) buildSkopeoArgs(task *models.SyncTask, req *models.SyncRequest) []string {
 	// Credentials are now handled via REGISTRY_AUTH_FILE environment variable
 	// No longer adding --src-creds or --dest-creds to command line
 	if req.SourceUsername != "" && req.SourcePassword != "" {
@@ -243,26 +879,65 @@ func (s *syncService) buildSkopeoArgs(task *models.SyncTask, req *models.SyncReq
 
 	// Handle architecture selection
 	if task.Architecture == "all" {
-		args = append(args, "--all")
 		task.AddLog("Copying all architectures")
 	} else if task.Architecture != "" {
+		task.AddLog(fmt.Sprintf("Copying architecture: %s", task.Architecture))
+	}
+
+	return copyArgs(task.Architecture, retryTimesOrDefault(req.RetryTimes),
+		tlsVerifyOrDefault(req.SrcTLSVerify), tlsVerifyOrDefault(req.DestTLSVerify),
+		fmt.Sprintf("docker://%s", task.SourceImage), fmt.Sprintf("docker://%s", task.DestImage))
+}
+
+// copyArgs returns the arguments of a skopeo copy from src to dest, which include their
+// transport (e.g. "docker://"), with additional flags. Architecture "all" copies all
+// platforms of a manifest list, "os/arch[/variant]" a single one, and an empty
+// architecture the image as is.
+func copyArgs(architecture string, retryTimes int, srcTLSVerify, destTLSVerify bool, src, dest string, flags ...string) []string {
+	args := []string{"copy"}
+
+	// Add retry mechanism for network failures
+	args = append(args, "--retry-times", fmt.Sprintf("%d", retryTimes))
+
+	// Add TLS verification flags
+	args = append(args, fmt.Sprintf("--src-tls-verify=%v", srcTLSVerify))
+	args = append(args, fmt.Sprintf("--dest-tls-verify=%v", destTLSVerify))
+
+	// Handle architecture selection
+	if architecture == "all" {
+		args = append(args, "--all")
+	} else if architecture != "" {
 		// Parse architecture format: os/arch or os/arch/variant
-		parts := strings.Split(task.Architecture, "/")
+		parts := strings.Split(architecture, "/")
 		if len(parts) >= 2 {
 			args = append(args, "--override-os", parts[0])
 			args = append(args, "--override-arch", parts[1])
 			if len(parts) > 2 {
 				args = append(args, "--override-variant", parts[2])
 			}
-			task.AddLog(fmt.Sprintf("Copying architecture: %s", task.Architecture))
 		}
 	}
 
+	args = append(args, flags...)
+
 	// Add source and destination image addresses
-	args = append(args, fmt.Sprintf("docker://%s", task.SourceImage))
-	args = append(args, fmt.Sprintf("docker://%s", task.DestImage))
+	return append(args, src, dest)
+}
 
-	return args
+// retryTimesOrDefault returns the requested retry times, defaulting to 3.
+func retryTimesOrDefault(retryTimes *int) int {
+	if retryTimes == nil {
+		return 3
+	}
+	return *retryTimes
+}
+
+// tlsVerifyOrDefault returns the requested TLS verification flag, defaulting to true.
+func tlsVerifyOrDefault(tlsVerify *bool) bool {
+	if tlsVerify == nil {
+		return true
+	}
+	return *tlsVerify
 }
 
 // readOutput reads command output from a pipe and adds it to the task log.
@@ -276,8 +951,8 @@ func (s *syncService) readOutput(task *models.SyncTask, pipe io.ReadCloser, wg *
 		line, err := reader.ReadString('\n')
 		if err != nil {
 			// Handle any remaining partial line
-			if line != "" {
-				task.AddLog(strings.TrimSpace(line))
+			if line = strings.TrimSpace(line); line != "" {
+				s.addOutputLine(task, line)
 			}
 			// EOF is normal, only log other errors
 			if err != io.EOF {
@@ -287,11 +962,21 @@ func (s *syncService) readOutput(task *models.SyncTask, pipe io.ReadCloser, wg *
 		}
 		line = strings.TrimSpace(line)
 		if line != "" {
-			task.AddLog(line)
+			s.addOutputLine(task, line)
 		}
 	}
 }
 
+// addOutputLine records a line of skopeo output and the copy progress parsed from it.
+func (s *syncService) addOutputLine(task *models.SyncTask, line string) {
+	// Progress is updated first so that log streams see it together with the line
+	progressed := task.ParseProgress(line)
+	task.AddLog(line)
+	if progressed {
+		s.publish(models.EventProgress, task)
+	}
+}
+
 // handleTaskError updates the task with error information and marks it as failed.
 func (s *syncService) handleTaskError(task *models.SyncTask, message string, err error) error {
 	task.AddLog(fmt.Sprintf("Error: %v", err))
@@ -304,35 +989,36 @@ func (s *syncService) handleTaskError(task *models.SyncTask, message string, err
 	if updateErr := s.repo.Update(task); updateErr != nil {
 		s.logger.Error("[%s] Failed to update task: %v", task.ID, updateErr)
 	}
+	s.notifyFinished(task)
 
 	s.logger.Error("[%s] %s: %v", task.ID, message, err)
 	return fmt.Errorf("%s: %w", message, err)
 }
 
-// ListTasks retrieves a paginated and filtered list of sync tasks.
-// It supports filtering by status, sorting, and pagination.
-func (s *syncService) ListTasks(req *models.TaskListRequest) (*models.TaskListResponse, error) {
-	tasks, err := s.repo.List()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list tasks: %w", err)
-	}
+// notifyFinished wakes the log streams of a task that reached a terminal status
+// and broadcasts the final status to event subscribers.
+func (s *syncService) notifyFinished(task *models.SyncTask) {
+	task.Notify()
+	s.publish(models.TaskEventType(task.Status), task)
+}
 
-	// Filter by status if specified
-	filtered := tasks
-	if req.Status != "" {
-		filtered = []*models.SyncTask{}
-		for _, task := range tasks {
-			if task.Status == req.Status {
-				filtered = append(filtered, task)
-			}
-		}
-	}
+// publish broadcasts an event with a snapshot of task to event subscribers.
+func (s *syncService) publish(eventType models.TaskEventType, task *models.SyncTask) {
+	s.events.Publish(models.NewTaskEvent(eventType, task))
+}
 
-	// Sort tasks
-	sortTasks(filtered, req.SortBy, req.SortOrder)
+// Events returns the broker that broadcasts task lifecycle events.
+func (s *syncService) Events() *EventBroker {
+	return s.events
+}
 
-	// Pagination
-	total := len(filtered)
+// ListTasks retrieves a paginated and filtered list of sync tasks.
+// It supports filtering, sorting, and page or cursor based pagination; the work is
+// delegated to the repository so that persistent stores can do it in their query engine.
+// With a cursor, the page starts right after the last task of the previous page, which
+// stays stable while new tasks are created; the page number is ignored.
+// Returns an error wrapping ErrInvalidQuery for malformed parameters.
+func (s *syncService) ListTasks(req *models.TaskListRequest) (*models.TaskListResponse, error) {
 	page := req.Page
 	if page < 1 {
 		page = 1
@@ -345,81 +1031,80 @@ func (s *syncService) ListTasks(req *models.TaskListRequest) (*models.TaskListRe
 		pageSize = 100
 	}
 
-	start := (page - 1) * pageSize
-	end := start + pageSize
-	if start > total {
-		start = total
+	query := &repository.TaskQuery{
+		Status:        req.Status,
+		Owner:         req.Owner,
+		Source:        req.Source,
+		Dest:          req.Dest,
+		Registry:      req.Registry,
+		Architecture:  req.Architecture,
+		JobID:         req.JobID,
+		ScheduleID:    req.ScheduleID,
+		WatchID:       req.WatchID,
+		HookID:        req.HookID,
+		StartedAfter:  req.StartedAfter,
+		StartedBefore: req.StartedBefore,
+		EndedAfter:    req.EndedAfter,
+		EndedBefore:   req.EndedBefore,
+		SortBy:        req.SortBy,
+		SortOrder:     req.SortOrder,
+		Offset:        (page - 1) * pageSize,
+		// One extra task tells whether there is a next page
+		Limit: pageSize + 1,
+	}
+	if req.MinDuration != "" {
+		minDuration, err := timeutil.ParseDuration(req.MinDuration)
+		if err != nil {
+			return nil, fmt.Errorf("%w: minDuration: %v", ErrInvalidQuery, err)
+		}
+		query.MinDuration = minDuration
 	}
-	if end > total {
-		end = total
+	for _, label := range req.Labels {
+		selector, err := repository.ParseLabelSelector(label)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+		query.Labels = append(query.Labels, selector)
+	}
+	if req.Cursor != "" {
+		cursor, err := repository.DecodeTaskCursor(req.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+		if !cursor.Matches(req.SortBy, req.SortOrder) {
+			return nil, fmt.Errorf("%w: cursor does not match sortBy and sortOrder", ErrInvalidQuery)
+		}
+		query.After = cursor
+		query.Offset = 0
+		page = 1
 	}
 
-	pagedTasks := filtered[start:end]
+	pagedTasks, total, err := s.repo.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	nextCursor := ""
+	if len(pagedTasks) > pageSize {
+		pagedTasks = pagedTasks[:pageSize]
+		nextCursor = repository.NewTaskCursor(pagedTasks[pageSize-1], req.SortBy, req.SortOrder).Encode()
+	}
 
 	// Convert to summary format (excludes full logs)
 	summaries := make([]*models.TaskSummary, len(pagedTasks))
 	for i, task := range pagedTasks {
-		summaries[i] = &models.TaskSummary{
-			ID:           task.ID,
-			SourceImage:  task.SourceImage,
-			DestImage:    task.DestImage,
-			Architecture: task.Architecture,
-			Status:       task.Status,
-			Message:      task.Message,
-			StartTime:    task.StartTime,
-			EndTime:      task.EndTime,
-		}
+		summaries[i] = task.Summary()
 	}
 
 	return &models.TaskListResponse{
-		Total:    total,
-		Page:     page,
-		PageSize: pageSize,
-		Tasks:    summaries,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		Tasks:      summaries,
+		NextCursor: nextCursor,
 	}, nil
 }
 
-// sortTasks sorts a slice of tasks in-place using bubble sort.
-// Supports sorting by startTime or endTime, in ascending or descending order.
-func sortTasks(tasks []*models.SyncTask, sortBy, sortOrder string) {
-	if len(tasks) <= 1 {
-		return
-	}
-
-	// Simple bubble sort for small datasets (sufficient for task lists)
-	for i := 0; i < len(tasks)-1; i++ {
-		for j := 0; j < len(tasks)-i-1; j++ {
-			shouldSwap := false
-			if sortBy == "endTime" {
-				t1 := tasks[j].EndTime
-				t2 := tasks[j+1].EndTime
-				// Handle nil endTime (for running tasks)
-				if t1 == nil && t2 != nil {
-					shouldSwap = sortOrder == "asc"
-				} else if t1 != nil && t2 == nil {
-					shouldSwap = sortOrder == "desc"
-				} else if t1 != nil && t2 != nil {
-					if sortOrder == "desc" {
-						shouldSwap = t1.Before(*t2)
-					} else {
-						shouldSwap = t1.After(*t2)
-					}
-				}
-			} else {
-				// Default to startTime
-				if sortOrder == "desc" {
-					shouldSwap = tasks[j].StartTime.Before(tasks[j+1].StartTime)
-				} else {
-					shouldSwap = tasks[j].StartTime.After(tasks[j+1].StartTime)
-				}
-			}
-			if shouldSwap {
-				tasks[j], tasks[j+1] = tasks[j+1], tasks[j]
-			}
-		}
-	}
-}
-
 // sanitizeCommand replaces credentials in command arguments with "***:***" for safe logging.
 func sanitizeCommand(args []string) string {
 	sanitized := make([]string, len(args))
@@ -526,3 +1211,140 @@ func createAuthFile(sourceImage, sourceUsername, sourcePassword, destImage, dest
 
 	return tmpFile.Name(), nil
 }
+
+// UpdateTask edits the labels and note of a task of any status. Labels are merged into
+// the existing ones and removed for null values; the note is replaced if given.
+// Returns repository.ErrTaskNotFound if the task does not exist, or an error wrapping
+// ErrInvalidMetadata if the resulting labels or note are invalid.
+func (s *syncService) UpdateTask(id string, req *models.TaskUpdateRequest) (*models.SyncTask, error) {
+	task, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build a new map rather than modifying the current one, which may be read concurrently
+	labels := models.CopyLabels(task.Labels)
+	for key, value := range req.Labels {
+		if value == nil {
+			delete(labels, key)
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = *value
+	}
+	if err := validator.ValidateLabels(labels); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+	note := task.Note
+	if req.Note != nil {
+		note = *req.Note
+	}
+	if err := validator.ValidateNote(note); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+
+	task.Labels = models.CopyLabels(labels)
+	task.Note = note
+	if err := s.repo.Update(task); err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+
+	s.logger.Info("[%s] Task labels and note updated", id)
+	return task, nil
+}
+
+// DeleteTask removes a finished task and its logs.
+// Returns ErrTaskActive if the task is still pending or running.
+func (s *syncService) DeleteTask(id string) error {
+	task, err := s.repo.Get(id)
+	if err != nil {
+		return err
+	}
+	if !task.Status.IsTerminal() {
+		return ErrTaskActive
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.logger.Info("[%s] Task deleted", id)
+	s.pruneJob(task.JobID)
+	return nil
+}
+
+// DeleteTasks removes all finished tasks matching the filter: status (any terminal
+// status if empty), owner user ID or email (any owner if empty) and end time before
+// endedBefore (any time if zero). Pending and running tasks are never deleted.
+// Returns the number of deleted tasks.
+func (s *syncService) DeleteTasks(status models.SyncStatus, owner string, endedBefore time.Time) (int, error) {
+	tasks, _, err := s.repo.Query(&repository.TaskQuery{
+		Status:      status,
+		Owner:       owner,
+		Finished:    true,
+		EndedBefore: endedBefore,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	return s.deleteTasks(tasks)
+}
+
+// PruneTasks applies the task retention policy: it removes finished tasks that ended
+// more than maxAge ago, and the oldest finished tasks beyond the newest maxCount.
+// A zero maxAge or maxCount disables the respective limit. Pending and running tasks
+// are never removed and do not count towards maxCount.
+// Returns the number of deleted tasks.
+func (s *syncService) PruneTasks(maxAge time.Duration, maxCount int) (int, error) {
+	deleted := 0
+	if maxAge > 0 {
+		n, err := s.DeleteTasks("", "", time.Now().Add(-maxAge))
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	if maxCount > 0 {
+		tasks, _, err := s.repo.Query(&repository.TaskQuery{
+			Finished:  true,
+			SortBy:    "endTime",
+			SortOrder: "desc",
+			Offset:    maxCount,
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to query tasks: %w", err)
+		}
+		n, err := s.deleteTasks(tasks)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// deleteTasks removes the given tasks and returns how many were deleted.
+// Tasks deleted concurrently by another request are skipped. Jobs left without
+// tasks are deleted as well.
+func (s *syncService) deleteTasks(tasks []*models.SyncTask) (int, error) {
+	deleted := 0
+	jobs := make(map[string]bool)
+	defer func() {
+		for id := range jobs {
+			s.pruneJob(id)
+		}
+	}()
+	for _, task := range tasks {
+		if err := s.repo.Delete(task.ID); err != nil {
+			if errors.Is(err, repository.ErrTaskNotFound) {
+				continue
+			}
+			return deleted, fmt.Errorf("failed to delete task %s: %w", task.ID, err)
+		}
+		deleted++
+		if task.JobID != "" {
+			jobs[task.JobID] = true
+		}
+	}
+	return deleted, nil
+}
@@ -4,7 +4,14 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/lazycatapps/image-sync/internal/models"
 	"github.com/lazycatapps/image-sync/internal/pkg/logger"
@@ -14,7 +21,7 @@ import (
 func TestCreateSyncTask(t *testing.T) {
 	repo := repository.NewInMemoryTaskRepository()
 	log := logger.New()
-	service := NewSyncService(repo, log, 600)
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
 
 	req := &models.SyncRequest{
 		SourceImage: "docker.io/library/nginx:latest",
@@ -55,7 +62,7 @@ func TestCreateSyncTask(t *testing.T) {
 func TestCreateSyncTaskWithArchitecture(t *testing.T) {
 	repo := repository.NewInMemoryTaskRepository()
 	log := logger.New()
-	service := NewSyncService(repo, log, 600)
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
 
 	req := &models.SyncRequest{
 		SourceImage:  "docker.io/library/nginx:latest",
@@ -78,10 +85,134 @@ func TestCreateSyncTaskWithArchitecture(t *testing.T) {
 	}
 }
 
+func TestCreateSyncTaskIdempotencyKey(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	req := &models.SyncRequest{
+		SourceImage:    "docker.io/library/nginx:latest",
+		DestImage:      "registry.example.com/nginx:latest",
+		Owner:          "u1",
+		IdempotencyKey: "ci-build-42",
+	}
+	taskID, err := service.CreateSyncTask(req)
+	if err != nil {
+		t.Fatalf("CreateSyncTask failed: %v", err)
+	}
+
+	// The key is honoured after the task finished, unlike in-flight detection
+	task, _ := repo.Get(taskID)
+	task.Status = models.StatusCompleted
+	repo.Update(task)
+
+	_, err = service.CreateSyncTask(req)
+	var duplicate *DuplicateTaskError
+	if !errors.As(err, &duplicate) || !errors.Is(err, ErrDuplicateTask) {
+		t.Fatalf("Expected DuplicateTaskError, got %v", err)
+	}
+	if duplicate.TaskID != taskID || duplicate.Reason != DuplicateIdempotencyKey || duplicate.Status != models.StatusCompleted {
+		t.Errorf("Expected completed task %s by idempotency key, got %+v", taskID, duplicate)
+	}
+
+	// Same key for different images
+	other := *req
+	other.DestImage = "registry.example.com/nginx:stable"
+	if _, err := service.CreateSyncTask(&other); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("Expected ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	// Keys are scoped to their owner
+	other = *req
+	other.Owner = "u2"
+	if _, err := service.CreateSyncTask(&other); err != nil {
+		t.Errorf("Expected a new task for another owner, got %v", err)
+	}
+
+	// Keys expire after the TTL
+	task.StartTime = time.Now().Add(-2 * time.Hour)
+	repo.Update(task)
+	if newID, err := service.CreateSyncTask(req); err != nil || newID == taskID {
+		t.Errorf("Expected a new task after the TTL, got %s, %v", newID, err)
+	}
+}
+
+func TestCreateSyncTaskInFlightDedup(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	req := &models.SyncRequest{
+		SourceImage:  "docker.io/library/nginx:latest",
+		DestImage:    "registry.example.com/nginx:latest",
+		Architecture: "linux/amd64",
+		Owner:        "u1",
+	}
+	taskID, err := service.CreateSyncTask(req)
+	if err != nil {
+		t.Fatalf("CreateSyncTask failed: %v", err)
+	}
+
+	var duplicate *DuplicateTaskError
+	if _, err := service.CreateSyncTask(req); !errors.As(err, &duplicate) || duplicate.TaskID != taskID || duplicate.Reason != DuplicateInFlight {
+		t.Fatalf("Expected request to be attached to in-flight task %s, got %v", taskID, err)
+	}
+
+	// Substring matches of the images and other architectures are different syncs
+	for _, other := range []models.SyncRequest{
+		{SourceImage: "docker.io/library/nginx:latest", DestImage: "registry.example.com/nginx:latest", Architecture: "linux/arm64", Owner: "u1"},
+		{SourceImage: "docker.io/library/nginx:latest", DestImage: "registry.example.com/nginx:latest-alpine", Architecture: "linux/amd64", Owner: "u1"},
+		{SourceImage: "docker.io/library/nginx:latest", DestImage: "registry.example.com/nginx:latest", Architecture: "linux/amd64", Owner: "u2"},
+	} {
+		if _, err := service.CreateSyncTask(&other); err != nil {
+			t.Errorf("Expected a new task for %+v, got %v", other, err)
+		}
+	}
+
+	// Once the task finished, the same request starts a new copy
+	task, _ := repo.Get(taskID)
+	task.Status = models.StatusFailed
+	repo.Update(task)
+	if newID, err := service.CreateSyncTask(req); err != nil || newID == taskID {
+		t.Errorf("Expected a new task after the first finished, got %s, %v", newID, err)
+	}
+}
+
+func TestCreateSyncTaskConcurrentDuplicates(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	req := models.SyncRequest{
+		SourceImage:    "docker.io/library/nginx:latest",
+		DestImage:      "registry.example.com/nginx:latest",
+		IdempotencyKey: "ci-build-43",
+	}
+
+	created := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			r := req
+			taskID, err := service.CreateSyncTask(&r)
+			if err != nil {
+				taskID = ""
+			}
+			created <- taskID
+		}()
+	}
+
+	count := 0
+	for i := 0; i < 10; i++ {
+		if <-created != "" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("Expected exactly one task for parallel duplicates, got %d", count)
+	}
+}
+
 func TestGetTask(t *testing.T) {
 	repo := repository.NewInMemoryTaskRepository()
 	log := logger.New()
-	service := NewSyncService(repo, log, 600)
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
 
 	req := &models.SyncRequest{
 		SourceImage: "docker.io/library/nginx:latest",
@@ -103,7 +234,7 @@ func TestGetTask(t *testing.T) {
 func TestGetTaskNotFound(t *testing.T) {
 	repo := repository.NewInMemoryTaskRepository()
 	log := logger.New()
-	service := NewSyncService(repo, log, 600)
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
 
 	_, err := service.GetTask("non-existent-id")
 	if err != repository.ErrTaskNotFound {
@@ -114,13 +245,13 @@ func TestGetTaskNotFound(t *testing.T) {
 func TestListTasks(t *testing.T) {
 	repo := repository.NewInMemoryTaskRepository()
 	log := logger.New()
-	service := NewSyncService(repo, log, 600)
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
 
 	// Create multiple tasks
 	for i := 0; i < 5; i++ {
 		req := &models.SyncRequest{
 			SourceImage: "docker.io/library/nginx:latest",
-			DestImage:   "registry.example.com/nginx:latest",
+			DestImage:   fmt.Sprintf("registry.example.com/nginx:%d", i),
 		}
 		service.CreateSyncTask(req)
 	}
@@ -147,13 +278,13 @@ func TestListTasks(t *testing.T) {
 func TestListTasksWithPagination(t *testing.T) {
 	repo := repository.NewInMemoryTaskRepository()
 	log := logger.New()
-	service := NewSyncService(repo, log, 600)
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
 
 	// Create 25 tasks
 	for i := 0; i < 25; i++ {
 		req := &models.SyncRequest{
 			SourceImage: "docker.io/library/nginx:latest",
-			DestImage:   "registry.example.com/nginx:latest",
+			DestImage:   fmt.Sprintf("registry.example.com/nginx:%d", i),
 		}
 		service.CreateSyncTask(req)
 	}
@@ -180,13 +311,13 @@ func TestListTasksWithPagination(t *testing.T) {
 func TestListTasksFilterByStatus(t *testing.T) {
 	repo := repository.NewInMemoryTaskRepository()
 	log := logger.New()
-	service := NewSyncService(repo, log, 600)
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
 
 	// Create tasks with different statuses
 	for i := 0; i < 3; i++ {
 		req := &models.SyncRequest{
 			SourceImage: "docker.io/library/nginx:latest",
-			DestImage:   "registry.example.com/nginx:latest",
+			DestImage:   fmt.Sprintf("registry.example.com/nginx:%d", i),
 		}
 		taskID, _ := service.CreateSyncTask(req)
 		task, _ := repo.Get(taskID)
@@ -209,3 +340,615 @@ func TestListTasksFilterByStatus(t *testing.T) {
 		t.Errorf("Expected 2 pending tasks, got %d", resp.Total)
 	}
 }
+
+func TestListTasksCursor(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+	base := time.Now().Add(-time.Hour)
+
+	for i := 0; i < 5; i++ {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "docker.io/library/nginx:latest", "registry.example.com/nginx:latest", "all")
+		task.StartTime = base.Add(time.Duration(i) * time.Minute)
+		repo.Create(task)
+	}
+
+	resp, err := service.ListTasks(&models.TaskListRequest{PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListTasks failed: %v", err)
+	}
+	if len(resp.Tasks) != 2 || resp.Tasks[0].ID != "id4" || resp.NextCursor == "" {
+		t.Fatalf("Expected first page id4, id3 with a next cursor, got %d tasks, cursor %q", len(resp.Tasks), resp.NextCursor)
+	}
+
+	// A task created between pages must not shift the next page
+	repo.Create(models.NewSyncTask("new", "src", "dest", "all"))
+
+	resp, err = service.ListTasks(&models.TaskListRequest{PageSize: 2, Cursor: resp.NextCursor})
+	if err != nil {
+		t.Fatalf("ListTasks failed: %v", err)
+	}
+	if len(resp.Tasks) != 2 || resp.Tasks[0].ID != "id2" || resp.Tasks[1].ID != "id1" {
+		t.Fatalf("Expected id2, id1 on the second page, got %+v", resp.Tasks)
+	}
+	if resp.Total != 6 {
+		t.Errorf("Expected total 6, got %d", resp.Total)
+	}
+
+	resp, _ = service.ListTasks(&models.TaskListRequest{PageSize: 2, Cursor: resp.NextCursor})
+	if len(resp.Tasks) != 1 || resp.Tasks[0].ID != "id0" || resp.NextCursor != "" {
+		t.Errorf("Expected only id0 and no next cursor on the last page, got %d tasks, cursor %q", len(resp.Tasks), resp.NextCursor)
+	}
+}
+
+func TestListTasksInvalidQuery(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+	cursor := repository.NewTaskCursor(models.NewSyncTask("id", "src", "dest", "all"), "startTime", "desc").Encode()
+
+	tests := []struct {
+		name string
+		req  *models.TaskListRequest
+	}{
+		{"malformed cursor", &models.TaskListRequest{Cursor: "garbage!"}},
+		{"cursor for another order", &models.TaskListRequest{Cursor: cursor, SortOrder: "asc"}},
+		{"malformed duration", &models.TaskListRequest{MinDuration: "5 minutes"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := service.ListTasks(tt.req); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: expected ErrInvalidQuery, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestCancelPendingTask(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	log := logger.New()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
+
+	req := &models.SyncRequest{
+		SourceImage: "docker.io/library/nginx:latest",
+		DestImage:   "registry.example.com/nginx:latest",
+	}
+
+	taskID, _ := service.CreateSyncTask(req)
+	task, _ := repo.Get(taskID)
+	_, changed := task.ReadLogs(0)
+
+	if err := service.CancelTask(taskID, "alice@example.com"); err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+
+	if task.Status != models.StatusCancelled {
+		t.Errorf("Expected status cancelled, got %s", task.Status)
+	}
+
+	if task.CancelledBy != "alice@example.com" {
+		t.Errorf("Expected cancelledBy 'alice@example.com', got %s", task.CancelledBy)
+	}
+
+	if task.CancelledAt == nil || task.EndTime == nil {
+		t.Error("Expected cancelledAt and endTime to be set")
+	}
+
+	// Log streams must be woken once the task is cancelled
+	select {
+	case <-changed:
+	default:
+		t.Error("Expected log streams to be notified")
+	}
+
+	// A cancelled task must not be started afterwards
+	if err := service.ExecuteSync(taskID, req); err != nil {
+		t.Fatalf("ExecuteSync failed: %v", err)
+	}
+
+	if task.Status != models.StatusCancelled {
+		t.Errorf("Expected status to remain cancelled, got %s", task.Status)
+	}
+}
+
+func TestCancelFinishedTask(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	log := logger.New()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
+
+	req := &models.SyncRequest{
+		SourceImage: "docker.io/library/nginx:latest",
+		DestImage:   "registry.example.com/nginx:latest",
+	}
+
+	taskID, _ := service.CreateSyncTask(req)
+	task, _ := repo.Get(taskID)
+	task.Status = models.StatusCompleted
+	repo.Update(task)
+
+	if err := service.CancelTask(taskID, ""); err != ErrTaskFinished {
+		t.Errorf("Expected ErrTaskFinished, got %v", err)
+	}
+
+	if err := service.CancelTask("non-existent-id", ""); err != repository.ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestFinishSyncAfterCancel(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour).(*syncService)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus models.SyncStatus
+	}{
+		{"copy stopped by the cancel", errors.New("signal: terminated"), models.StatusCancelled},
+		{"start failed by the cancel", context.Canceled, models.StatusCancelled},
+		{"copy finished before the cancel", nil, models.StatusCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskID, _ := service.CreateSyncTask(&models.SyncRequest{
+				SourceImage: "docker.io/library/nginx:latest",
+				DestImage:   "registry.example.com/nginx:" + fmt.Sprint(len(tt.name)),
+			})
+			task, _ := repo.Get(taskID)
+			task.Status = models.StatusRunning
+			now := time.Now()
+			task.CancelledBy, task.CancelledAt = "alice@example.com", &now
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			service.finishSync(ctx, task, tt.err)
+
+			if task.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, task.Status)
+			}
+			if tt.wantStatus == models.StatusCompleted && task.CancelledAt != nil {
+				t.Error("Expected the late cancellation to be cleared")
+			}
+		})
+	}
+}
+
+func TestRecoverTasks(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	log := logger.New()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
+
+	pendingID, _ := service.CreateSyncTask(&models.SyncRequest{
+		SourceImage: "docker.io/library/nginx:latest",
+		DestImage:   "registry.example.com/nginx:latest",
+	})
+	runningID, _ := service.CreateSyncTask(&models.SyncRequest{
+		SourceImage: "docker.io/library/redis:latest",
+		DestImage:   "registry.example.com/redis:latest",
+	})
+	running, _ := repo.Get(runningID)
+	running.Status = models.StatusRunning
+	repo.Update(running)
+
+	completedID, _ := service.CreateSyncTask(&models.SyncRequest{
+		SourceImage: "docker.io/library/alpine:latest",
+		DestImage:   "registry.example.com/alpine:latest",
+	})
+	completed, _ := repo.Get(completedID)
+	completed.Status = models.StatusCompleted
+	repo.Update(completed)
+
+	recovered, err := service.RecoverTasks(false)
+	if err != nil {
+		t.Fatalf("RecoverTasks failed: %v", err)
+	}
+	if recovered != 2 {
+		t.Errorf("Expected 2 recovered tasks, got %d", recovered)
+	}
+
+	for _, id := range []string{pendingID, runningID} {
+		task, _ := repo.Get(id)
+		if task.Status != models.StatusInterrupted {
+			t.Errorf("Expected task %s to be interrupted, got %s", id, task.Status)
+		}
+		if task.EndTime == nil {
+			t.Errorf("Expected task %s to have an end time", id)
+		}
+		if len(task.GetLogLines()) == 0 {
+			t.Errorf("Expected task %s to have an explanatory log line", id)
+		}
+	}
+
+	if task, _ := repo.Get(completedID); task.Status != models.StatusCompleted {
+		t.Errorf("Expected completed task to be left alone, got %s", task.Status)
+	}
+}
+
+func TestRecoverTasksDoesNotResumeWithCredentials(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	log := logger.New()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
+
+	taskID, _ := service.CreateSyncTask(&models.SyncRequest{
+		SourceImage:    "registry.example.com/private/app:latest",
+		DestImage:      "registry.example.com/app:latest",
+		SourceUsername: "user",
+		SourcePassword: "secret",
+	})
+
+	if _, err := service.RecoverTasks(true); err != nil {
+		t.Fatalf("RecoverTasks failed: %v", err)
+	}
+
+	task, _ := repo.Get(taskID)
+	if task.Status != models.StatusInterrupted {
+		t.Errorf("Expected task with credentials to stay interrupted, got %s", task.Status)
+	}
+}
+
+func TestRecoverTasksSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	repo, err := repository.NewSQLiteTaskRepository(path)
+	if err != nil {
+		t.Fatalf("Failed to open SQLite repository: %v", err)
+	}
+	log := logger.New()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
+
+	resumedID, _ := service.CreateSyncTask(&models.SyncRequest{
+		SourceImage: "docker.io/library/nginx:latest",
+		DestImage:   "registry.example.com/nginx:latest",
+	})
+	keptID, _ := service.CreateSyncTask(&models.SyncRequest{
+		SourceImage:    "registry.example.com/private/app:latest",
+		DestImage:      "registry.example.com/app:latest",
+		SourceUsername: "user",
+		SourcePassword: "secret",
+	})
+	for _, id := range []string{resumedID, keptID} {
+		task, _ := repo.Get(id)
+		task.Status = models.StatusRunning
+		task.AddLog("Copying blob sha256:abc")
+		repo.Update(task)
+	}
+	repo.Close()
+
+	// Queries of the reopened repository return the tasks without their logs
+	repo, err = repository.NewSQLiteTaskRepository(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen SQLite repository: %v", err)
+	}
+	restarted := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour).(*syncService)
+	restarted.queue.maxConcurrent = 0
+	if recovered, err := restarted.RecoverTasks(true); err != nil || recovered != 2 {
+		t.Fatalf("Expected 2 recovered tasks, got %d (%v)", recovered, err)
+	}
+	repo.Close()
+
+	repo, err = repository.NewSQLiteTaskRepository(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen SQLite repository: %v", err)
+	}
+	defer repo.Close()
+
+	resumed, _ := repo.Get(resumedID)
+	lines := resumed.GetLogLines()
+	i := slices.Index(lines, "Copying blob sha256:abc")
+	if i < 0 || len(lines) < i+3 ||
+		!strings.HasPrefix(lines[i+1], "Task interrupted by a server restart while running") ||
+		lines[i+2] != "Resuming automatically (--resume-interrupted)" {
+		t.Errorf("Expected the interruption and resume to follow the stored logs, got %q", lines)
+	}
+	if resumed.Status != models.StatusPending || resumed.Output != "" {
+		t.Errorf("Expected resumed task to be pending without output, got %s with %q", resumed.Status, resumed.Output)
+	}
+
+	kept, _ := repo.Get(keptID)
+	lines = kept.GetLogLines()
+	i = slices.Index(lines, "Copying blob sha256:abc")
+	if i < 0 || len(lines) < i+3 ||
+		!strings.HasPrefix(lines[i+1], "Task interrupted by a server restart while running") ||
+		!strings.HasPrefix(lines[i+2], "Not resumed automatically") {
+		t.Errorf("Expected the interruption to follow the stored logs, got %q", lines)
+	}
+	if !strings.Contains(kept.Output, "Copying blob sha256:abc") || !strings.Contains(kept.Output, "Task interrupted by a server restart") {
+		t.Errorf("Expected the output to hold all logs up to the interruption, got %q", kept.Output)
+	}
+}
+
+func TestShutdownInterruptsQueuedTasks(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	log := logger.New()
+	svc := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour).(*syncService)
+
+	// Put a task in the queue without a free worker so that it is still waiting at shutdown
+	svc.queue.maxConcurrent = 0
+	taskID, _ := svc.CreateSyncTask(&models.SyncRequest{
+		SourceImage: "docker.io/library/nginx:latest",
+		DestImage:   "registry.example.com/nginx:latest",
+	})
+	if _, err := svc.EnqueueTask(taskID, &models.SyncRequest{}); err != nil {
+		t.Fatalf("EnqueueTask failed: %v", err)
+	}
+
+	if err := svc.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	task, _ := repo.Get(taskID)
+	if task.Status != models.StatusInterrupted || !task.Resumable {
+		t.Errorf("Expected queued task to be interrupted and resumable, got %s (resumable: %v)", task.Status, task.Resumable)
+	}
+
+	if _, err := svc.CreateSyncTask(&models.SyncRequest{SourceImage: "src", DestImage: "dest"}); err != ErrShuttingDown {
+		t.Errorf("Expected ErrShuttingDown after shutdown, got %v", err)
+	}
+
+	// The next start picks up the task interrupted by the shutdown exactly once
+	restarted := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
+	if recovered, _ := restarted.RecoverTasks(false); recovered != 1 {
+		t.Errorf("Expected 1 recovered task, got %d", recovered)
+	}
+	if recovered, _ := restarted.RecoverTasks(false); recovered != 0 {
+		t.Errorf("Expected no recovered tasks on second pass, got %d", recovered)
+	}
+}
+
+func TestRetryTask(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	log := logger.New()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
+
+	parentID, _ := service.CreateSyncTask(&models.SyncRequest{
+		SourceImage:    "registry.example.com/private/app:latest",
+		DestImage:      "registry.example.com/app:latest",
+		SourceUsername: "user",
+		SourcePassword: "secret",
+	})
+
+	parent, _ := repo.Get(parentID)
+	req, _ := parent.Request()
+	if _, err := service.RetryTask(parentID, req); err != ErrTaskNotRetryable {
+		t.Errorf("Expected ErrTaskNotRetryable for pending task, got %v", err)
+	}
+
+	parent.Status = models.StatusFailed
+	repo.Update(parent)
+
+	if _, err := service.RetryTask(parentID, req); !errors.Is(err, ErrCredentialsRequired) {
+		t.Errorf("Expected ErrCredentialsRequired without credentials, got %v", err)
+	}
+
+	req.SourceUsername, req.SourcePassword = "user", "secret"
+	taskID, err := service.RetryTask(parentID, req)
+	if err != nil {
+		t.Fatalf("RetryTask failed: %v", err)
+	}
+
+	task, _ := repo.Get(taskID)
+	if task.ParentTaskID != parentID {
+		t.Errorf("Expected parent task ID %s, got %s", parentID, task.ParentTaskID)
+	}
+	if task.SourceImage != parent.SourceImage || task.DestImage != parent.DestImage {
+		t.Errorf("Expected retry to keep the images, got %s -> %s", task.SourceImage, task.DestImage)
+	}
+	if task.Status != models.StatusPending {
+		t.Errorf("Expected status pending, got %s", task.Status)
+	}
+}
+
+func TestCloneTask(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	log := logger.New()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), log, 600, 3, time.Hour)
+
+	parentID, _ := service.CreateSyncTask(&models.SyncRequest{
+		SourceImage:  "docker.io/library/nginx:latest",
+		DestImage:    "registry.example.com/nginx:latest",
+		DestUsername: "user",
+		DestPassword: "secret",
+	})
+	parent, _ := repo.Get(parentID)
+	parent.Status = models.StatusCompleted
+	repo.Update(parent)
+
+	// A different destination registry does not need the original credentials
+	req, _ := parent.Request()
+	req.DestImage = "other.example.com/nginx:1.27"
+	req.Architecture = "linux/arm64"
+
+	taskID, err := service.CloneTask(parentID, req)
+	if err != nil {
+		t.Fatalf("CloneTask failed: %v", err)
+	}
+
+	task, _ := repo.Get(taskID)
+	if task.ParentTaskID != parentID {
+		t.Errorf("Expected parent task ID %s, got %s", parentID, task.ParentTaskID)
+	}
+	if task.DestImage != "other.example.com/nginx:1.27" || task.Architecture != "linux/arm64" {
+		t.Errorf("Expected overrides to be applied, got %s (%s)", task.DestImage, task.Architecture)
+	}
+
+	req.DestImage = "registry.example.com/nginx:1.27"
+	if _, err := service.CloneTask(parentID, req); !errors.Is(err, ErrCredentialsRequired) {
+		t.Errorf("Expected ErrCredentialsRequired for the same destination registry, got %v", err)
+	}
+}
+
+func TestRetryAndCloneInFlightDedup(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	parentID, _ := service.CreateSyncTask(&models.SyncRequest{
+		SourceImage: "docker.io/library/nginx:latest",
+		DestImage:   "registry.example.com/nginx:latest",
+		Owner:       "u1",
+	})
+	parent, _ := repo.Get(parentID)
+	parent.Status = models.StatusFailed
+	repo.Update(parent)
+
+	req, _ := parent.Request()
+	req.Owner = "u1"
+	retryID, err := service.RetryTask(parentID, req)
+	if err != nil {
+		t.Fatalf("RetryTask failed: %v", err)
+	}
+
+	// While the retry is pending, retrying again or cloning to the same destination is a duplicate
+	var duplicate *DuplicateTaskError
+	if _, err := service.RetryTask(parentID, req); !errors.As(err, &duplicate) || duplicate.TaskID != retryID {
+		t.Errorf("Expected retry to be attached to in-flight task %s, got %v", retryID, err)
+	}
+	if _, err := service.CloneTask(parentID, req); !errors.As(err, &duplicate) || duplicate.TaskID != retryID {
+		t.Errorf("Expected clone to be attached to in-flight task %s, got %v", retryID, err)
+	}
+
+	req.DestImage = "registry.example.com/nginx:1.27"
+	if _, err := service.CloneTask(parentID, req); err != nil {
+		t.Errorf("Expected a clone to another destination, got %v", err)
+	}
+}
+
+func TestUpdateTask(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	task := models.NewSyncTask("test-id", "src", "dest", "all")
+	task.Labels = map[string]string{"ticket": "OPS-1", "release": "2025.03"}
+	original := task.Labels
+	repo.Create(task)
+
+	ticket := "OPS-2"
+	note := "Synced for the March release"
+	updated, err := service.UpdateTask("test-id", &models.TaskUpdateRequest{
+		Labels: map[string]*string{"ticket": &ticket, "release": nil},
+		Note:   &note,
+	})
+	if err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	if len(updated.Labels) != 1 || updated.Labels["ticket"] != "OPS-2" || updated.Note != note {
+		t.Errorf("Expected merged labels and new note, got %v and %q", updated.Labels, updated.Note)
+	}
+	if original["ticket"] != "OPS-1" || len(original) != 2 {
+		t.Error("Expected the previous labels map not to be modified")
+	}
+
+	// An omitted note is left unchanged
+	updated, _ = service.UpdateTask("test-id", &models.TaskUpdateRequest{})
+	if updated.Note != note {
+		t.Errorf("Expected note to be kept, got %q", updated.Note)
+	}
+
+	invalid := "a\nb"
+	if _, err := service.UpdateTask("test-id", &models.TaskUpdateRequest{Labels: map[string]*string{"bad key": &ticket}}); !errors.Is(err, ErrInvalidMetadata) {
+		t.Errorf("Expected ErrInvalidMetadata for invalid key, got %v", err)
+	}
+	if _, err := service.UpdateTask("test-id", &models.TaskUpdateRequest{Labels: map[string]*string{"ticket": &invalid}}); !errors.Is(err, ErrInvalidMetadata) {
+		t.Errorf("Expected ErrInvalidMetadata for invalid value, got %v", err)
+	}
+	if task.Labels["ticket"] != "OPS-2" {
+		t.Errorf("Expected failed updates to leave labels unchanged, got %v", task.Labels)
+	}
+	if _, err := service.UpdateTask("missing", &models.TaskUpdateRequest{}); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+
+	resp, _ := service.ListTasks(&models.TaskListRequest{Labels: []string{"ticket=OPS-2"}})
+	if resp.Total != 1 || resp.Tasks[0].Labels["ticket"] != "OPS-2" || resp.Tasks[0].Note != note {
+		t.Errorf("Expected task summary with labels and note, got %+v", resp.Tasks)
+	}
+	if _, err := service.ListTasks(&models.TaskListRequest{Labels: []string{"=OPS-2"}}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for invalid selector, got %v", err)
+	}
+}
+
+func TestDeleteTask(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+
+	req := &models.SyncRequest{
+		SourceImage: "docker.io/library/nginx:latest",
+		DestImage:   "registry.example.com/nginx:latest",
+	}
+	taskID, _ := service.CreateSyncTask(req)
+
+	if err := service.DeleteTask(taskID); err != ErrTaskActive {
+		t.Errorf("Expected ErrTaskActive for pending task, got %v", err)
+	}
+
+	service.CancelTask(taskID, "")
+	if err := service.DeleteTask(taskID); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+	if _, err := repo.Get(taskID); err != repository.ErrTaskNotFound {
+		t.Errorf("Expected task to be deleted, got %v", err)
+	}
+}
+
+func TestPruneTasks(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+	now := time.Now()
+
+	// Finished tasks ended 1..5 days ago, plus a running task started long ago
+	for i := 1; i <= 5; i++ {
+		task := models.NewSyncTask(fmt.Sprintf("done%d", i), "src", "dest", "all")
+		task.Status = models.StatusCompleted
+		endTime := now.Add(-time.Duration(i) * 24 * time.Hour)
+		task.EndTime = &endTime
+		repo.Create(task)
+	}
+	running := models.NewSyncTask("running", "src", "dest", "all")
+	running.Status = models.StatusRunning
+	running.StartTime = now.Add(-30 * 24 * time.Hour)
+	repo.Create(running)
+
+	deleted, err := service.PruneTasks(72*time.Hour+time.Minute, 0)
+	if err != nil {
+		t.Fatalf("PruneTasks failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 tasks older than 3 days to be pruned, got %d", deleted)
+	}
+
+	deleted, _ = service.PruneTasks(0, 1)
+	if deleted != 2 {
+		t.Errorf("Expected 2 tasks beyond the newest one to be pruned, got %d", deleted)
+	}
+	if _, err := repo.Get("done1"); err != nil {
+		t.Errorf("Expected newest finished task to be kept, got %v", err)
+	}
+	if _, err := repo.Get("running"); err != nil {
+		t.Errorf("Expected running task to be kept, got %v", err)
+	}
+}
+
+func TestDeleteTasks_Filters(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+	endTime := time.Now().Add(-48 * time.Hour)
+
+	for i, status := range []models.SyncStatus{models.StatusCompleted, models.StatusFailed, models.StatusCompleted} {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "src", "dest", "all")
+		task.Status = status
+		task.EndTime = &endTime
+		task.Owner = fmt.Sprintf("user%d", i%2)
+		repo.Create(task)
+	}
+
+	deleted, err := service.DeleteTasks(models.StatusCompleted, "user0", time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteTasks failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 completed tasks of user0 to be deleted, got %d", deleted)
+	}
+	if _, err := repo.Get("id1"); err != nil {
+		t.Errorf("Expected failed task to be kept, got %v", err)
+	}
+
+	if deleted, _ := service.DeleteTasks("", "", time.Now().Add(-72*time.Hour)); deleted != 0 {
+		t.Errorf("Expected no tasks ended more than 3 days ago, got %d deleted", deleted)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+)
+
+// janitorInterval is how often the task janitor applies the retention policy.
+const janitorInterval = 10 * time.Minute
+
+// TaskJanitor periodically prunes finished tasks according to the retention policy,
+// so that task history (and the log lines kept with each task) does not grow forever.
+type TaskJanitor struct {
+	syncService SyncService
+	maxAge      time.Duration // Finished tasks older than this are removed (0 = no age limit)
+	maxCount    int           // At most this many finished tasks are kept (0 = no count limit)
+	logger      logger.Logger
+}
+
+// NewTaskJanitor creates a janitor for the given retention limits.
+func NewTaskJanitor(syncService SyncService, maxAge time.Duration, maxCount int, logger logger.Logger) *TaskJanitor {
+	return &TaskJanitor{
+		syncService: syncService,
+		maxAge:      maxAge,
+		maxCount:    maxCount,
+		logger:      logger,
+	}
+}
+
+// Enabled reports whether any retention limit is set.
+func (j *TaskJanitor) Enabled() bool {
+	return j.maxAge > 0 || j.maxCount > 0
+}
+
+// Run prunes tasks right away and then every janitorInterval until ctx is done.
+func (j *TaskJanitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+
+	for {
+		j.prune()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// prune applies the retention policy once.
+func (j *TaskJanitor) prune() {
+	deleted, err := j.syncService.PruneTasks(j.maxAge, j.maxCount)
+	if err != nil {
+		j.logger.Error("Failed to prune tasks: %v", err)
+	}
+	if deleted > 0 {
+		j.logger.Info("Pruned %d finished task(s) (retention age: %s, count: %d)", deleted, j.maxAge, j.maxCount)
+	}
+}
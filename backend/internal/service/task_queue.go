@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+// queuedTask is a task waiting in the queue together with the request needed to run it.
+// The request is kept in memory only, since it may contain credentials.
+type queuedTask struct {
+	task *models.SyncTask
+	req  *models.SyncRequest
+}
+
+// taskQueue is a FIFO scheduler that runs at most maxConcurrent tasks at a time.
+// Tasks stay in pending status while they wait; their 1-based queue position is
+// kept up to date on the task so it can be reported by the API.
+type taskQueue struct {
+	mu            sync.Mutex
+	pending       []*queuedTask
+	running       int
+	maxConcurrent int
+	run           func(taskID string, req *models.SyncRequest) // Executes a task; called in its own goroutine
+	workers       sync.WaitGroup                               // Tracks started tasks until run returns
+}
+
+// newTaskQueue creates a task queue that runs tasks with the given function.
+// maxConcurrent values below 1 are treated as 1.
+func newTaskQueue(maxConcurrent int, run func(taskID string, req *models.SyncRequest)) *taskQueue {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &taskQueue{
+		maxConcurrent: maxConcurrent,
+		run:           run,
+	}
+}
+
+// Enqueue appends a task to the end of the queue and starts it right away if a worker is free.
+// Returns the queue position of the task, or 0 if it was started immediately.
+func (q *taskQueue) Enqueue(task *models.SyncTask, req *models.SyncRequest) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending = append(q.pending, &queuedTask{task: task, req: req})
+	q.dispatchLocked()
+	return task.QueuePosition
+}
+
+// Remove drops a task from the queue if it has not been started yet.
+// Returns true if the task was found in the queue.
+func (q *taskQueue) Remove(taskID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, item := range q.pending {
+		if item.task.ID == taskID {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			item.task.QueuePosition = 0
+			q.updatePositionsLocked()
+			return true
+		}
+	}
+	return false
+}
+
+// Drain removes all tasks that have not been started yet and returns them in queue order.
+// Tasks that are already running are not affected.
+func (q *taskQueue) Drain() []*models.SyncTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	tasks := make([]*models.SyncTask, 0, len(q.pending))
+	for _, item := range q.pending {
+		item.task.QueuePosition = 0
+		tasks = append(tasks, item.task)
+	}
+	q.pending = nil
+	return tasks
+}
+
+// Wait blocks until no task is running or the context is done.
+// New tasks must not be enqueued while waiting.
+func (q *taskQueue) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Running returns the number of tasks currently being executed.
+func (q *taskQueue) Running() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.running
+}
+
+// dispatchLocked starts queued tasks in FIFO order while workers are available.
+// Must be called with q.mu held.
+func (q *taskQueue) dispatchLocked() {
+	for q.running < q.maxConcurrent && len(q.pending) > 0 {
+		item := q.pending[0]
+		q.pending = q.pending[1:]
+		item.task.QueuePosition = 0
+		q.running++
+		q.workers.Add(1)
+
+		go func(item *queuedTask) {
+			defer q.workers.Done()
+			q.run(item.task.ID, item.req)
+
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.running--
+			q.dispatchLocked()
+		}(item)
+	}
+	q.updatePositionsLocked()
+}
+
+// updatePositionsLocked refreshes the queue position and status message of all waiting tasks.
+// Must be called with q.mu held.
+func (q *taskQueue) updatePositionsLocked() {
+	for i, item := range q.pending {
+		item.task.QueuePosition = i + 1
+		item.task.Message = fmt.Sprintf("Waiting in queue (position %d)", i+1)
+		item.task.Notify()
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+)
+
+func TestTaskQueue_LimitsConcurrencyAndKeepsOrder(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan string, 10)
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+
+	queue := newTaskQueue(2, func(taskID string, req *models.SyncRequest) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		started <- taskID
+		<-release
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+
+	tasks := make([]*models.SyncTask, 5)
+	for i := range tasks {
+		tasks[i] = models.NewSyncTask(fmt.Sprintf("task-%d", i), "src", "dest", "all")
+		queue.Enqueue(tasks[i], &models.SyncRequest{})
+	}
+
+	// The first two tasks start immediately, the rest wait in order
+	first := map[string]bool{<-started: true, <-started: true}
+	if !first["task-0"] || !first["task-1"] {
+		t.Fatalf("Expected task-0 and task-1 to start first, got %v", first)
+	}
+	for i, task := range tasks[2:] {
+		if task.QueuePosition != i+1 {
+			t.Errorf("Expected %s at queue position %d, got %d", task.ID, i+1, task.QueuePosition)
+		}
+	}
+
+	// Free one worker at a time and check that the queue drains in FIFO order
+	for _, want := range []string{"task-2", "task-3", "task-4"} {
+		release <- struct{}{}
+		select {
+		case got := <-started:
+			if got != want {
+				t.Errorf("Expected %s to start next, got %s", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for %s to start", want)
+		}
+	}
+	close(release)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxRunning > 2 {
+		t.Errorf("Expected at most 2 concurrent tasks, got %d", maxRunning)
+	}
+}
+
+func TestTaskQueue_Remove(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	queue := newTaskQueue(1, func(taskID string, req *models.SyncRequest) {
+		<-release
+	})
+
+	first := models.NewSyncTask("first", "src", "dest", "all")
+	second := models.NewSyncTask("second", "src", "dest", "all")
+	third := models.NewSyncTask("third", "src", "dest", "all")
+
+	if pos := queue.Enqueue(first, &models.SyncRequest{}); pos != 0 {
+		t.Errorf("Expected first task to start immediately, got position %d", pos)
+	}
+	if pos := queue.Enqueue(second, &models.SyncRequest{}); pos != 1 {
+		t.Errorf("Expected second task at position 1, got %d", pos)
+	}
+	if pos := queue.Enqueue(third, &models.SyncRequest{}); pos != 2 {
+		t.Errorf("Expected third task at position 2, got %d", pos)
+	}
+
+	if !queue.Remove("second") {
+		t.Fatal("Expected second task to be removed from the queue")
+	}
+	if queue.Remove("first") {
+		t.Error("Expected running task not to be removable")
+	}
+
+	if second.QueuePosition != 0 {
+		t.Errorf("Expected removed task position 0, got %d", second.QueuePosition)
+	}
+	if third.QueuePosition != 1 {
+		t.Errorf("Expected third task to move to position 1, got %d", third.QueuePosition)
+	}
+}
+
+func TestTaskQueue_DrainAndWait(t *testing.T) {
+	release := make(chan struct{})
+
+	queue := newTaskQueue(1, func(taskID string, req *models.SyncRequest) {
+		<-release
+	})
+
+	running := models.NewSyncTask("running", "src", "dest", "all")
+	waiting := models.NewSyncTask("waiting", "src", "dest", "all")
+	queue.Enqueue(running, &models.SyncRequest{})
+	queue.Enqueue(waiting, &models.SyncRequest{})
+
+	drained := queue.Drain()
+	if len(drained) != 1 || drained[0] != waiting {
+		t.Fatalf("Expected only the waiting task to be drained, got %v", drained)
+	}
+	if waiting.QueuePosition != 0 {
+		t.Errorf("Expected drained task position 0, got %d", waiting.QueuePosition)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := queue.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected Wait to time out while a task is running, got %v", err)
+	}
+
+	close(release)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := queue.Wait(ctx); err != nil {
+		t.Errorf("Expected Wait to return once the task finished, got %v", err)
+	}
+	if queue.Running() != 0 {
+		t.Errorf("Expected no running tasks after drain, got %d", queue.Running())
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/timeutil"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+const (
+	// defaultStatsWindow is the window covered by statistics when no start is given.
+	defaultStatsWindow = 30 * timeutil.Day
+
+	// hourlyStatsWindow is the longest window whose series defaults to hourly buckets.
+	hourlyStatsWindow = 7 * timeutil.Day
+
+	// maxStatsBuckets bounds the length of the statistics series.
+	maxStatsBuckets = 1000
+)
+
+// TaskStats computes statistics of the tasks started within the requested window:
+// totals, success rate, duration average and 95th percentile, breakdowns by status,
+// registry and user, and a series bucketed by hour or day (aligned to UTC).
+// Returns an error wrapping ErrInvalidQuery for an invalid window or interval.
+func (s *syncService) TaskStats(req *models.TaskStatsRequest) (*models.TaskStats, error) {
+	until := req.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	since := req.Since
+	if since.IsZero() {
+		since = until.Add(-defaultStatsWindow)
+	}
+	if !since.Before(until) {
+		return nil, fmt.Errorf("%w: since must be before until", ErrInvalidQuery)
+	}
+
+	interval := req.Interval
+	if interval == "" {
+		interval = models.StatsIntervalDay
+		if until.Sub(since) <= hourlyStatsWindow {
+			interval = models.StatsIntervalHour
+		}
+	}
+	var step time.Duration
+	switch interval {
+	case models.StatsIntervalHour:
+		step = time.Hour
+	case models.StatsIntervalDay:
+		step = timeutil.Day
+	default:
+		return nil, fmt.Errorf("%w: interval must be hour or day", ErrInvalidQuery)
+	}
+	// UTC has no DST, so truncating to a whole number of hours or days aligns buckets to UTC
+	first := since.UTC().Truncate(step)
+	buckets := int(until.Sub(first)/step) + 1
+	if until.Sub(first)%step == 0 {
+		buckets--
+	}
+	if buckets > maxStatsBuckets {
+		return nil, fmt.Errorf("%w: window has more than %d %s buckets", ErrInvalidQuery, maxStatsBuckets, interval)
+	}
+
+	tasks, _, err := s.repo.Query(&repository.TaskQuery{
+		Owner:         req.Owner,
+		StartedAfter:  since,
+		StartedBefore: until,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	var overall statsAccumulator
+	byStatus := make(map[models.SyncStatus]int)
+	bySource := make(map[string]*statsAccumulator)
+	byDest := make(map[string]*statsAccumulator)
+	byUser := make(map[string]*statsAccumulator)
+	series := make([]statsAccumulator, buckets)
+
+	for _, task := range tasks {
+		overall.add(task)
+		byStatus[task.Status]++
+		groupAccumulator(bySource, models.RegistryHost(task.SourceImage)).add(task)
+		groupAccumulator(byDest, models.RegistryHost(task.DestImage)).add(task)
+		user := task.OwnerEmail
+		if user == "" {
+			user = task.Owner
+		}
+		groupAccumulator(byUser, user).add(task)
+		series[int(task.StartTime.Sub(first)/step)].add(task)
+	}
+
+	stats := &models.TaskStats{
+		Since:            since,
+		Until:            until,
+		Interval:         interval,
+		Total:            overall.total,
+		Succeeded:        overall.succeeded,
+		Failed:           overall.failed,
+		SuccessRate:      overall.successRate(),
+		AvgDuration:      overall.avgDuration(),
+		P95Duration:      overall.percentileDuration(95),
+		ByStatus:         byStatus,
+		BySourceRegistry: statsGroups(bySource),
+		ByDestRegistry:   statsGroups(byDest),
+		ByUser:           statsGroups(byUser),
+		Series:           make([]*models.StatsBucket, buckets),
+	}
+	for i := range series {
+		stats.Series[i] = &models.StatsBucket{
+			Start:       first.Add(time.Duration(i) * step),
+			Total:       series[i].total,
+			Succeeded:   series[i].succeeded,
+			Failed:      series[i].failed,
+			AvgDuration: series[i].avgDuration(),
+		}
+	}
+	return stats, nil
+}
+
+// statsAccumulator collects the counts and durations of a set of tasks.
+type statsAccumulator struct {
+	total     int
+	succeeded int
+	failed    int
+	durations []float64 // Durations of completed tasks in seconds
+}
+
+// add counts a task.
+func (a *statsAccumulator) add(task *models.SyncTask) {
+	a.total++
+	switch task.Status {
+	case models.StatusCompleted, models.StatusSkipped:
+		a.succeeded++
+	case models.StatusFailed, models.StatusInterrupted, models.StatusPartial:
+		a.failed++
+	}
+	if task.Status == models.StatusCompleted && task.EndTime != nil {
+		a.durations = append(a.durations, task.EndTime.Sub(task.StartTime).Seconds())
+	}
+}
+
+// successRate returns the share of succeeded among succeeded and failed tasks.
+func (a *statsAccumulator) successRate() float64 {
+	if a.succeeded+a.failed == 0 {
+		return 0
+	}
+	return float64(a.succeeded) / float64(a.succeeded+a.failed)
+}
+
+// avgDuration returns the average duration of completed tasks, 0 if there are none.
+func (a *statsAccumulator) avgDuration() float64 {
+	if len(a.durations) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, d := range a.durations {
+		sum += d
+	}
+	return sum / float64(len(a.durations))
+}
+
+// percentileDuration returns the p-th percentile duration of completed tasks using the
+// nearest-rank method, 0 if there are none.
+func (a *statsAccumulator) percentileDuration(p float64) float64 {
+	if len(a.durations) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), a.durations...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// groupAccumulator returns the accumulator of a breakdown key, creating it if needed.
+func groupAccumulator(groups map[string]*statsAccumulator, key string) *statsAccumulator {
+	a, ok := groups[key]
+	if !ok {
+		a = &statsAccumulator{}
+		groups[key] = a
+	}
+	return a
+}
+
+// statsGroups converts breakdown accumulators to groups, busiest first.
+func statsGroups(groups map[string]*statsAccumulator) []*models.StatsGroup {
+	result := make([]*models.StatsGroup, 0, len(groups))
+	for key, a := range groups {
+		result = append(result, &models.StatsGroup{
+			Key:         key,
+			Total:       a.total,
+			Succeeded:   a.succeeded,
+			Failed:      a.failed,
+			SuccessRate: a.successRate(),
+			AvgDuration: a.avgDuration(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+func TestTaskStats(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tasks := []struct {
+		status   models.SyncStatus
+		offset   time.Duration
+		duration time.Duration
+		dest     string
+		owner    string
+	}{
+		{models.StatusCompleted, 10 * time.Minute, 10 * time.Second, "registry.example.com/nginx:latest", "alice@example.com"},
+		{models.StatusCompleted, 20 * time.Minute, 30 * time.Second, "registry.example.com/redis:7", "alice@example.com"},
+		{models.StatusSkipped, 90 * time.Minute, time.Second, "registry.example.com/nginx:latest", "bob@example.com"},
+		{models.StatusFailed, 100 * time.Minute, 5 * time.Second, "harbor.local/nginx:latest", "bob@example.com"},
+		{models.StatusCancelled, 150 * time.Minute, 0, "harbor.local/nginx:latest", ""},
+		{models.StatusRunning, 170 * time.Minute, 0, "harbor.local/nginx:latest", ""},
+		// Outside the window
+		{models.StatusCompleted, 5 * time.Hour, time.Hour, "registry.example.com/nginx:latest", "alice@example.com"},
+	}
+	for i, tt := range tasks {
+		task := models.NewSyncTask(fmt.Sprintf("id%d", i), "docker.io/library/nginx:latest", tt.dest, "all")
+		task.Status = tt.status
+		task.OwnerEmail = tt.owner
+		task.StartTime = since.Add(tt.offset)
+		if tt.status.IsTerminal() {
+			endTime := task.StartTime.Add(tt.duration)
+			task.EndTime = &endTime
+		}
+		repo.Create(task)
+	}
+
+	stats, err := service.TaskStats(&models.TaskStatsRequest{Since: since, Until: since.Add(3 * time.Hour)})
+	if err != nil {
+		t.Fatalf("TaskStats failed: %v", err)
+	}
+
+	if stats.Interval != models.StatsIntervalHour {
+		t.Errorf("Expected hourly interval for a short window, got %s", stats.Interval)
+	}
+	if stats.Total != 6 || stats.Succeeded != 3 || stats.Failed != 1 {
+		t.Errorf("Expected total=6 succeeded=3 failed=1, got %d/%d/%d", stats.Total, stats.Succeeded, stats.Failed)
+	}
+	if stats.SuccessRate != 0.75 {
+		t.Errorf("Expected success rate 0.75, got %v", stats.SuccessRate)
+	}
+	if stats.AvgDuration != 20 || stats.P95Duration != 30 {
+		t.Errorf("Expected avg 20s and p95 30s, got %v and %v", stats.AvgDuration, stats.P95Duration)
+	}
+	if stats.ByStatus[models.StatusCompleted] != 2 || stats.ByStatus[models.StatusRunning] != 1 {
+		t.Errorf("Expected per-status counts, got %v", stats.ByStatus)
+	}
+
+	if len(stats.BySourceRegistry) != 1 || stats.BySourceRegistry[0].Key != "docker.io" {
+		t.Errorf("Expected a single docker.io source registry, got %+v", stats.BySourceRegistry)
+	}
+	// Both destination registries have 3 tasks; ties are ordered by key
+	if len(stats.ByDestRegistry) != 2 || stats.ByDestRegistry[0].Key != "harbor.local" ||
+		stats.ByDestRegistry[1].SuccessRate != 1 {
+		t.Errorf("Expected harbor.local then registry.example.com, got %+v", stats.ByDestRegistry)
+	}
+	if len(stats.ByUser) != 3 || stats.ByUser[0].Key != "" || stats.ByUser[1].Key != "alice@example.com" {
+		t.Errorf("Expected users ordered by task count then key, got %+v", stats.ByUser)
+	}
+
+	if len(stats.Series) != 3 {
+		t.Fatalf("Expected 3 hourly buckets, got %d", len(stats.Series))
+	}
+	for i, want := range []int{2, 2, 2} {
+		if stats.Series[i].Total != want || !stats.Series[i].Start.Equal(since.Add(time.Duration(i)*time.Hour)) {
+			t.Errorf("Bucket %d: expected %d tasks at %v, got %d at %v",
+				i, want, since.Add(time.Duration(i)*time.Hour), stats.Series[i].Total, stats.Series[i].Start)
+		}
+	}
+
+	// Owner filter
+	stats, _ = service.TaskStats(&models.TaskStatsRequest{Since: since, Until: since.Add(24 * time.Hour), Owner: "alice@example.com"})
+	if stats.Total != 3 || stats.P95Duration != 3600 {
+		t.Errorf("Expected 3 tasks of alice with p95 3600s, got %d and %v", stats.Total, stats.P95Duration)
+	}
+}
+
+func TestTaskStatsInvalidQuery(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	service := NewSyncService(repo, repository.NewInMemoryJobRepository(), logger.New(), 600, 3, time.Hour)
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		req  *models.TaskStatsRequest
+	}{
+		{"since after until", &models.TaskStatsRequest{Since: now, Until: now.Add(-time.Hour)}},
+		{"unknown interval", &models.TaskStatsRequest{Interval: "week"}},
+		{"too many buckets", &models.TaskStatsRequest{Since: now.AddDate(-1, 0, 0), Interval: models.StatsIntervalHour}},
+	}
+
+	for _, tt := range tests {
+		if _, err := service.TaskStats(tt.req); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: expected ErrInvalidQuery, got %v", tt.name, err)
+		}
+	}
+
+	stats, err := service.TaskStats(&models.TaskStatsRequest{})
+	if err != nil {
+		t.Fatalf("TaskStats failed: %v", err)
+	}
+	if stats.Interval != models.StatsIntervalDay || len(stats.Series) != 31 {
+		t.Errorf("Expected 31 daily buckets by default, got %d %s buckets", len(stats.Series), stats.Interval)
+	}
+}
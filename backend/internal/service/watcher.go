@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+const (
+	defaultWatchInterval = "1h"
+	minWatchInterval     = 5 * time.Minute // Keeps watches from using up registry pull quotas
+	watchCheckTimeout    = 2 * time.Minute
+	watchHistoryLimit    = 20 // Digest changes kept per watch
+	watcherMaxSleep      = time.Minute
+)
+
+// watchChecks sets how often watches are checked.
+var watchChecks = checkPolicy{defaultInterval: defaultWatchInterval, minInterval: minWatchInterval, maxSleep: watcherMaxSleep}
+
+// ErrInvalidWatch is returned when a watch has a malformed interval.
+var ErrInvalidWatch = errors.New("invalid watch")
+
+// Watcher stores watches, checks the digest of their source tags when due and
+// creates a sync task whenever a digest changes. Watches are managed with the methods
+// of definitionStore: Create and Update return an error wrapping ErrInvalidWatch if a
+// watch is malformed, and Get, Update, SetEnabled and Delete return
+// repository.ErrWatchNotFound for unknown IDs. A new watch is checked right away, and
+// its first check syncs the tag, which copies nothing if the destination is already up
+// to date. An updated watch keeps its history, and is checked and synced right away if
+// its source, destination or architecture changed.
+type Watcher struct {
+	*definitionStore[*models.Watch]
+	syncService   SyncService
+	configService *ConfigService // Resolves the credentials of the saved config a watch references
+	// resolveDigest returns the current digest of the source of req for its architecture
+	resolveDigest func(ctx context.Context, req *models.SyncRequest) (string, error)
+}
+
+// NewWatcher creates a watcher for the watches in repo.
+func NewWatcher(syncService SyncService, configService *ConfigService, repo repository.WatchRepository, logger logger.Logger) *Watcher {
+	w := &Watcher{
+		definitionStore: newDefinitionStore(repo, definitionKind[*models.Watch]{
+			name:    "watch",
+			plural:  "watches",
+			prepare: prepareWatch,
+			describe: func(w *models.Watch) string {
+				return fmt.Sprintf("%s -> %s every %s", w.SourceImage, w.DestImage, w.Interval)
+			},
+			checks: watchChecks,
+		}, logger),
+		syncService:   syncService,
+		configService: configService,
+	}
+	w.resolveDigest = w.sourceDigest
+	return w
+}
+
+// Run checks due watches until ctx is done. Watches are checked one at a time.
+func (w *Watcher) Run(ctx context.Context) {
+	w.run(ctx, w.checkDue)
+}
+
+// prepareWatch validates a watch, defaults its interval and sets its next check time.
+// A new watch, or one whose source, destination or architecture changed, is checked
+// right away, with its last digest and failures reset.
+func prepareWatch(watch, previous *models.Watch, now time.Time) error {
+	retarget := previous == nil || watch.SourceImage != previous.SourceImage ||
+		watch.DestImage != previous.DestImage || watch.Architecture != previous.Architecture
+	if retarget {
+		watch.LastDigest = ""
+		watch.Failures = 0
+		watch.RateLimited = false
+	}
+	if err := watchChecks.prepare(&watch.Checks, watch.Enabled, now, retarget); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWatch, err)
+	}
+	return nil
+}
+
+// checkDue checks all watches that are due and returns when Run should check again.
+// When a registry rate limits a check, the other watches of that registry that are due
+// in the same pass are postponed along with it rather than checked.
+func (w *Watcher) checkDue(ctx context.Context) time.Time {
+	limited := make(map[string]time.Time) // Registry host -> end of its rate limit backoff
+	return checkDue(ctx, w.definitionStore, func(ctx context.Context, watch *models.Watch) func(*models.Watch) {
+		registry := models.RegistryHost(watch.SourceImage)
+		if until, ok := limited[registry]; ok {
+			return func(stored *models.Watch) {
+				stored.NextCheckAt = &until
+			}
+		}
+		digest, err := w.check(ctx, watch)
+		return func(stored *models.Watch) {
+			w.recordCheck(stored, digest, err)
+			if stored.RateLimited && stored.NextCheckAt != nil {
+				limited[registry] = *stored.NextCheckAt
+			}
+		}
+	})
+}
+
+// check returns the current digest of the source tag of a watch.
+func (w *Watcher) check(ctx context.Context, watch *models.Watch) (string, error) {
+	req, err := ownerRequest(w.configService, &watch.SyncDefinition, watch.Owner, watch.OwnerEmail)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, watchCheckTimeout)
+	defer cancel()
+	return w.resolveDigest(ctx, req)
+}
+
+// recordCheck records the outcome of a check and starts a sync if the digest changed.
+// A digest is only recorded once its sync has started, so that a change whose sync
+// could not be started is synced at the next check.
+// Must be called with w.mu held.
+func (w *Watcher) recordCheck(watch *models.Watch, digest string, checkErr error) {
+	now := w.now()
+	watchChecks.record(&watch.Checks, now, checkErr)
+	if checkErr != nil {
+		w.logger.Error("[watch %s] Check of %s failed (%d in a row), next check at %s: %v",
+			watch.ID, watch.SourceImage, watch.Failures, watch.NextCheckAt.Format(time.RFC3339), checkErr)
+		return
+	}
+
+	if digest == watch.LastDigest {
+		w.logger.Debug("[watch %s] %s is unchanged at %s", watch.ID, watch.SourceImage, digest)
+		return
+	}
+
+	taskID, err := w.startSync(watch)
+	var duplicate *DuplicateTaskError
+	switch {
+	case errors.As(err, &duplicate):
+		watch.LastError = fmt.Sprintf("Digest changed to %s while identical sync %s was still in progress", digest, duplicate.TaskID)
+		w.logger.Info("[watch %s] %s changed to %s, identical sync %s is in progress", watch.ID, watch.SourceImage, digest, duplicate.TaskID)
+	case err != nil:
+		watch.LastError = fmt.Sprintf("Digest changed to %s but the sync could not be started: %v", digest, err)
+		w.logger.Error("[watch %s] %s changed to %s, failed to start sync: %v", watch.ID, watch.SourceImage, digest, err)
+	default:
+		watch.LastDigest = digest
+		watch.LastTaskID = taskID
+		watch.History = append([]models.DigestChange{{Digest: digest, DetectedAt: now, TaskID: taskID}}, watch.History...)
+		if len(watch.History) > watchHistoryLimit {
+			watch.History = watch.History[:watchHistoryLimit]
+		}
+		w.logger.Info("[watch %s] %s changed to %s, started task %s", watch.ID, watch.SourceImage, digest, taskID)
+	}
+}
+
+// startSync creates and enqueues the sync task for a digest change, on behalf of the watch's owner.
+func (w *Watcher) startSync(watch *models.Watch) (string, error) {
+	req, err := ownerRequest(w.configService, &watch.SyncDefinition, watch.Owner, watch.OwnerEmail)
+	if err != nil {
+		return "", err
+	}
+	req.WatchID = watch.ID
+	return startSync(w.syncService, req)
+}
+
+// sourceDigest resolves the digest of the source of req with skopeo inspect, as it
+// would be copied for the request's architecture.
+func (w *Watcher) sourceDigest(ctx context.Context, req *models.SyncRequest) (string, error) {
+	authFile, err := createAuthFile(req.SourceImage, req.SourceUsername, req.SourcePassword, "", "", "")
+	if err != nil {
+		return "", fmt.Errorf("failed to create auth file: %w", err)
+	}
+	if authFile != "" {
+		defer func() {
+			if err := os.Remove(authFile); err != nil {
+				w.logger.Error("Failed to remove auth file: %v", err)
+			}
+		}()
+	}
+	return resolveManifestDigest(ctx, req.SourceImage, req.Architecture, tlsVerifyOrDefault(req.SrcTLSVerify), authFile)
+}
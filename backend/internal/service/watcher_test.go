@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/image-sync/internal/models"
+	"github.com/lazycatapps/image-sync/internal/pkg/logger"
+	"github.com/lazycatapps/image-sync/internal/repository"
+)
+
+// testRegistry serves digests to a watcher under test, by source image.
+type testRegistry struct {
+	digests map[string]string
+	errs    map[string]error
+	checks  int
+}
+
+func (r *testRegistry) resolve(_ context.Context, req *models.SyncRequest) (string, error) {
+	r.checks++
+	if err := r.errs[req.SourceImage]; err != nil {
+		return "", err
+	}
+	return r.digests[req.SourceImage], nil
+}
+
+// newTestWatcher returns a watcher that checks digests in the returned registry and
+// whose clock is set by the returned function.
+func newTestWatcher(t *testing.T) (*Watcher, *syncService, *testRegistry, func(time.Time)) {
+	t.Helper()
+	watcher, svc, setNow := newTestService(t, func(svc *syncService, dir string) (*Watcher, *definitionStore[*models.Watch], error) {
+		repo, err := repository.NewFileWatchRepository(filepath.Join(dir, "watches.json"))
+		if err != nil {
+			return nil, nil, err
+		}
+		watcher := NewWatcher(svc, nil, repo, logger.New())
+		return watcher, watcher.definitionStore, nil
+	})
+	registry := &testRegistry{digests: make(map[string]string), errs: make(map[string]error)}
+	watcher.resolveDigest = registry.resolve
+	return watcher, svc, registry, setNow
+}
+
+func newTestWatch(source, interval string) *models.Watch {
+	watch := &models.Watch{Enabled: true, Owner: "u1", Checks: models.Checks{Interval: interval}}
+	watch.SourceImage = source
+	watch.DestImage = "registry.example.com/mirror/nginx:stable"
+	return watch
+}
+
+func TestWatcher_Create(t *testing.T) {
+	watcher, _, _, _ := newTestWatcher(t)
+
+	for _, interval := range []string{"1m", "soon", "-1h"} {
+		if err := watcher.Create(newTestWatch("docker.io/library/nginx:stable", interval)); !errors.Is(err, ErrInvalidWatch) {
+			t.Errorf("Expected ErrInvalidWatch for interval %q, got %v", interval, err)
+		}
+	}
+
+	watch := newTestWatch("docker.io/library/nginx:stable", "")
+	if err := watcher.Create(watch); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if watch.Interval != "1h" {
+		t.Errorf("Expected default interval 1h, got %q", watch.Interval)
+	}
+	if watch.NextCheckAt == nil || !watch.NextCheckAt.Equal(watch.CreatedAt) {
+		t.Errorf("Expected first check right away, got %v", watch.NextCheckAt)
+	}
+
+	disabled, err := watcher.Update(watch.ID, func(w *models.Watch) error {
+		w.Enabled = false
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if disabled.NextCheckAt != nil {
+		t.Errorf("Expected no next check for disabled watch, got %v", disabled.NextCheckAt)
+	}
+}
+
+func TestWatcher_DigestChange(t *testing.T) {
+	watcher, svc, registry, setNow := newTestWatcher(t)
+	source := "docker.io/library/nginx:stable"
+	registry.digests[source] = "sha256:aaa"
+
+	watch := newTestWatch(source, "10m")
+	if err := watcher.Create(watch); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// The first check syncs the current digest
+	watcher.checkDue(context.Background())
+	first, _ := watcher.Get(watch.ID)
+	if first.LastDigest != "sha256:aaa" || first.LastTaskID == "" || len(first.History) != 1 {
+		t.Fatalf("Expected sync of sha256:aaa, got digest %q task %q (error %q)", first.LastDigest, first.LastTaskID, first.LastError)
+	}
+	task, _ := svc.GetTask(first.LastTaskID)
+	if task.WatchID != watch.ID || task.Owner != "u1" {
+		t.Errorf("Expected task of the watch owned by u1, got watch=%q owner=%q", task.WatchID, task.Owner)
+	}
+	if want := time.Date(2025, 3, 14, 10, 10, 0, 0, time.UTC); !first.NextCheckAt.Equal(want) {
+		t.Errorf("Expected next check at %v, got %v", want, first.NextCheckAt)
+	}
+
+	// The digest changes while the previous sync is still pending: the change is kept
+	// for the next check rather than lost
+	registry.digests[source] = "sha256:bbb"
+	setNow(time.Date(2025, 3, 14, 10, 10, 0, 0, time.UTC))
+	watcher.checkDue(context.Background())
+	pending, _ := watcher.Get(watch.ID)
+	if pending.LastDigest != "sha256:aaa" || pending.LastError == "" {
+		t.Errorf("Expected digest sha256:aaa with error, got %q (error %q)", pending.LastDigest, pending.LastError)
+	}
+
+	if err := svc.CancelTask(first.LastTaskID, "u1"); err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+	setNow(time.Date(2025, 3, 14, 10, 20, 0, 0, time.UTC))
+	watcher.checkDue(context.Background())
+	changed, _ := watcher.Get(watch.ID)
+	if changed.LastDigest != "sha256:bbb" || changed.LastTaskID == first.LastTaskID || changed.LastError != "" {
+		t.Fatalf("Expected sync of sha256:bbb, got digest %q task %q (error %q)", changed.LastDigest, changed.LastTaskID, changed.LastError)
+	}
+	if len(changed.History) != 2 || changed.History[0].Digest != "sha256:bbb" || changed.History[0].TaskID != changed.LastTaskID {
+		t.Errorf("Expected sha256:bbb first in history, got %+v", changed.History)
+	}
+
+	// An unchanged digest creates no task
+	setNow(time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC))
+	watcher.checkDue(context.Background())
+	unchanged, _ := watcher.Get(watch.ID)
+	if unchanged.LastTaskID != changed.LastTaskID || !unchanged.LastCheckAt.Equal(time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected check without sync, got task %q checked at %v", unchanged.LastTaskID, unchanged.LastCheckAt)
+	}
+}
+
+func TestWatcher_Backoff(t *testing.T) {
+	watcher, _, registry, setNow := newTestWatcher(t)
+	nginx := "docker.io/library/nginx:stable"
+	redis := "docker.io/library/redis:7"
+	registry.errs[nginx] = ErrRateLimited
+	registry.digests[redis] = "sha256:ccc"
+
+	// Watches are checked oldest first
+	limited := newTestWatch(nginx, "5m")
+	postponed := newTestWatch(redis, "5m")
+	setNow(time.Date(2025, 3, 14, 9, 59, 0, 0, time.UTC))
+	if err := watcher.Create(limited); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	setNow(time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC))
+	if err := watcher.Create(postponed); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// The rate limit postpones the other watch of the registry without checking it
+	wakeAt := watcher.checkDue(context.Background())
+	if registry.checks != 1 {
+		t.Errorf("Expected 1 check, got %d", registry.checks)
+	}
+	want := time.Date(2025, 3, 14, 10, 15, 0, 0, time.UTC)
+	for _, id := range []string{limited.ID, postponed.ID} {
+		if watch, _ := watcher.Get(id); !watch.NextCheckAt.Equal(want) {
+			t.Errorf("Expected next check at %v, got %v", want, watch.NextCheckAt)
+		}
+	}
+	if stored, _ := watcher.Get(limited.ID); stored.Failures != 1 || !stored.RateLimited || stored.LastError == "" {
+		t.Errorf("Expected 1 rate limited failure, got %d (rate limited %v)", stored.Failures, stored.RateLimited)
+	}
+	if !wakeAt.Equal(time.Date(2025, 3, 14, 10, 1, 0, 0, time.UTC)) {
+		t.Errorf("Expected to check again after a minute, got %v", wakeAt)
+	}
+
+	// Further failures double the delay
+	setNow(want)
+	watcher.checkDue(context.Background())
+	if stored, _ := watcher.Get(limited.ID); stored.Failures != 2 ||
+		!stored.NextCheckAt.Equal(time.Date(2025, 3, 14, 10, 45, 0, 0, time.UTC)) {
+		t.Errorf("Expected second failure with check at 10:45, got %d at %v", stored.Failures, stored.NextCheckAt)
+	}
+
+	// A successful check resets the backoff
+	delete(registry.errs, nginx)
+	setNow(time.Date(2025, 3, 14, 10, 45, 0, 0, time.UTC))
+	watcher.checkDue(context.Background())
+	if stored, _ := watcher.Get(limited.ID); stored.Failures != 0 || stored.RateLimited ||
+		!stored.NextCheckAt.Equal(time.Date(2025, 3, 14, 10, 50, 0, 0, time.UTC)) {
+		t.Errorf("Expected reset backoff with check at 10:50, got %d failures at %v", stored.Failures, stored.NextCheckAt)
+	}
+}
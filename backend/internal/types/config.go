@@ -4,6 +4,8 @@
 // Package types defines configuration types for the Image Sync application.
 package types
 
+import "time"
+
 // Config represents the complete application configuration.
 type Config struct {
 	Server   ServerConfig   // HTTP server configuration
@@ -16,8 +18,9 @@ type Config struct {
 
 // ServerConfig defines HTTP server listening configuration.
 type ServerConfig struct {
-	Host string // Server listening address (e.g., "0.0.0.0", "127.0.0.1")
-	Port int    // Server listening port (e.g., 8080)
+	Host                string // Server listening address (e.g., "0.0.0.0", "127.0.0.1")
+	Port                int    // Server listening port (e.g., 8080)
+	ShutdownGracePeriod int    // Seconds running syncs may take to finish on shutdown before being interrupted (default: 30)
 }
 
 // RegistryConfig defines default container registry addresses.
@@ -29,7 +32,10 @@ type RegistryConfig struct {
 
 // SyncConfig defines sync operation behavior.
 type SyncConfig struct {
-	Timeout int // Sync operation timeout in seconds (default: 600)
+	Timeout           int           // Sync operation timeout in seconds (default: 600)
+	MaxConcurrent     int           // Maximum number of syncs running at the same time (default: 3)
+	ResumeInterrupted bool          // Re-queue tasks interrupted by a restart if they need no credentials (default: false)
+	IdempotencyTTL    time.Duration // How long an Idempotency-Key maps to the task it created (0 = disabled, default: 24h)
 }
 
 // CORSConfig defines Cross-Origin Resource Sharing policy.
@@ -39,7 +45,11 @@ type CORSConfig struct {
 
 // StorageConfig defines storage configuration.
 type StorageConfig struct {
-	ConfigDir string // Directory for storing configuration files (default: "/configs")
+	ConfigDir          string        // Directory for storing configuration files (default: "/configs")
+	TaskStore          string        // Task storage backend: "memory" or "sqlite" (default: "memory")
+	TaskDB             string        // SQLite database file for tasks (default: "<ConfigDir>/tasks.db")
+	TaskRetentionAge   time.Duration // Finished tasks older than this are pruned (0 = keep regardless of age)
+	TaskRetentionCount int           // Maximum number of finished tasks kept (0 = unlimited, default: 0)
 }
 
 // OIDCConfig defines OIDC authentication configuration.
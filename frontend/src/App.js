@@ -2,7 +2,7 @@
 // Licensed under the MIT License. See LICENSE file in the project root for details.
 
 import React, { useState, useEffect, useRef, useCallback } from 'react';
-import { Form, Input, InputNumber, Button, Card, Space, Typography, Select, Tag, Checkbox, Modal, Alert, Collapse, FloatButton, App as AntApp } from 'antd';
+import { Form, Input, InputNumber, Button, Card, Space, Typography, Select, Tag, Checkbox, Modal, Alert, Collapse, FloatButton, Progress, App as AntApp } from 'antd';
 import { InfoCircleOutlined, BugOutlined, CopyOutlined, FullscreenOutlined, FullscreenExitOutlined } from '@ant-design/icons';
 import 'antd/dist/reset.css';
 import './App.css';
@@ -28,6 +28,9 @@ const debugLog = (category, ...args) => {
   ).join(' ') };
 };
 
+// Task statuses after which a sync task will not change anymore
+const TERMINAL_STATUSES = ['completed', 'failed', 'cancelled', 'interrupted', 'skipped', 'partial'];
+
 function AppContent() {
   const { message } = AntApp.useApp();
   const [loading, setLoading] = useState(false);
@@ -35,6 +38,9 @@ function AppContent() {
   const [architectures, setArchitectures] = useState([]);
   const [syncLogs, setSyncLogs] = useState([]);
   const [syncStatus, setSyncStatus] = useState(null);
+  const [syncProgress, setSyncProgress] = useState(null);
+  const [currentTaskId, setCurrentTaskId] = useState(null);
+  const [cancelling, setCancelling] = useState(false);
   const [logsModalVisible, setLogsModalVisible] = useState(false);
   const [inspectModalVisible, setInspectModalVisible] = useState(false);
   const [inspectLogs, setInspectLogs] = useState([]);
@@ -54,7 +60,6 @@ function AppContent() {
   const [logsModalMaximized, setLogsModalMaximized] = useState(false);
   const [form] = Form.useForm();
   const eventSourceRef = useRef(null);
-  const statusIntervalRef = useRef(null);
   const logsEndRef = useRef(null);
   const inspectLogsEndRef = useRef(null);
   const debugLogsEndRef = useRef(null);
@@ -289,9 +294,6 @@ function AppContent() {
       if (eventSourceRef.current) {
         eventSourceRef.current.close();
       }
-      if (statusIntervalRef.current) {
-        clearInterval(statusIntervalRef.current);
-      }
     };
   }, []);
 
@@ -390,12 +392,9 @@ function AppContent() {
       eventSourceRef.current.close();
       addDebugLog('LOG_STREAM', 'Closed previous EventSource');
     }
-    if (statusIntervalRef.current) {
-      clearInterval(statusIntervalRef.current);
-      addDebugLog('LOG_STREAM', 'Cleared previous status interval');
-    }
 
     setSyncLogs([]);
+    setSyncProgress(null);
     const url = `${BACKEND_API_URL}/api/v1/sync/${taskId}/logs`;
     addDebugLog('LOG_STREAM', 'Creating EventSource:', url);
 
@@ -406,39 +405,45 @@ function AppContent() {
       addDebugLog('LOG_STREAM', 'EventSource connection opened');
     };
 
-    // Start polling status immediately to update status in real-time
-    statusIntervalRef.current = setInterval(async () => {
+    // Log lines carry their sequence number as event id, so on reconnect the browser
+    // sends Last-Event-ID and the server resumes after the last line received
+    eventSource.addEventListener('log', (event) => {
+      addDebugLog('LOG_STREAM', 'Received log message');
+      setSyncLogs(prev => [...prev, event.data]);
+    });
+
+    eventSource.addEventListener('status', (event) => {
       try {
-        const statusUrl = `${BACKEND_API_URL}/api/v1/sync/${taskId}`;
-        const response = await fetch(statusUrl, { credentials: 'include' });
-        if (response.ok) {
-          const data = await response.json();
-          setSyncStatus(data.status);
-          addDebugLog('LOG_STREAM', 'Status update:', data.status);
-          if (data.status === 'completed' || data.status === 'failed') {
-            addDebugLog('LOG_STREAM', 'Task finished, stopping polling');
-            clearInterval(statusIntervalRef.current);
-            statusIntervalRef.current = null;
-            if (eventSourceRef.current) {
-              eventSourceRef.current.close();
-            }
-          }
-        }
+        const data = JSON.parse(event.data);
+        setSyncStatus(data.status);
+        addDebugLog('LOG_STREAM', 'Status update:', data.status);
       } catch (error) {
-        console.error('Failed to fetch sync status:', error);
-        addDebugLog('ERROR', 'Status polling failed:', error.message);
+        addDebugLog('ERROR', 'Invalid status event:', event.data);
       }
-    }, 1000);
+    });
 
-    eventSource.onmessage = (event) => {
-      addDebugLog('LOG_STREAM', 'Received log message');
-      setSyncLogs(prev => [...prev, event.data]);
-    };
+    eventSource.addEventListener('progress', (event) => {
+      try {
+        setSyncProgress(JSON.parse(event.data));
+      } catch (error) {
+        addDebugLog('ERROR', 'Invalid progress event:', event.data);
+      }
+    });
+
+    eventSource.addEventListener('done', (event) => {
+      try {
+        setSyncStatus(JSON.parse(event.data).status);
+      } catch (error) {
+        addDebugLog('ERROR', 'Invalid done event:', event.data);
+      }
+      addDebugLog('LOG_STREAM', 'Task finished, closing stream');
+      eventSource.close();
+    });
 
     eventSource.onerror = (error) => {
+      // The browser reconnects automatically and resumes from the last log line
       console.log('EventSource error:', error);
-      addDebugLog('ERROR', 'EventSource error:', error);
-      eventSource.close();
+      addDebugLog('ERROR', 'EventSource error, reconnecting:', error);
     };
   };
 
@@ -481,6 +486,7 @@ function AppContent() {
         addDebugLog('SYNC', 'Sync task created:', data);
         message.success('镜像同步任务已启动！');
         setSyncStatus('running');
+        setCurrentTaskId(data.id);
         setLogsModalVisible(true);
         startLogStream(data.id);
       } else {
@@ -502,9 +508,29 @@ function AppContent() {
     if (eventSourceRef.current) {
       eventSourceRef.current.close();
     }
-    if (statusIntervalRef.current) {
-      clearInterval(statusIntervalRef.current);
-      statusIntervalRef.current = null;
+  };
+
+  const handleCancelSync = async () => {
+    if (!currentTaskId) return;
+    addDebugLog('SYNC', 'Cancelling sync task:', currentTaskId);
+    setCancelling(true);
+    try {
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/sync/${currentTaskId}/cancel`, {
+        method: 'POST',
+        credentials: 'include',
+      });
+      if (response.ok) {
+        message.success('已请求取消同步任务');
+      } else {
+        const error = await response.text();
+        addDebugLog('ERROR', 'Cancel failed:', { status: response.status, error });
+        message.error('取消同步任务失败');
+      }
+    } catch (error) {
+      addDebugLog('ERROR', 'Cancel exception:', error.message, error);
+      message.error('请求失败: ' + error.message);
+    } finally {
+      setCancelling(false);
     }
   };
 
@@ -1036,6 +1062,19 @@ function AppContent() {
               style={{ marginBottom: '16px' }}
             />
           )}
+          {syncProgress && syncProgress.blobsTotal > 0 && (
+            <div style={{ marginBottom: '16px' }}>
+              <Progress
+                percent={Math.round((syncProgress.blobsDone + syncProgress.blobsSkipped) * 100 / syncProgress.blobsTotal)}
+                status={syncStatus === 'failed' ? 'exception' : undefined}
+              />
+              <Typography.Text type="secondary">
+                {syncProgress.images > 0 && `镜像 ${syncProgress.image}/${syncProgress.images} · `}
+                Blob {syncProgress.blobsDone + syncProgress.blobsSkipped}/{syncProgress.blobsTotal}
+                （已存在 {syncProgress.blobsSkipped}）· {syncProgress.phase}
+              </Typography.Text>
+            </div>
+          )}
           <div style={{
             background: '#000',
             color: '#0f0',
@@ -1082,6 +1121,11 @@ function AppContent() {
           open={logsModalVisible}
           onCancel={handleCloseModal}
           footer={[
+            !TERMINAL_STATUSES.includes(syncStatus) && (
+              <Button key="cancel" danger loading={cancelling} onClick={handleCancelSync}>
+                取消任务
+              </Button>
+            ),
             <Button key="close" onClick={handleCloseModal}>
               关闭
             </Button>
@@ -1092,10 +1136,23 @@ function AppContent() {
           {syncStatus && (
             <Alert
               message={`任务状态: ${syncStatus}`}
-              type={syncStatus === 'completed' ? 'success' : syncStatus === 'failed' ? 'error' : 'info'}
+              type={(syncStatus === 'completed' || syncStatus === 'skipped') ? 'success' : syncStatus === 'failed' ? 'error' : syncStatus === 'partial' ? 'warning' : 'info'}
               style={{ marginBottom: '16px' }}
             />
           )}
+          {syncProgress && syncProgress.blobsTotal > 0 && (
+            <div style={{ marginBottom: '16px' }}>
+              <Progress
+                percent={Math.round((syncProgress.blobsDone + syncProgress.blobsSkipped) * 100 / syncProgress.blobsTotal)}
+                status={syncStatus === 'failed' ? 'exception' : undefined}
+              />
+              <Typography.Text type="secondary">
+                {syncProgress.images > 0 && `镜像 ${syncProgress.image}/${syncProgress.images} · `}
+                Blob {syncProgress.blobsDone + syncProgress.blobsSkipped}/{syncProgress.blobsTotal}
+                （已存在 {syncProgress.blobsSkipped}）· {syncProgress.phase}
+              </Typography.Text>
+            </div>
+          )}
           <div style={{
             background: '#000',
             color: '#0f0',